@@ -0,0 +1,208 @@
+// The analysis package provides an analyzer that reports errors
+// returned without annotation in packages that use errgo, so
+// that the convention of recording a location for each error
+// passed up the stack can be checked automatically.
+package analysis
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strconv"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+// errgoPath holds the import path of the errgo package.
+const errgoPath = "github.com/juju/errgo"
+
+// Analyzer reports return statements that return an error variable
+// unchanged, such as
+//
+//	return nil, err
+//
+// in files that import errgo, suggesting that the error be passed
+// through errgo.Mask (or annotated with errgo.Notef) so that its
+// location is recorded. Only variables of type error are reported;
+// other expressions, such as calls, are assumed to
+// produce annotated errors.
+//
+// A variable whose last assignment before the return statement
+// is from a call to a function in the errgo package, as in
+//
+//	err = errgo.Notef(err, "cannot open %q", name)
+//	return nil, err
+//
+// is taken to hold an annotated error and is not reported. The
+// order of the assignments is that of the source code, so an
+// annotation made on only some of the paths to the return
+// statement is not distinguished from one made on all of them.
+var Analyzer = &analysis.Analyzer{
+	Name:     "errgoreturn",
+	Doc:      "report errors returned without errgo annotation",
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+var errorType = types.Universe.Lookup("error").Type()
+
+// assignment records an assignment to an error variable.
+type assignment struct {
+	// pos holds the position of the assignment.
+	pos token.Pos
+
+	// annotated records whether the value assigned is
+	// the result of a call to an errgo function.
+	annotated bool
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	names := make(map[*ast.File]string)
+	for _, f := range pass.Files {
+		if name := errgoName(f); name != "" {
+			names[f] = name
+		}
+	}
+	if len(names) == 0 {
+		return nil, nil
+	}
+	ins := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	assigns := assignments(pass, ins)
+	ins.WithStack([]ast.Node{(*ast.ReturnStmt)(nil)}, func(n ast.Node, push bool, stack []ast.Node) bool {
+		if !push {
+			return true
+		}
+		name, ok := names[stack[0].(*ast.File)]
+		if !ok {
+			return true
+		}
+		for _, result := range n.(*ast.ReturnStmt).Results {
+			id, ok := result.(*ast.Ident)
+			if !ok {
+				continue
+			}
+			v, ok := pass.TypesInfo.Uses[id].(*types.Var)
+			if !ok || !types.Identical(v.Type(), errorType) {
+				continue
+			}
+			if annotated(assigns[v], id.Pos()) {
+				continue
+			}
+			pass.Report(analysis.Diagnostic{
+				Pos:     id.Pos(),
+				End:     id.End(),
+				Message: "error " + id.Name + " returned without annotation; use " + name + ".Mask or " + name + ".Notef",
+				SuggestedFixes: []analysis.SuggestedFix{{
+					Message: "Wrap with " + name + ".Mask",
+					TextEdits: []analysis.TextEdit{{
+						Pos:     id.Pos(),
+						End:     id.End(),
+						NewText: []byte(name + ".Mask(" + id.Name + ")"),
+					}},
+				}},
+			})
+		}
+		return true
+	})
+	return nil, nil
+}
+
+// assignments returns the assignments to variables of
+// type error in the package, in source order.
+func assignments(pass *analysis.Pass, ins *inspector.Inspector) map[*types.Var][]assignment {
+	assigns := make(map[*types.Var][]assignment)
+	record := func(lhs ast.Expr, rhs ast.Expr) {
+		id, ok := lhs.(*ast.Ident)
+		if !ok {
+			return
+		}
+		v, ok := pass.TypesInfo.ObjectOf(id).(*types.Var)
+		if !ok || !types.Identical(v.Type(), errorType) {
+			return
+		}
+		assigns[v] = append(assigns[v], assignment{
+			pos:       id.Pos(),
+			annotated: isErrgoCall(pass, rhs),
+		})
+	}
+	ins.Preorder([]ast.Node{(*ast.AssignStmt)(nil), (*ast.ValueSpec)(nil)}, func(n ast.Node) {
+		switch n := n.(type) {
+		case *ast.AssignStmt:
+			for i, lhs := range n.Lhs {
+				record(lhs, assignedValue(n.Rhs, i, len(n.Lhs)))
+			}
+		case *ast.ValueSpec:
+			for i, name := range n.Names {
+				record(name, assignedValue(n.Values, i, len(n.Names)))
+			}
+		}
+	})
+	return assigns
+}
+
+// assignedValue returns the expression holding the value
+// assigned to the ith of n variables from values: the ith
+// value, or the only value when it is a call returning
+// several results, or nil if there is none.
+func assignedValue(values []ast.Expr, i, n int) ast.Expr {
+	switch len(values) {
+	case n:
+		return values[i]
+	case 1:
+		return values[0]
+	}
+	return nil
+}
+
+// isErrgoCall reports whether expr is a call to
+// a function in the errgo package.
+func isErrgoCall(pass *analysis.Pass, expr ast.Expr) bool {
+	call, ok := ast.Unparen(expr).(*ast.CallExpr)
+	if !ok {
+		return false
+	}
+	sel, ok := ast.Unparen(call.Fun).(*ast.SelectorExpr)
+	if !ok {
+		return false
+	}
+	pkg, ok := sel.X.(*ast.Ident)
+	if !ok {
+		return false
+	}
+	name, ok := pass.TypesInfo.Uses[pkg].(*types.PkgName)
+	return ok && name.Imported().Path() == errgoPath
+}
+
+// annotated reports whether the last of the given
+// assignments made before pos assigned an annotated error.
+func annotated(assigns []assignment, pos token.Pos) bool {
+	last := false
+	for _, a := range assigns {
+		if a.pos >= pos {
+			break
+		}
+		last = a.annotated
+	}
+	return last
+}
+
+// errgoName returns the name by which the errgo package
+// is imported into f, or the empty string if it is not.
+func errgoName(f *ast.File) string {
+	for _, imp := range f.Imports {
+		path, err := strconv.Unquote(imp.Path.Value)
+		if err != nil || path != errgoPath {
+			continue
+		}
+		if imp.Name == nil {
+			return "errgo"
+		}
+		if imp.Name.Name == "_" || imp.Name.Name == "." {
+			return ""
+		}
+		return imp.Name.Name
+	}
+	return ""
+}
@@ -0,0 +1,53 @@
+package a
+
+import (
+	"os"
+
+	"github.com/juju/errgo"
+)
+
+func open(name string) (*os.File, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err // want "error err returned without annotation; use errgo.Mask or errgo.Notef"
+	}
+	return f, nil
+}
+
+func masked(name string) error {
+	_, err := os.Open(name)
+	return errgo.Mask(err)
+}
+
+func created() error {
+	return errgo.New("foo")
+}
+
+func reassigned(name string) error {
+	_, err := os.Open(name)
+	if err != nil {
+		err = errgo.Notef(err, "cannot open %q", name)
+		return err
+	}
+	err = os.Remove(name)
+	err = errgo.Mask(err)
+	return err
+}
+
+func declared() error {
+	var err = errgo.New("foo")
+	return err
+}
+
+func captured(name string) func() error {
+	err := errgo.Mask(os.Remove(name))
+	return func() error {
+		return err
+	}
+}
+
+func overwritten(name string) error {
+	err := errgo.New("foo")
+	err = os.Remove(name)
+	return err // want "error err returned without annotation; use errgo.Mask or errgo.Notef"
+}
@@ -0,0 +1,8 @@
+package b
+
+import "os"
+
+func open(name string) error {
+	_, err := os.Open(name)
+	return err
+}
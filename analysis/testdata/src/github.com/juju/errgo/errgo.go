@@ -0,0 +1,7 @@
+package errgo
+
+func New(s string) error { return nil }
+
+func Mask(err error, pass ...func(error) bool) error { return err }
+
+func Notef(err error, f string, a ...interface{}) error { return err }
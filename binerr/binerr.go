@@ -0,0 +1,155 @@
+// The binerr package encodes error chains in the compact binary
+// CBOR (RFC 8949) and MessagePack formats, for reporting errors
+// over constrained links where JSON is too large, and decodes
+// them back into errgo errors.
+//
+// Both formats encode the same information: the message, location
+// and cause message of each error in the chain (see errgo.Wrapper),
+// and the chains of any errors that it wraps (see errgo.MultiWrapper).
+package binerr
+
+import (
+	"github.com/fxamacker/cbor/v2"
+	"github.com/vmihailenco/msgpack/v5"
+
+	"github.com/juju/errgo"
+)
+
+// Link holds the encoded form of a single error in a chain.
+// It is encoded as an array so that field names are not
+// included in the encoded data.
+type Link struct {
+	_ struct{} `cbor:",toarray" msgpack:",as_array"`
+
+	// Message holds the message of the error, not
+	// including the message of its underlying error.
+	Message string
+
+	// File and Line hold the location of the
+	// error, if known.
+	File string
+	Line int
+
+	// Cause holds the message of the cause of the error,
+	// if it has one other than itself.
+	Cause string
+
+	// Branches holds the chains of the errors wrapped by
+	// the error, if it wraps several.
+	Branches [][]Link
+}
+
+// Chain returns the encoded form of the chain of err,
+// outermost first. It returns nil if err is nil.
+func Chain(err error) []Link {
+	var links []Link
+	for err != nil {
+		var link Link
+		if l, ok := err.(errgo.Locationer); ok {
+			loc := l.Location()
+			link.File, link.Line = loc.File, loc.Line
+		}
+		if c, ok := err.(errgo.Causer); ok && c.Cause() != nil {
+			link.Cause = c.Cause().Error()
+		}
+		if m, ok := err.(errgo.MultiWrapper); ok {
+			for _, branch := range m.UnderlyingErrors() {
+				link.Branches = append(link.Branches, Chain(branch))
+			}
+		}
+		if w, ok := err.(errgo.Wrapper); ok {
+			link.Message = w.Message()
+			err = w.Underlying()
+		} else {
+			link.Message = err.Error()
+			err = nil
+		}
+		links = append(links, link)
+	}
+	return links
+}
+
+// Error returns an error reconstructed from the given encoded chain.
+// Each link becomes an *errgo.Err, or an *errgo.MultiErr if it has
+// branches, with the encoded message and location. The cause of
+// each link is the first later link in the chain whose message is
+// the encoded cause message, or else a new error with that message.
+// Error returns nil if links is empty.
+func Error(links []Link) error {
+	errs := make([]error, len(links))
+	for i := len(links) - 1; i >= 0; i-- {
+		link := links[i]
+		e := errgo.Err{
+			Message_:  link.Message,
+			Location_: errgo.Location{File: link.File, Line: link.Line},
+		}
+		if i+1 < len(links) {
+			e.Underlying_ = errs[i+1]
+		}
+		if link.Cause != "" {
+			e.Cause_ = findCause(errs[i+1:], link.Cause)
+		}
+		if len(link.Branches) == 0 {
+			errs[i] = &e
+			continue
+		}
+		merr := &errgo.MultiErr{Err: e}
+		for _, branch := range link.Branches {
+			merr.UnderlyingErrors_ = append(merr.UnderlyingErrors_, Error(branch))
+		}
+		errs[i] = merr
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs[0]
+}
+
+// findCause returns the first of errs whose message is
+// the given cause message, or a new error with that message.
+func findCause(errs []error, cause string) error {
+	for _, err := range errs {
+		if err.Error() == cause {
+			return err
+		}
+	}
+	return &errgo.Err{Message_: cause}
+}
+
+// MarshalCBOR returns the CBOR encoding of the chain of err.
+func MarshalCBOR(err error) ([]byte, error) {
+	data, merr := cbor.Marshal(Chain(err))
+	if merr != nil {
+		return nil, errgo.Notef(merr, "cannot encode error")
+	}
+	return data, nil
+}
+
+// UnmarshalCBOR returns the error encoded in
+// the given CBOR data by MarshalCBOR.
+func UnmarshalCBOR(data []byte) (error, error) {
+	var links []Link
+	if err := cbor.Unmarshal(data, &links); err != nil {
+		return nil, errgo.Notef(err, "cannot decode error")
+	}
+	return Error(links), nil
+}
+
+// MarshalMsgpack returns the MessagePack encoding of the chain of err.
+func MarshalMsgpack(err error) ([]byte, error) {
+	data, merr := msgpack.Marshal(Chain(err))
+	if merr != nil {
+		return nil, errgo.Notef(merr, "cannot encode error")
+	}
+	return data, nil
+}
+
+// UnmarshalMsgpack returns the error encoded in the
+// given MessagePack data by MarshalMsgpack.
+func UnmarshalMsgpack(data []byte) (error, error) {
+	var links []Link
+	if err := msgpack.Unmarshal(data, &links); err != nil {
+		return nil, errgo.Notef(err, "cannot decode error")
+	}
+	return Error(links), nil
+}
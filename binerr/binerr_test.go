@@ -0,0 +1,77 @@
+package binerr_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/juju/errgo"
+	"github.com/juju/errgo/binerr"
+)
+
+var errNotFound = fmt.Errorf("not found")
+
+func TestRoundTrip(t *testing.T) {
+	err0 := errgo.WithCausef(nil, errNotFound, "foo")
+	err1 := errgo.NoteMask(err0, "bar", errgo.Any)
+	err2 := errgo.Combine(errgo.New("one"), err1)
+	err3 := errgo.Notef(err2, "baz")
+
+	tests := []struct {
+		about     string
+		marshal   func(error) ([]byte, error)
+		unmarshal func([]byte) (error, error)
+	}{{
+		about:     "cbor",
+		marshal:   binerr.MarshalCBOR,
+		unmarshal: binerr.UnmarshalCBOR,
+	}, {
+		about:     "msgpack",
+		marshal:   binerr.MarshalMsgpack,
+		unmarshal: binerr.UnmarshalMsgpack,
+	}}
+	for _, test := range tests {
+		for _, err := range []error{err1, err3} {
+			data, merr := test.marshal(err)
+			if merr != nil {
+				t.Fatalf("%s: cannot marshal: %v", test.about, merr)
+			}
+			got, uerr := test.unmarshal(data)
+			if uerr != nil {
+				t.Fatalf("%s: cannot unmarshal: %v", test.about, uerr)
+			}
+			if errgo.Details(got) != errgo.Details(err) {
+				t.Errorf("%s: unexpected details; got %s want %s", test.about, errgo.Details(got), errgo.Details(err))
+			}
+			if got.Error() != err.Error() {
+				t.Errorf("%s: unexpected message %q", test.about, got.Error())
+			}
+		}
+		data, _ := test.marshal(err1)
+		got, _ := test.unmarshal(data)
+		if cause := errgo.Cause(got); cause.Error() != "not found" {
+			t.Errorf("%s: unexpected cause %#v", test.about, cause)
+		}
+		data, _ = test.marshal(nil)
+		if got, _ := test.unmarshal(data); got != nil {
+			t.Errorf("%s: unexpected error for nil %#v", test.about, got)
+		}
+		if _, uerr := test.unmarshal([]byte{0xff}); uerr == nil {
+			t.Errorf("%s: no error for bad data", test.about)
+		}
+	}
+}
+
+func TestCompact(t *testing.T) {
+	err := errgo.Notef(errgo.New("foo"), "bar")
+	jsonData, _ := json.Marshal(binerr.Chain(err))
+	for _, marshal := range []func(error) ([]byte, error){
+		binerr.MarshalCBOR,
+		binerr.MarshalMsgpack,
+	} {
+		data, _ := marshal(err)
+		if len(data) >= len(jsonData) {
+			t.Fatalf("encoding is not compact: %d bytes, %d bytes of JSON", len(data), len(jsonData))
+		}
+	}
+}
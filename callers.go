@@ -0,0 +1,90 @@
+//go:build !tinygo && !errgo_nocallers
+
+package errgo
+
+import (
+	"runtime"
+	"strings"
+)
+
+// maxSkippedFrames holds the number of frames examined beyond
+// those recorded when frames are skipped by callerLocations.
+const maxSkippedFrames = 32
+
+// callerLocations fills locs with the source locations starting
+// callDepth stack frames above its caller, skipping any frames in
+// packages registered with SkipPackage or in the runtime package,
+// as when recovering from a panic, and funcs, which must be as
+// long as locs, with the fully qualified names of the functions
+// holding them. It returns the number of locations found.
+//
+// The frames are found with runtime.Callers, which is correct
+// even when callDepth counts frames that have been inlined.
+// Only as many frames as there are locations are examined
+// unless a frame must be skipped, and nothing is allocated
+// when a single location is found.
+func callerLocations(locs []Location, funcs []string, callDepth int) int {
+	skip, _ := skippedPkgs.Load().(map[string]bool)
+	if len(locs) == 1 {
+		var pcs [1]uintptr
+		if runtime.Callers(callDepth+2, pcs[:]) == 1 {
+			// The PC is a return address, so it is
+			// moved back into the call instruction.
+			pc := pcs[0] - 1
+			if fn := runtime.FuncForPC(pc); fn != nil && !skipFrame(fn.Name(), skip) {
+				file, line := fn.FileLine(pc)
+				locs[0], funcs[0] = Location{file, line}, fn.Name()
+				return 1
+			}
+		}
+	}
+	pcs := make([]uintptr, len(locs))
+	n := runtime.Callers(callDepth+2, pcs)
+	if n, ok := fillLocations(locs, funcs, pcs[:n], skip, n < len(pcs)); ok {
+		return n
+	}
+	pcs = make([]uintptr, len(locs)+maxSkippedFrames)
+	n = runtime.Callers(callDepth+2, pcs)
+	n, _ = fillLocations(locs, funcs, pcs[:n], skip, true)
+	return n
+}
+
+// fillLocations fills locs and funcs as described for
+// callerLocations from the frames at pcs, which hold all the
+// frames of the goroutine if complete is set. It reports false
+// if a frame must be skipped and pcs is not complete.
+func fillLocations(locs []Location, funcs []string, pcs []uintptr, skip map[string]bool, complete bool) (int, bool) {
+	frames := runtime.CallersFrames(pcs)
+	i := 0
+	for i < len(locs) {
+		frame, more := frames.Next()
+		if i == 0 && skipFrame(frame.Function, skip) {
+			if !complete {
+				return 0, false
+			}
+			if more {
+				continue
+			}
+		}
+		if frame.File == "" {
+			break
+		}
+		locs[i] = Location{frame.File, frame.Line}
+		funcs[i] = frame.Function
+		i++
+		if !more {
+			break
+		}
+	}
+	return i, true
+}
+
+// skipFrame reports whether a frame in the function with
+// the given fully qualified name should be skipped when
+// recording a location.
+func skipFrame(function string, skip map[string]bool) bool {
+	if strings.HasPrefix(function, "runtime.") {
+		return true
+	}
+	return len(skip) > 0 && skip[funcPackage(function)]
+}
@@ -0,0 +1,26 @@
+//go:build tinygo || errgo_nocallers
+
+package errgo
+
+import "sync/atomic"
+
+// locationCount holds the number of locations
+// recorded by callerLocations.
+var locationCount atomic.Int64
+
+// callerLocations is the version of callerLocations used on
+// runtimes with limited support for finding callers. It
+// examines no stack frames: it fills only the first of locs
+// with a location made from RestrictedLocationLabel and
+// the number of locations recorded so far, and records
+// no function names.
+func callerLocations(locs []Location, funcs []string, callDepth int) int {
+	if len(locs) == 0 {
+		return 0
+	}
+	locs[0] = Location{
+		File: RestrictedLocationLabel,
+		Line: int(locationCount.Add(1)),
+	}
+	return 1
+}
@@ -0,0 +1,47 @@
+//go:build tinygo || errgo_nocallers
+
+package errgo_test
+
+import (
+	"testing"
+
+	"github.com/juju/errgo"
+)
+
+func TestRestrictedLocations(t *testing.T) {
+	err0 := errgo.New("foo")
+	err1 := errgo.Mask(err0)
+	if _, function := errgo.Origin(err1); function != "" {
+		t.Fatalf("unexpected function %q", function)
+	}
+
+	skipIfMinimal(t)
+	loc0 := err0.(errgo.Locationer).Location()
+	loc1 := err1.(errgo.Locationer).Location()
+	if loc0.File != errgo.RestrictedLocationLabel || loc1.File != errgo.RestrictedLocationLabel {
+		t.Fatalf("unexpected locations %v, %v", loc0, loc1)
+	}
+	if loc1.Line <= loc0.Line {
+		t.Fatalf("location numbers do not increase: %v, %v", loc0, loc1)
+	}
+}
+
+func TestRestrictedFingerprint(t *testing.T) {
+	newErr := func() error {
+		return errgo.Notef(errgo.New("foo"), "bar")
+	}
+	if fp0, fp1 := errgo.Fingerprint(newErr()), errgo.Fingerprint(newErr()); fp0 != fp1 {
+		t.Fatalf("fingerprints differ: %q, %q", fp0, fp1)
+	}
+	if fp0, fp1 := errgo.Fingerprint(newErr()), errgo.Fingerprint(errgo.New("foo")); fp0 == fp1 {
+		t.Fatalf("fingerprints are the same: %q", fp0)
+	}
+
+	var s errgo.Stats
+	for i := 0; i < 10; i++ {
+		s.Record(newErr())
+	}
+	if n := len(s.Snapshot().ByFingerprint); n != 1 {
+		t.Fatalf("unexpected fingerprint count %d", n)
+	}
+}
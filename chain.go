@@ -0,0 +1,614 @@
+package errgo
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// message returns the message held in the given link
+// of an error chain, not including the message of any
+// underlying error. Errors of types registered with
+// RegisterWrapper are split as arranged there.
+func message(err error) string {
+	switch err := err.(type) {
+	case Wrapper:
+		return err.Message()
+	}
+	if msg, _, ok := splitWrapped(err); ok {
+		return msg
+	}
+	switch err.(type) {
+	case interface{ WrappedErrors() []error }, interface{ Errors() []error }:
+		// The message of a multi-error container is made
+		// up of the messages of the errors it holds.
+		return ""
+	case interface{ Unwrap() []error }:
+		// Likewise for errors.Join, but not for errors such as
+		// those made by fmt.Errorf with several %w verbs, which
+		// may add their own text.
+		if isJoin(err) {
+			return ""
+		}
+	}
+	return err.Error()
+}
+
+// underlying returns the error underlying the given
+// link of an error chain, or nil if there is none.
+// For errors of types registered with RegisterWrapper,
+// it is the next error returned by the registered function.
+func underlying(err error) error {
+	switch err := err.(type) {
+	case Wrapper:
+		return err.Underlying()
+	}
+	_, next, _ := splitWrapped(err)
+	return next
+}
+
+// directCause returns the cause recorded by err itself,
+// or nil if it has none.
+func directCause(err error) error {
+	if err, ok := err.(Causer); ok {
+		return err.Cause()
+	}
+	return nil
+}
+
+// Find returns the first error in the error chain of err for
+// which match returns true, or nil if there is none.
+//
+// Each error in the chain is tried in turn, starting with err and
+// following underlying errors (see Wrapper). Errors that do not
+// implement Wrapper are followed through their Unwrap method, if
+// any, as defined by the standard errors package. When an error
+// wraps several errors (see MultiWrapper and Details), each of their
+// chains is searched in turn before continuing. If none of those match, the
+// cause of each error in the chain (see Causer) is tried, in the
+// same order.
+func Find(err error, match func(error) bool) error {
+	var chain []error
+	if found := find(err, match, &chain); found != nil {
+		return found
+	}
+	for _, e := range chain {
+		if cause := directCause(e); cause != nil && match(cause) {
+			return cause
+		}
+	}
+	return nil
+}
+
+// find is the recursive implementation of Find. It adds each
+// error that it visits to *chain.
+func find(err error, match func(error) bool, chain *[]error) error {
+	for e := err; e != nil; e = next(e) {
+		if match(e) {
+			return e
+		}
+		*chain = append(*chain, e)
+		for _, branch := range branches(e) {
+			if found := find(branch, match, chain); found != nil {
+				return found
+			}
+		}
+	}
+	return nil
+}
+
+// Transform returns a new chain made by calling f with each error
+// in the chain of err, outermost first, for example to redact
+// messages before errors cross a trust boundary. If f returns false
+// or a nil error, the error is dropped from the new chain. Otherwise the error
+// returned by f takes its place; to keep the error unchanged, f
+// should return it.
+//
+// As with Clone, only errors of types that embed Err by value, such
+// as *Err, *MultiErr and the errors returned by WithCode or Tag, are
+// linked into the new chain: they are copied, with their underlying error
+// replaced by the transformed remainder of the chain, and the
+// errors wrapped by a *MultiErr are transformed in turn. An error of
+// any other type returned by f ends the new chain, and the walk
+// stops at the first error of another type that is kept. Causes
+// are not changed (but see MapCause). The original errors are
+// not modified. If every error is dropped, Transform returns nil.
+func Transform(err error, f func(link error) (error, bool)) error {
+	if err == nil {
+		return nil
+	}
+	newLink, keep := f(err)
+	e := embeddedErr(err)
+	if e == nil {
+		if !keep || newLink == nil {
+			return Transform(next(err), f)
+		}
+		return newLink
+	}
+	rest := Transform(e.Underlying_, f)
+	if !keep || newLink == nil {
+		return rest
+	}
+	newErr, ne := copyLink(newLink)
+	if ne == nil {
+		return newLink
+	}
+	ne.Underlying_ = rest
+	if m := embeddedMultiErr(newErr); m != nil {
+		var branches []error
+		for _, branch := range m.UnderlyingErrors_ {
+			if branch := Transform(branch, f); branch != nil {
+				branches = append(branches, branch)
+			}
+		}
+		m.UnderlyingErrors_ = branches
+	}
+	return newErr
+}
+
+// Depth returns the number of errors in the chain of err, found by
+// following underlying errors (see Wrapper) and errors wrapped with
+// an Unwrap method. Errors wrapped by an error that wraps several
+// errors (see MultiWrapper) are not counted.
+func Depth(err error) int {
+	n := 0
+	for ; err != nil; err = next(err) {
+		n++
+	}
+	return n
+}
+
+// HasLocation reports whether any error in the chain of
+// err (see Depth) recorded its location (see Locationer).
+func HasLocation(err error) bool {
+	_, ok := OutermostLocation(err)
+	return ok
+}
+
+// OutermostLocation returns the location recorded by the outermost
+// error in the chain of err (see Depth) that recorded one, and
+// whether there was such an error.
+func OutermostLocation(err error) (Location, bool) {
+	for ; err != nil; err = next(err) {
+		if loc, ok := location(err); ok {
+			return loc, true
+		}
+	}
+	return Location{}, false
+}
+
+// InnermostLocation returns the location recorded by the innermost
+// error in the chain of err (see Depth) that recorded one, and
+// whether there was such an error.
+func InnermostLocation(err error) (Location, bool) {
+	var found Location
+	for ; err != nil; err = next(err) {
+		if loc, ok := location(err); ok {
+			found = loc
+		}
+	}
+	return found, found.IsSet()
+}
+
+// location returns the location recorded by err, if any.
+func location(err error) (Location, bool) {
+	if err, ok := err.(Locationer); ok {
+		if loc := err.Location(); loc.IsSet() {
+			return loc, true
+		}
+	}
+	return Location{}, false
+}
+
+// sameError reports whether a and b identify the same error,
+// either because they are equal or because either one reports
+// that it is the other with an Is method (see errors.Is).
+// Unlike a plain comparison, it does not panic when
+// the errors are of an uncomparable type.
+func sameError(a, b error) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return errors.Is(a, b) || errors.Is(b, a)
+}
+
+// Links returns all the errors in the chain of err, in the order
+// that Find visits them, not including causes, so that packages
+// rendering errors can describe each error in turn. Use Message
+// to obtain the text contributed by each error.
+func Links(err error) []error {
+	return links(err)
+}
+
+// Message returns the message held by err itself, not including
+// the message of any underlying error. For errors that implement
+// Wrapper, it is the result of their Message method; for errors
+// that wrap several errors (see Branches) it is usually empty,
+// and for other errors it is the result of Error.
+func Message(err error) string {
+	return message(err)
+}
+
+// Underlying returns the error underlying err (see Wrapper),
+// or nil if there is none. Unlike the chain followed by Find,
+// errors wrapped with an Unwrap method are not followed.
+func Underlying(err error) error {
+	return underlying(err)
+}
+
+// Branches returns the errors wrapped by err if it wraps several
+// errors, as described for Details, or nil otherwise.
+func Branches(err error) []error {
+	return branches(err)
+}
+
+// links returns all the errors in the chain of err, in
+// the order that Find visits them, not including causes.
+func links(err error) []error {
+	var chain []error
+	find(err, func(error) bool {
+		return false
+	}, &chain)
+	return chain
+}
+
+// branches returns the errors wrapped by err if
+// it implements MultiWrapper or is a multi-error
+// container from another package (see foreignBranches).
+func branches(err error) []error {
+	if err, ok := err.(MultiWrapper); ok {
+		return err.UnderlyingErrors()
+	}
+	return foreignBranches(err)
+}
+
+// foreignBranches returns the errors held by err if it is a
+// multi-error container as implemented by the
+// github.com/hashicorp/go-multierror or go.uber.org/multierr
+// packages, or if it wraps several errors with an Unwrap method
+// returning []error, as errors.Join does; otherwise
+// it returns nil.
+func foreignBranches(err error) []error {
+	switch err := err.(type) {
+	case interface{ WrappedErrors() []error }:
+		// github.com/hashicorp/go-multierror
+		return err.WrappedErrors()
+	case interface{ Errors() []error }:
+		// go.uber.org/multierr
+		return err.Errors()
+	case interface{ Unwrap() []error }:
+		return err.Unwrap()
+	}
+	return nil
+}
+
+// isJoin reports whether the message of err consists only
+// of the messages of the errors it wraps, one per line,
+// as for errors returned by errors.Join.
+func isJoin(err error) bool {
+	var msgs []string
+	for _, err := range foreignBranches(err) {
+		if err != nil {
+			msgs = append(msgs, err.Error())
+		}
+	}
+	return err.Error() == strings.Join(msgs, "\n")
+}
+
+// next returns the error following err in its error chain,
+// as traversed by Find.
+func next(err error) error {
+	switch err := err.(type) {
+	case Wrapper:
+		return err.Underlying()
+	case interface{ Unwrap() error }:
+		return err.Unwrap()
+	}
+	return nil
+}
+
+// EqualChains reports whether the error chains a and b are
+// equal, ignoring the source locations recorded in them.
+// See ChainDifference for details of how chains are compared.
+func EqualChains(a, b error) bool {
+	return ChainDifference(a, b) == ""
+}
+
+// ChainDifference compares the error chains a and b, following
+// the underlying errors of each (see Wrapper), and returns a
+// description of the first difference found, or the empty
+// string if they are equal.
+//
+// Corresponding links in the chains are equal when they have the
+// same type and message, their causes are either identical
+// or themselves equal chains, and any errors they wrap
+// (see MultiWrapper) are equal chains. Source locations are ignored,
+// so an expected chain can be constructed in a test and compared
+// against the actual result.
+func ChainDifference(a, b error) string {
+	for i := 0; ; i++ {
+		if a == nil || b == nil {
+			if a != b {
+				return fmt.Sprintf("link %d: %s != %s", i, linkString(a), linkString(b))
+			}
+			return ""
+		}
+		if ta, tb := reflect.TypeOf(a), reflect.TypeOf(b); ta != tb {
+			return fmt.Sprintf("link %d: type %v != %v", i, ta, tb)
+		}
+		if ma, mb := message(a), message(b); ma != mb {
+			return fmt.Sprintf("link %d: message %q != %q", i, ma, mb)
+		}
+		if ca, cb := directCause(a), directCause(b); ca != cb {
+			if ca == nil || cb == nil {
+				return fmt.Sprintf("link %d: cause %s != %s", i, linkString(ca), linkString(cb))
+			}
+			if d := ChainDifference(ca, cb); d != "" {
+				return fmt.Sprintf("link %d: cause: %s", i, d)
+			}
+		}
+		ba, bb := branches(a), branches(b)
+		if len(ba) != len(bb) {
+			return fmt.Sprintf("link %d: %d underlying errors != %d", i, len(ba), len(bb))
+		}
+		for j := range ba {
+			if d := ChainDifference(ba[j], bb[j]); d != "" {
+				return fmt.Sprintf("link %d: underlying error %d: %s", i, j, d)
+			}
+		}
+		a, b = underlying(a), underlying(b)
+	}
+}
+
+// linkString returns a short description of the given link
+// for use in ChainDifference.
+func linkString(err error) string {
+	if err == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("%q", message(err))
+}
+
+// Diff returns a line-oriented diff between descriptions of
+// the error chains a and b, or the empty string if the
+// descriptions are the same. Each link in a chain is
+// described on its own line by its type, its message and
+// its cause, if any. Errors wrapped by a link (see MultiWrapper)
+// are described on the following lines, indented by two
+// spaces. Lines present only in a are prefixed with "-", lines
+// present only in b with "+" and common lines with a space,
+// for example:
+//
+//	 *errgo.Err "cannot open config"
+//	-*errgo.Err "bad syntax" cause *errors.errorString "bad syntax"
+//	+*errgo.Err "file not found" cause *errors.errorString "file not found"
+//
+// As with ChainDifference, source locations are ignored.
+func Diff(a, b error) string {
+	la, lb := describeChain(nil, a, ""), describeChain(nil, b, "")
+	// Compute the longest common subsequence of lines
+	// so that insertions and deletions line up.
+	lcs := make([][]int, len(la)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(lb)+1)
+	}
+	for i := len(la) - 1; i >= 0; i-- {
+		for j := len(lb) - 1; j >= 0; j-- {
+			if la[i] == lb[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	var buf strings.Builder
+	same := true
+	i, j := 0, 0
+	for i < len(la) || j < len(lb) {
+		switch {
+		case i < len(la) && j < len(lb) && la[i] == lb[j]:
+			buf.WriteString(" " + la[i] + "\n")
+			i++
+			j++
+		case j == len(lb) || i < len(la) && lcs[i+1][j] >= lcs[i][j+1]:
+			buf.WriteString("-" + la[i] + "\n")
+			i++
+			same = false
+		default:
+			buf.WriteString("+" + lb[j] + "\n")
+			j++
+			same = false
+		}
+	}
+	if same {
+		return ""
+	}
+	return buf.String()
+}
+
+// describeChain appends a description of each link in
+// the chain of err to lines, each prefixed by indent,
+// and returns the result.
+func describeChain(lines []string, err error, indent string) []string {
+	for ; err != nil; err = underlying(err) {
+		line := fmt.Sprintf("%s%T %q", indent, err, message(err))
+		if cause := directCause(err); cause != nil {
+			line += fmt.Sprintf(" cause %T %q", cause, cause.Error())
+		}
+		lines = append(lines, line)
+		for _, branch := range branches(err) {
+			lines = describeChain(lines, branch, indent+"  ")
+		}
+	}
+	return lines
+}
+
+// Clone returns a deep copy of the error chain starting at err.
+// Each error in the chain of a type that embeds Err by value,
+// such as *Err, *MultiErr and the errors returned by WithCode or
+// Tag, is copied, so that the copy may be modified, for example
+// with SetLocation, without affecting the original. The chain is
+// copied up to the first error that does not embed Err by value
+// (types that embed *Err cannot be copied without sharing it);
+// that error is shared between the original and the copy.
+//
+// Causes are not copied, so that the cause of the copy is
+// identical to the cause of the original and can still be
+// compared against well known error values.
+func Clone(err error) error {
+	newErr, e := copyLink(err)
+	if e == nil {
+		return err
+	}
+	e.Underlying_ = Clone(e.Underlying_)
+	if m := embeddedMultiErr(newErr); m != nil {
+		m.UnderlyingErrors_ = make([]error, len(m.UnderlyingErrors_))
+		for i, branch := range embeddedMultiErr(err).UnderlyingErrors_ {
+			m.UnderlyingErrors_[i] = Clone(branch)
+		}
+	}
+	return newErr
+}
+
+// MapCause is like Clone except that the cause of each copied
+// error (see Causer) is replaced by the result of calling f with
+// it, for example to convert errors from a database driver into
+// errors defined by the application, while the locations and
+// messages of the errors are retained. Concealed causes are not
+// passed to f. Causes that f leaves unchanged and that are
+// themselves errors in the copied chain are replaced by
+// their copies.
+func MapCause(err error, f func(error) error) error {
+	return mapCause(err, f, make(map[error]error))
+}
+
+// mapCause is the recursive implementation of MapCause.
+// It records the copy of each error in copies.
+func mapCause(err error, f func(error) error, copies map[error]error) error {
+	newErr, e := copyLink(err)
+	if e == nil {
+		return err
+	}
+	e.Underlying_ = mapCause(e.Underlying_, f, copies)
+	if m := embeddedMultiErr(newErr); m != nil {
+		m.UnderlyingErrors_ = make([]error, len(m.UnderlyingErrors_))
+		for i, branch := range embeddedMultiErr(err).UnderlyingErrors_ {
+			m.UnderlyingErrors_[i] = mapCause(branch, f, copies)
+		}
+	}
+	e.Cause_ = mappedCause(e.Cause_, f, copies)
+	copies[err] = newErr
+	return newErr
+}
+
+// mappedCause returns the replacement for the given
+// cause of an error copied by mapCause.
+func mappedCause(cause error, f func(error) error, copies map[error]error) error {
+	if cause == nil {
+		return nil
+	}
+	mapped := f(cause)
+	if embeddedErr(cause) != nil {
+		if c, ok := copies[cause]; ok && mapped == cause {
+			return c
+		}
+	}
+	return mapped
+}
+
+// embedder is implemented by every error type that embeds
+// Err, including those defined in other packages, through
+// the promoted embedded method.
+type embedder interface {
+	error
+	embedded() *Err
+}
+
+// embedded returns e. Its promotion to the types that embed
+// Err allows their chains to be copied (see copyLink).
+func (e *Err) embedded() *Err {
+	return e
+}
+
+// embeddedMulti returns e. Its promotion to the types that
+// embed MultiErr, such as Validation, allows the errors
+// they wrap to be copied.
+func (e *MultiErr) embeddedMulti() *MultiErr {
+	return e
+}
+
+// embeddedErr returns the Err embedded in err, or nil if err
+// is not a non-nil pointer to a type that embeds Err by value.
+// An Err embedded through a pointer, as in
+//
+//	type myErr struct {
+//		*errgo.Err
+//	}
+//
+// is not returned, as a copy of the error would share it.
+func embeddedErr(err error) *Err {
+	e, ok := err.(embedder)
+	if !ok {
+		return nil
+	}
+	if embedded := e.embedded(); holds(err, embedded) {
+		return embedded
+	}
+	return nil
+}
+
+// embeddedMultiErr returns the MultiErr embedded by value in
+// err, which must be a non-nil pointer, or nil if it has none.
+func embeddedMultiErr(err error) *MultiErr {
+	if e, ok := err.(interface{ embeddedMulti() *MultiErr }); ok {
+		if embedded := e.embeddedMulti(); holds(err, embedded) {
+			return embedded
+		}
+	}
+	return nil
+}
+
+// holds reports whether err is a non-nil pointer to a
+// value whose memory holds the value pointed to by p,
+// so that the value is copied along with err.
+func holds(err error, p interface{}) bool {
+	v := reflect.ValueOf(err)
+	if v.Kind() != reflect.Pointer || v.IsNil() {
+		return false
+	}
+	start := v.Pointer()
+	addr := reflect.ValueOf(p).Pointer()
+	return addr >= start && addr < start+v.Type().Elem().Size()
+}
+
+// copyLink returns a shallow copy of err, along with the Err
+// embedded in the copy, if err is a link that embeds Err (see
+// embeddedErr); otherwise it returns nil, nil. The copy shares
+// no frames or format arguments with err, and may be changed
+// with SetLocation even if err was passed to Created.
+func copyLink(err error) (error, *Err) {
+	if embeddedErr(err) == nil {
+		return nil, nil
+	}
+	v := reflect.ValueOf(err).Elem()
+	c := reflect.New(v.Type())
+	c.Elem().Set(v)
+	newErr := c.Interface().(error)
+	e := newErr.(embedder).embedded()
+	e.frozen = false
+	if e.Extra_ != nil {
+		x := *e.Extra_
+		if x.Frames != nil {
+			x.Frames = append([]Location(nil), x.Frames...)
+		}
+		if x.FrameFunctions != nil {
+			x.FrameFunctions = append([]string(nil), x.FrameFunctions...)
+		}
+		if x.Args != nil {
+			x.Args = append([]interface{}(nil), x.Args...)
+		}
+		e.Extra_ = &x
+	}
+	return newErr, e
+}
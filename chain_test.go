@@ -0,0 +1,461 @@
+package errgo_test
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/juju/errgo"
+	"github.com/juju/errgo/errgotest"
+)
+
+var chainDifferenceTests = []struct {
+	about  string
+	a, b   error
+	expect string
+}{{
+	about: "nil chains",
+}, {
+	about: "identical chains with different locations",
+	a:     errgo.Notef(errgo.New("foo"), "bar"),
+	b:     errgo.Notef(errgo.New("foo"), "bar"),
+}, {
+	about: "equal causes",
+	a:     errgo.WithCausef(errgo.New("foo"), errNotFound, "bar"),
+	b:     errgo.WithCausef(errgo.New("foo"), errNotFound, "bar"),
+}, {
+	about: "equivalent causes",
+	a:     errgo.WithCausef(nil, errgo.New("cause"), "bar"),
+	b:     errgo.WithCausef(nil, errgo.New("cause"), "bar"),
+}, {
+	about:  "different messages",
+	a:      errgo.Notef(errgo.New("foo"), "bar"),
+	b:      errgo.Notef(errgo.New("foo"), "baz"),
+	expect: `link 0: message "bar" != "baz"`,
+}, {
+	about:  "different lengths",
+	a:      errgo.Notef(errgo.New("foo"), "bar"),
+	b:      errgo.New("bar"),
+	expect: `link 1: "foo" != <nil>`,
+}, {
+	about:  "different types",
+	a:      errgo.Notef(errNotFound, "bar"),
+	b:      errgo.Notef(errgo.New("not found"), "bar"),
+	expect: "link 1: type *errors.errorString != *errgo.Err",
+}, {
+	about:  "masked cause",
+	a:      errgo.Mask(errNotFound),
+	b:      errgo.Mask(errNotFound, errgo.Any),
+	expect: `link 0: cause <nil> != "not found"`,
+}, {
+	about:  "different causes",
+	a:      errgo.WithCausef(nil, errgo.New("cause"), "bar"),
+	b:      errgo.WithCausef(nil, errgo.New("other"), "bar"),
+	expect: `link 0: cause: link 0: message "cause" != "other"`,
+}}
+
+func TestChainDifference(t *testing.T) {
+	for i, test := range chainDifferenceTests {
+		if got := errgo.ChainDifference(test.a, test.b); got != test.expect {
+			t.Errorf("test %d (%s): got %q want %q", i, test.about, got, test.expect)
+		}
+		if ok := errgo.EqualChains(test.a, test.b); ok != (test.expect == "") {
+			t.Errorf("test %d (%s): EqualChains returned %v", i, test.about, ok)
+		}
+	}
+}
+
+var diffTests = []struct {
+	about  string
+	a, b   error
+	expect string
+}{{
+	about: "nil chains",
+}, {
+	about: "identical chains with different locations",
+	a:     errgo.Notef(errgo.New("foo"), "bar"),
+	b:     errgo.Notef(errgo.New("foo"), "bar"),
+}, {
+	about: "changed link",
+	a:     errgo.Notef(errgo.WithCausef(nil, errNotFound, "foo"), "bar"),
+	b:     errgo.Notef(errgo.New("baz"), "bar"),
+	expect: ` *errgo.Err "bar"
+-*errgo.Err "foo" cause *errors.errorString "not found"
++*errgo.Err "baz"
+`,
+}, {
+	about: "inserted link",
+	a:     errgo.Notef(errNotFound, "bar"),
+	b:     errgo.Notef(errgo.Mask(errNotFound, errgo.Any), "bar"),
+	expect: ` *errgo.Err "bar"
++*errgo.Err "" cause *errors.errorString "not found"
+ *errors.errorString "not found"
+`,
+}, {
+	about: "branches",
+	a:     errgo.Combine(errgo.New("one"), errgo.New("two")),
+	b:     errgo.Combine(errgo.New("one")),
+	expect: ` *errgo.MultiErr ""
+   *errgo.Err "one"
+-  *errgo.Err "two"
+`,
+}}
+
+func TestDiff(t *testing.T) {
+	for i, test := range diffTests {
+		if got := errgo.Diff(test.a, test.b); got != test.expect {
+			t.Errorf("test %d (%s): got\n%s\nwant\n%s", i, test.about, got, test.expect)
+		}
+	}
+}
+
+func TestClone(t *testing.T) {
+	if err := errgo.Clone(nil); err != nil {
+		t.Fatalf("Clone(nil) returned %#v", err)
+	}
+	if err := errgo.Clone(errNotFound); err != errNotFound {
+		t.Fatalf("Clone of foreign error returned %#v", err)
+	}
+	orig := errgo.Notef(errgo.WithCausef(errNotFound, errNotFound, "foo"), "bar")
+	origDetails := errgo.Details(orig)
+
+	clone := errgo.Clone(orig)
+	if details := errgo.Details(clone); details != origDetails {
+		t.Fatalf("unexpected details: got %q want %q", details, origDetails)
+	}
+	clone.(*errgo.Err).Message_ = "redacted"
+	clone.(*errgo.Err).Underlying_.(*errgo.Err).Message_ = "redacted"
+	if details := errgo.Details(orig); details != origDetails {
+		t.Fatalf("original was changed: got %q want %q", details, origDetails)
+	}
+	if cause := errgo.Cause(clone.(*errgo.Err).Underlying()); cause != errNotFound {
+		t.Fatalf("unexpected cause %#v", cause)
+	}
+	if u := clone.(*errgo.Err).Underlying_.(*errgo.Err).Underlying(); u != errNotFound {
+		t.Fatalf("foreign error was not shared; got %#v", u)
+	}
+}
+
+func TestMapCause(t *testing.T) {
+	errDriver := errgo.New("driver: no rows")
+	toDomain := func(err error) error {
+		if err == errDriver {
+			return errNotFound
+		}
+		return err
+	}
+	err0 := errgo.Mask(errDriver, errgo.Any)
+	err1 := errgo.Combine(errgo.NoteMask(err0, "get user", errgo.Any), errgo.Notef(err0, "masked"))
+	orig := errgo.NoteMask(err1, "request", errgo.Any)
+	origDetails := errgo.Details(orig)
+
+	mapped := errgo.MapCause(orig, toDomain)
+	if details := errgo.Details(mapped); details != origDetails {
+		t.Fatalf("unexpected details: got %q want %q", details, origDetails)
+	}
+	if details := errgo.Details(orig); details != origDetails {
+		t.Fatalf("original was changed: got %q", details)
+	}
+	causes := errgo.Causes(mapped)
+	if len(causes) != 2 || causes[0] != errNotFound || causes[1] == errNotFound {
+		t.Fatalf("unexpected causes %#v", causes)
+	}
+	if errgo.Cause(errgo.MapCause(err0, toDomain)) != errNotFound {
+		t.Fatalf("cause was not mapped")
+	}
+	if errgo.Cause(errgo.MapCause(errgo.WithCode(errgo.Mask(err0, errgo.Any), 4242), toDomain)) != errNotFound {
+		t.Fatalf("cause below code link was not mapped")
+	}
+	foreignErr := fmt.Errorf("foreign")
+	if err := errgo.MapCause(foreignErr, toDomain); err != foreignErr {
+		t.Fatalf("unexpected error %#v", err)
+	}
+	if err := errgo.MapCause(nil, toDomain); err != nil {
+		t.Fatalf("unexpected error %#v", err)
+	}
+}
+
+func TestTransform(t *testing.T) {
+	err0 := errgo.New("secret password")
+	err1 := errgo.Mask(err0)
+	err2 := errgo.Combine(errgo.Notef(err1, "one"), nil)
+	err3 := errgo.Notef(err2, "two")
+	origDetails := errgo.Details(err3)
+
+	redact := func(link error) (error, bool) {
+		if link, ok := link.(*errgo.Err); ok {
+			if link.Message_ == "" {
+				return nil, false
+			}
+			if strings.Contains(link.Message_, "secret") {
+				newLink := *link
+				newLink.Message_ = "redacted"
+				return &newLink, true
+			}
+		}
+		return link, true
+	}
+	err := errgo.Transform(err3, redact)
+	if got := err.Error(); got != "two: one: redacted" {
+		t.Fatalf("unexpected message %q", got)
+	}
+	if sourceLocations {
+		want := "[{chain_test.go: two} {chain_test.go: [{chain_test.go: one} {chain_test.go: redacted}]}]"
+		if got := errgotest.NormalizeStack(errgo.Details(err), nil); got != want {
+			t.Fatalf("unexpected details\ngot  %s\nwant %s", got, want)
+		}
+	}
+	if errgo.Details(err3) != origDetails {
+		t.Fatalf("original was changed")
+	}
+
+	dropAll := func(error) (error, bool) {
+		return nil, false
+	}
+	if err := errgo.Transform(err3, dropAll); err != nil {
+		t.Fatalf("unexpected error %#v", err)
+	}
+	keep := func(link error) (error, bool) {
+		return link, true
+	}
+	if err := errgo.Transform(errNotFound, keep); err != errNotFound {
+		t.Fatalf("unexpected error %#v", err)
+	}
+	err4 := errgo.Notef(errgo.Tag(errgo.Notef(errgo.New("secret path /etc/x"), "inner"), "t"), "outer")
+	if got, want := errgo.Transform(err4, redact).Error(), "outer: inner: redacted"; got != want {
+		t.Fatalf("got %q want %q", got, want)
+	}
+	wrapped := fmt.Errorf("wrapped: %w", errgo.New("inner"))
+	dropForeign := func(link error) (error, bool) {
+		_, ok := link.(*errgo.Err)
+		return link, ok
+	}
+	if err := errgo.Transform(wrapped, dropForeign); err.Error() != "inner" {
+		t.Fatalf("unexpected error %#v", err)
+	}
+}
+
+// pointerEmbeddingErr embeds *errgo.Err, so
+// that copies of it share the same Err.
+type pointerEmbeddingErr struct {
+	*errgo.Err
+}
+
+func TestClonePointerEmbedding(t *testing.T) {
+	err0 := errgo.New("foo")
+	e := &errgo.Err{
+		Message_:    "bar",
+		Underlying_: err0,
+	}
+	errgo.Created(e)
+	err := &pointerEmbeddingErr{e}
+	outer := errgo.Notef(err, "baz")
+
+	clone := errgo.Clone(outer)
+	if got := clone.(*errgo.Err).Underlying(); got != err {
+		t.Fatalf("pointer-embedding error was copied: %#v", got)
+	}
+	if e.Underlying_ != err0 {
+		t.Fatalf("original error was modified")
+	}
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("original error was unfrozen")
+		}
+	}()
+	e.SetLocation(0)
+}
+
+func TestTransformPointerEmbedding(t *testing.T) {
+	err0 := errgo.New("foo")
+	e := &errgo.Err{
+		Message_:    "bar",
+		Underlying_: err0,
+	}
+	err := &pointerEmbeddingErr{e}
+	got := errgo.Transform(err, func(link error) (error, bool) {
+		return link, link != err0
+	})
+	if got != err || e.Underlying_ != err0 {
+		t.Fatalf("pointer-embedding error was modified: %#v", got)
+	}
+	errgo.MapCause(errgo.Notef(err, "baz"), func(error) error {
+		return someErr
+	})
+	if e.Underlying_ != err0 || e.Cause_ != nil {
+		t.Fatalf("pointer-embedding error was modified by MapCause")
+	}
+}
+
+func TestCloneWrappers(t *testing.T) {
+	errgo.SetFrameDepth(2)
+	defer errgo.SetFrameDepth(0)
+	err0 := errgo.Newf("inner %s", "x")
+	orig := errgo.Notef(errgo.WithCode(err0, 4242), "outer")
+
+	clone := errgo.Clone(orig)
+	if errgo.Details(clone) != errgo.Details(orig) || errgo.CodeOf(clone) != 4242 {
+		t.Fatalf("unexpected clone %s", errgo.Details(clone))
+	}
+	codeLink := clone.(*errgo.Err).Underlying()
+	if codeLink == orig.(*errgo.Err).Underlying() {
+		t.Fatalf("code link was not copied")
+	}
+	inner := codeLink.(errgo.Wrapper).Underlying().(*errgo.Err)
+	if inner == err0 {
+		t.Fatalf("link below code link was not copied")
+	}
+	_, args := inner.FormatArgs()
+	args[0] = "changed"
+	if _, args := err0.(*errgo.Err).FormatArgs(); args[0] != "x" {
+		t.Fatalf("format arguments shared with the original")
+	}
+
+	skipIfNoSourceLocations(t)
+	inner.Frames()[0].Line = -1
+	if err0.(*errgo.Err).Frames()[0].Line == -1 {
+		t.Fatalf("frames shared with the original")
+	}
+
+	// The clone of a created error may be relocated.
+	inner.SetLocation(0)
+	if inner.Location() == err0.(*errgo.Err).Location() {
+		t.Fatalf("location was not changed")
+	}
+}
+
+func TestFind(t *testing.T) {
+	isMessage := func(msg string) func(error) bool {
+		return func(err error) bool {
+			return err.Error() == msg
+		}
+	}
+	cause := errgo.New("cause")
+	wrapped := fmt.Errorf("wrapped: %w", errgo.New("inner"))
+	err := errgo.Notef(errgo.WithCausef(wrapped, cause, "bar"), "foo")
+	tests := []struct {
+		about  string
+		match  func(error) bool
+		expect error
+	}{{
+		about:  "outermost error",
+		match:  errgo.Any,
+		expect: err,
+	}, {
+		about:  "underlying error",
+		match:  isMessage("bar: wrapped: inner"),
+		expect: err.(errgo.Wrapper).Underlying(),
+	}, {
+		about:  "standard library wrapper",
+		match:  isMessage("wrapped: inner"),
+		expect: wrapped,
+	}, {
+		about:  "error found through Unwrap",
+		match:  isMessage("inner"),
+		expect: errors.Unwrap(wrapped),
+	}, {
+		about:  "cause",
+		match:  errgo.Is(cause),
+		expect: cause,
+	}, {
+		about: "no match",
+		match: isMessage("nothing"),
+	}}
+	for i, test := range tests {
+		if got := errgo.Find(err, test.match); got != test.expect {
+			t.Errorf("test %d (%s): got %#v want %#v", i, test.about, got, test.expect)
+		}
+	}
+	if got := errgo.Find(nil, errgo.Any); got != nil {
+		t.Errorf("Find(nil) returned %#v", got)
+	}
+}
+
+func TestChainLocations(t *testing.T) {
+	inner := errgo.New("inner")
+	wrapped := fmt.Errorf("wrapped: %w", inner)
+	outer := errgo.Notef(wrapped, "outer")
+	innerLoc := inner.(errgo.Locationer).Location()
+	outerLoc := outer.(errgo.Locationer).Location()
+	multi := errgo.Combine(inner, outer)
+	multiLoc := multi.(errgo.Locationer).Location()
+	tests := []struct {
+		about          string
+		err            error
+		depth          int
+		outer, inner   errgo.Location
+		expectLocation bool
+	}{{
+		about: "nil error",
+	}, {
+		about: "foreign error",
+		err:   errNotFound,
+		depth: 1,
+	}, {
+		about:          "single error",
+		err:            inner,
+		depth:          1,
+		outer:          innerLoc,
+		inner:          innerLoc,
+		expectLocation: true,
+	}, {
+		about:          "chain through a standard library wrapper",
+		err:            outer,
+		depth:          3,
+		outer:          outerLoc,
+		inner:          innerLoc,
+		expectLocation: true,
+	}, {
+		about:          "multiple error counts as one link",
+		err:            multi,
+		depth:          1,
+		outer:          multiLoc,
+		inner:          multiLoc,
+		expectLocation: true,
+	}}
+	for i, test := range tests {
+		if got := errgo.Depth(test.err); got != test.depth {
+			t.Errorf("test %d (%s): got depth %d want %d", i, test.about, got, test.depth)
+		}
+		if !sourceLocations {
+			continue
+		}
+		if got := errgo.HasLocation(test.err); got != test.expectLocation {
+			t.Errorf("test %d (%s): got HasLocation %v want %v", i, test.about, got, test.expectLocation)
+		}
+		if got, ok := errgo.OutermostLocation(test.err); got != test.outer || ok != test.expectLocation {
+			t.Errorf("test %d (%s): got outermost location %v, %v want %v", i, test.about, got, ok, test.outer)
+		}
+		if got, ok := errgo.InnermostLocation(test.err); got != test.inner || ok != test.expectLocation {
+			t.Errorf("test %d (%s): got innermost location %v, %v want %v", i, test.about, got, ok, test.inner)
+		}
+	}
+}
+
+func TestLinks(t *testing.T) {
+	err0 := errgo.New("foo")
+	err1 := errgo.Combine(someErr, err0)
+	err2 := errgo.Notef(err1, "bar")
+	if got, want := errgo.Links(err2), []error{err2, err1, someErr, err0}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected links %v", got)
+	}
+	for i, want := range []string{"bar", "", someErr.Error(), "foo"} {
+		if got := errgo.Message(errgo.Links(err2)[i]); got != want {
+			t.Errorf("link %d: unexpected message %q want %q", i, got, want)
+		}
+	}
+	if got := errgo.Underlying(err2); got != err1 {
+		t.Fatalf("unexpected underlying error %#v", got)
+	}
+	if got := errgo.Underlying(someErr); got != nil {
+		t.Fatalf("unexpected underlying error %#v", got)
+	}
+	if got := errgo.Branches(err1); !reflect.DeepEqual(got, []error{someErr, err0}) {
+		t.Fatalf("unexpected branches %v", got)
+	}
+	if got := errgo.Branches(err2); got != nil {
+		t.Fatalf("unexpected branches %v", got)
+	}
+}
@@ -0,0 +1,88 @@
+package errgo
+
+import (
+	"sort"
+	"sync"
+)
+
+var checkers = struct {
+	mu sync.RWMutex
+	m  map[string]func(error) bool
+}{
+	m: make(map[string]func(error) bool),
+}
+
+// RegisterChecker registers a function that classifies errors under
+// the given name, for example "conflict" or "quota". The function is
+// called with the cause of an error (see Cause) and should report
+// whether that cause belongs to the named classification.
+//
+// If RegisterChecker is called twice with the same name or if check is
+// nil, it panics.
+func RegisterChecker(name string, check func(error) bool) {
+	checkers.mu.Lock()
+	defer checkers.mu.Unlock()
+	if check == nil {
+		panic("errgo: RegisterChecker check is nil")
+	}
+	if _, dup := checkers.m[name]; dup {
+		panic("errgo: RegisterChecker called twice for checker " + name)
+	}
+	checkers.m[name] = check
+}
+
+// Matches reports whether the cause of err matches the
+// checker registered with the given name. It returns false
+// if err is nil or no such checker has been registered.
+func Matches(err error, name string) bool {
+	if err == nil {
+		return false
+	}
+	checkers.mu.RLock()
+	check := checkers.m[name]
+	checkers.mu.RUnlock()
+	return check != nil && check(Cause(err))
+}
+
+// Checker returns a function that reports whether an error
+// matches the checker registered with the given name.
+// It is intended to be used as a "pass" argument to Mask
+// and friends; for example:
+//
+//	return errgo.Mask(err, errgo.Checker("conflict"))
+//
+// The checker is looked up each time the returned function
+// is called, so it may be used before the checker has
+// been registered.
+func Checker(name string) func(error) bool {
+	return func(err error) bool {
+		return Matches(err, name)
+	}
+}
+
+// Matching returns the names of all registered checkers that
+// match err, in alphabetical order. It is intended for
+// use when rendering errors, so that the classifications
+// of an error can be reported alongside its message.
+func Matching(err error) []string {
+	if err == nil {
+		return nil
+	}
+	cause := Cause(err)
+	// The checkers are called without the lock held,
+	// as they may use registered checkers themselves.
+	checkers.mu.RLock()
+	m := make(map[string]func(error) bool, len(checkers.m))
+	for name, check := range checkers.m {
+		m[name] = check
+	}
+	checkers.mu.RUnlock()
+	var names []string
+	for name, check := range m {
+		if check(cause) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,107 @@
+package errgo_test
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/juju/errgo"
+)
+
+var (
+	errConflict = errgo.New("conflict")
+	errQuota    = errgo.New("quota exceeded")
+)
+
+func init() {
+	errgo.RegisterChecker("test-conflict", errgo.Is(errConflict))
+	errgo.RegisterChecker("test-quota", errgo.Is(errQuota))
+	errgo.RegisterChecker("test-any", errgo.Any)
+}
+
+func TestMatches(t *testing.T) {
+	tests := []struct {
+		err      error
+		name     string
+		expect   bool
+		matching []string
+	}{{
+		err:      errConflict,
+		name:     "test-conflict",
+		expect:   true,
+		matching: []string{"test-any", "test-conflict"},
+	}, {
+		err:      errgo.Mask(errQuota, errgo.Is(errQuota)),
+		name:     "test-quota",
+		expect:   true,
+		matching: []string{"test-any", "test-quota"},
+	}, {
+		err:      errgo.Mask(errQuota),
+		name:     "test-quota",
+		expect:   false,
+		matching: []string{"test-any"},
+	}, {
+		err:      errConflict,
+		name:     "test-unregistered",
+		expect:   false,
+		matching: []string{"test-any", "test-conflict"},
+	}, {
+		err:    nil,
+		name:   "test-any",
+		expect: false,
+	}}
+	for i, test := range tests {
+		if ok := errgo.Matches(test.err, test.name); ok != test.expect {
+			t.Errorf("test %d: Matches(%q) got %v want %v", i, test.name, ok, test.expect)
+		}
+		if names := errgo.Matching(test.err); !reflect.DeepEqual(names, test.matching) {
+			t.Errorf("test %d: Matching got %q want %q", i, names, test.matching)
+		}
+	}
+}
+
+func TestCheckerAsPass(t *testing.T) {
+	err := errgo.Mask(errConflict, errgo.Checker("test-conflict"))
+	if cause := errgo.Cause(err); cause != errConflict {
+		t.Fatalf("unexpected cause: got %#v want %#v", cause, errConflict)
+	}
+	err = errgo.Mask(errQuota, errgo.Checker("test-conflict"))
+	if cause := errgo.Cause(err); cause != err {
+		t.Fatalf("cause was not masked; got %#v", cause)
+	}
+}
+
+func TestRegisterCheckerTwice(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("expected panic")
+		}
+	}()
+	errgo.RegisterChecker("test-conflict", errgo.Any)
+}
+
+var (
+	errReentrant  = errgo.New("reentrant")
+	reentrantOnce sync.Once
+)
+
+func init() {
+	errgo.RegisterChecker("test-reentrant", func(err error) bool {
+		// Checkers may register other checkers
+		// and use registered checkers themselves.
+		reentrantOnce.Do(func() {
+			errgo.RegisterChecker("test-lazy", errgo.Is(errReentrant))
+		})
+		return err == errReentrant && !errgo.Matches(err, "test-conflict")
+	})
+}
+
+func TestMatchingReentrant(t *testing.T) {
+	got := errgo.Matching(errgo.Mask(errReentrant, errgo.Any))
+	if len(got) < 2 || got[0] != "test-any" || got[len(got)-1] != "test-reentrant" {
+		t.Fatalf("unexpected checkers %q", got)
+	}
+	if !errgo.Matches(errReentrant, "test-lazy") {
+		t.Fatalf("lazily registered checker does not match")
+	}
+}
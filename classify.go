@@ -0,0 +1,135 @@
+package errgo
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+)
+
+// Kind holds a general classification of an error, as
+// returned by Classify.
+type Kind string
+
+// Kinds of error classified by default.
+const (
+	KindNotFound   Kind = "not-found"
+	KindExists     Kind = "exists"
+	KindPermission Kind = "permission"
+	KindTimeout    Kind = "timeout"
+	KindCanceled   Kind = "canceled"
+)
+
+type classifier struct {
+	kind  Kind
+	check func(error) bool
+}
+
+var classifiers = struct {
+	mu sync.RWMutex
+	c  []classifier
+}{
+	c: []classifier{
+		{KindNotFound, isErr(os.ErrNotExist)},
+		{KindExists, isErr(os.ErrExist)},
+		{KindPermission, isErr(os.ErrPermission)},
+		{KindTimeout, isErr(context.DeadlineExceeded)},
+		{KindTimeout, isTimeout},
+		{KindCanceled, isErr(context.Canceled)},
+	},
+}
+
+// isErr returns a function that reports whether an error
+// is target according to errors.Is, so that errors from the
+// os package and errors wrapped with fmt.Errorf are
+// recognized.
+func isErr(target error) func(error) bool {
+	return func(err error) bool {
+		return errors.Is(err, target)
+	}
+}
+
+// isTimeout reports whether err reports itself as a timeout,
+// as net.Error does.
+func isTimeout(err error) bool {
+	var terr interface{ Timeout() bool }
+	return errors.As(err, &terr) && terr.Timeout()
+}
+
+// RegisterKind registers a function that classifies errors as
+// being of the given kind, for use by Classify. Functions
+// registered later take precedence over those registered
+// earlier, including the default classifications.
+//
+// If check is nil, RegisterKind panics.
+func RegisterKind(kind Kind, check func(error) bool) {
+	if check == nil {
+		panic("errgo: RegisterKind check is nil")
+	}
+	classifiers.mu.Lock()
+	defer classifiers.mu.Unlock()
+	classifiers.c = append(classifiers.c, classifier{kind, check})
+}
+
+// Classify returns the kind of err. If an error of kind type Kind
+// is found in the chain of err (see KindOf), its kind is returned;
+// for example an error created with:
+//
+//	errgo.WithKindf(err, errgo.KindNotFound, "no user %q", name)
+//
+// Otherwise each error in the chain of err, and finally its
+// cause, is tried against the registered classifications (see
+// RegisterKind). By default, errors from the standard library
+// are classified as follows:
+//
+//	os.ErrNotExist             KindNotFound
+//	os.ErrExist                KindExists
+//	os.ErrPermission           KindPermission
+//	context.DeadlineExceeded   KindTimeout
+//	errors with Timeout() true KindTimeout
+//	context.Canceled           KindCanceled
+//
+// If err is still not classified, the kind with the code of err
+// (see CodeOf) in the taxonomy is returned (see SetTaxonomy).
+//
+// Classify returns the empty string if err is nil
+// or is not classified.
+func Classify(err error) Kind {
+	if err == nil {
+		return ""
+	}
+	if kind, ok := KindOf[Kind](err); ok {
+		return kind
+	}
+	if kind := classifyChain(err); kind != "" {
+		return kind
+	}
+	return codeKind(codeOf(err))
+}
+
+// classifyChain returns the kind of err according to
+// the registered classifications.
+func classifyChain(err error) Kind {
+	// The classifications are called without the lock
+	// held, as they may classify errors themselves.
+	classifiers.mu.RLock()
+	c := classifiers.c
+	classifiers.mu.RUnlock()
+	for e := err; e != nil; e = underlying(e) {
+		if kind := classify(c, e); kind != "" {
+			return kind
+		}
+	}
+	return classify(c, Cause(err))
+}
+
+// classify returns the kind of the given error
+// according to the classifications in c.
+func classify(c []classifier, err error) Kind {
+	for i := len(c) - 1; i >= 0; i-- {
+		if c[i].check(err) {
+			return c[i].kind
+		}
+	}
+	return ""
+}
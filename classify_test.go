@@ -0,0 +1,83 @@
+package errgo_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/juju/errgo"
+)
+
+type timeoutErr struct{}
+
+func (timeoutErr) Error() string {
+	return "i/o timeout"
+}
+
+func (timeoutErr) Timeout() bool {
+	return true
+}
+
+var errRateLimited = fmt.Errorf("rate limited")
+
+const kindRateLimited errgo.Kind = "rate-limited"
+
+func init() {
+	errgo.RegisterKind(kindRateLimited, errgo.Is(errRateLimited))
+}
+
+func TestClassify(t *testing.T) {
+	_, openErr := os.Open("/non-existent-file")
+	tests := []struct {
+		about  string
+		err    error
+		expect errgo.Kind
+	}{{
+		about: "nil error",
+	}, {
+		about: "unclassified error",
+		err:   errgo.New("foo"),
+	}, {
+		about:  "os error",
+		err:    openErr,
+		expect: errgo.KindNotFound,
+	}, {
+		about:  "masked os error",
+		err:    errgo.Notef(errgo.Mask(openErr), "cannot read config"),
+		expect: errgo.KindNotFound,
+	}, {
+		about:  "wrapped deadline exceeded",
+		err:    errgo.Mask(fmt.Errorf("waiting: %w", context.DeadlineExceeded)),
+		expect: errgo.KindTimeout,
+	}, {
+		about:  "timeout error",
+		err:    errgo.Mask(timeoutErr{}),
+		expect: errgo.KindTimeout,
+	}, {
+		about:  "canceled",
+		err:    context.Canceled,
+		expect: errgo.KindCanceled,
+	}, {
+		about:  "permission",
+		err:    os.ErrPermission,
+		expect: errgo.KindPermission,
+	}, {
+		about:  "registered kind",
+		err:    errgo.Mask(errRateLimited),
+		expect: kindRateLimited,
+	}, {
+		about:  "explicit kind",
+		err:    errgo.Mask(errgo.WithKindf(openErr, errgo.KindPermission, "foo")),
+		expect: errgo.KindPermission,
+	}, {
+		about:  "cause",
+		err:    errgo.WithCausef(nil, errRateLimited, "foo"),
+		expect: kindRateLimited,
+	}}
+	for i, test := range tests {
+		if got := errgo.Classify(test.err); got != test.expect {
+			t.Errorf("test %d (%s): got %q want %q", i, test.about, got, test.expect)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+// The errgen command generates Go source code declaring typed
+// error kinds wired into errgo from a JSON description of the
+// kinds (see the errgen package for the format), for example:
+//
+//	{
+//		"package": "store",
+//		"kinds": [
+//			{"name": "NotFound", "message": "not found", "code": 1001, "httpStatus": 404, "grpcCode": 5}
+//		]
+//	}
+//
+// It is intended for use with go generate:
+//
+//	//go:generate errgen -o kinds_gen.go kinds.json
+//
+// The tests of the generated code are written alongside it,
+// in a file with the same name ending in _test.go.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/juju/errgo"
+	"github.com/juju/errgo/errgen"
+)
+
+var output = flag.String("o", "errors_gen.go", "name of the generated file")
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: errgen [-o file] spec.json\n")
+		flag.PrintDefaults()
+		os.Exit(2)
+	}
+	flag.Parse()
+	if flag.NArg() != 1 || !strings.HasSuffix(*output, ".go") {
+		flag.Usage()
+	}
+	if err := run(flag.Arg(0), *output); err != nil {
+		fmt.Fprintf(os.Stderr, "errgen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(specFile, output string) error {
+	data, err := os.ReadFile(specFile)
+	if err != nil {
+		return errgo.Mask(err)
+	}
+	var spec errgen.Spec
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return errgo.Notef(err, "cannot parse %s", specFile)
+	}
+	src, testSrc, err := errgen.Generate(spec)
+	if err != nil {
+		return errgo.Mask(err)
+	}
+	if err := os.WriteFile(output, src, 0666); err != nil {
+		return errgo.Mask(err)
+	}
+	testOutput := strings.TrimSuffix(output, ".go") + "_test.go"
+	if err := os.WriteFile(testOutput, testSrc, 0666); err != nil {
+		return errgo.Mask(err)
+	}
+	return nil
+}
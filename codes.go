@@ -0,0 +1,198 @@
+package errgo
+
+import (
+	"strconv"
+	"sync"
+)
+
+type codeEntry struct {
+	code      string
+	check     func(error) bool
+	construct func(msg string) error
+}
+
+var codes = struct {
+	mu      sync.RWMutex
+	entries []codeEntry
+	byCode  map[string]codeEntry
+}{
+	byCode: make(map[string]codeEntry),
+}
+
+// RegisterCode registers an error code, such as "quota-exceeded",
+// that identifies a well-known error across process boundaries.
+// The check function is called by Encode with the cause of an error
+// (see Cause) and should report whether that cause is identified by
+// the code; the construct function is called by Decode to make a
+// new error with the code and the given message. For example:
+//
+//	errgo.RegisterCode("not-found", errgo.Is(ErrNotFound), func(msg string) error {
+//		return errgo.WithCausef(nil, ErrNotFound, "%s", msg)
+//	})
+//
+// If RegisterCode is called twice with the same code or if
+// check or construct is nil, it panics.
+func RegisterCode(code string, check func(error) bool, construct func(msg string) error) {
+	codes.mu.Lock()
+	defer codes.mu.Unlock()
+	if check == nil || construct == nil {
+		panic("errgo: RegisterCode check or construct is nil")
+	}
+	if _, dup := codes.byCode[code]; dup {
+		panic("errgo: RegisterCode called twice for code " + code)
+	}
+	entry := codeEntry{code, check, construct}
+	codes.entries = append(codes.entries, entry)
+	codes.byCode[code] = entry
+}
+
+// Encode returns the code of the first registered code (see
+// RegisterCode) that identifies the cause of err, or the empty
+// string if there is none, and the message of err.
+// It returns two empty strings if err is nil.
+func Encode(err error) (code, msg string) {
+	if err == nil {
+		return "", ""
+	}
+	cause := Cause(err)
+	codes.mu.RLock()
+	entries := codes.entries
+	codes.mu.RUnlock()
+	for _, entry := range entries {
+		if entry.check(cause) {
+			return entry.code, err.Error()
+		}
+	}
+	return "", err.Error()
+}
+
+// Decode returns an error with the given code and message, as
+// returned by Encode. If the code has been registered, the error
+// is made by its construct function; otherwise it is a new error
+// with the given message and no cause, located at the
+// caller of Decode. Decode returns nil if code and msg are both empty.
+func Decode(code, msg string) error {
+	if code == "" && msg == "" {
+		return nil
+	}
+	codes.mu.RLock()
+	entry, ok := codes.byCode[code]
+	codes.mu.RUnlock()
+	if !ok {
+		err := &Err{Message_: msg}
+		err.SetLocation(1)
+		return Created(err)
+	}
+	return entry.construct(msg)
+}
+
+// Code is a stable numeric error code, for use in
+// customer-facing error representations. The zero
+// code means that no code has been assigned.
+type Code int
+
+var codeTexts = struct {
+	mu    sync.RWMutex
+	texts map[Code]string
+}{
+	texts: make(map[Code]string),
+}
+
+// RegisterCodeText registers the canonical description
+// of the given numeric code, as returned by its String
+// method. Registering the same text for a code again
+// does nothing. If RegisterCodeText is called twice with
+// the same code and different texts or with the zero
+// code, it panics.
+func RegisterCodeText(code Code, text string) {
+	codeTexts.mu.Lock()
+	defer codeTexts.mu.Unlock()
+	if code == 0 {
+		panic("errgo: RegisterCodeText called with zero code")
+	}
+	if old, dup := codeTexts.texts[code]; dup && old != text {
+		panic("errgo: RegisterCodeText called twice for code " + strconv.Itoa(int(code)))
+	}
+	codeTexts.texts[code] = text
+}
+
+// CodeText returns the description of the given code
+// registered with RegisterCodeText, and whether there
+// is one.
+func CodeText(code Code) (string, bool) {
+	codeTexts.mu.RLock()
+	defer codeTexts.mu.RUnlock()
+	text, ok := codeTexts.texts[code]
+	return text, ok
+}
+
+// String returns the description of c registered with
+// RegisterCodeText, or "code " followed by the number
+// if there is none.
+func (c Code) String() string {
+	if text, ok := CodeText(c); ok {
+		return text
+	}
+	return "code " + strconv.Itoa(int(c))
+}
+
+// Coder is implemented by errors that are
+// associated with a numeric error code.
+type Coder interface {
+	Code() Code
+}
+
+// codeErr holds an error associated with a numeric code.
+type codeErr struct {
+	Err
+	code Code
+}
+
+// Code implements Coder.
+func (e *codeErr) Code() Code {
+	return e.code
+}
+
+// WithCode returns an error that wraps err and associates it with
+// the given numeric code, as returned by CodeOf. The returned
+// error has the same message and cause as err. If err is
+// nil, WithCode returns nil.
+func WithCode(err error, code Code) error {
+	if err == nil {
+		return nil
+	}
+	newErr := &codeErr{
+		Err: Err{
+			Underlying_: err,
+			Cause_:      Cause(err),
+		},
+		code: code,
+	}
+	newErr.SetLocation(1)
+	return Created(newErr)
+}
+
+// CodeOf returns the code of the outermost error in the chain of
+// err (see Find) that implements Coder and has a non-zero code.
+// If there is none, it returns the code of the kind of err in
+// the taxonomy (see SetTaxonomy), or zero if there is none.
+func CodeOf(err error) Code {
+	if code := codeOf(err); code != 0 {
+		return code
+	}
+	info, _ := kindInfo(err)
+	return info.Code
+}
+
+// codeOf returns the code associated with
+// err by its chain as for CodeOf.
+func codeOf(err error) Code {
+	found := Find(err, func(err error) bool {
+		c, ok := err.(Coder)
+		return ok && c.Code() != 0
+	})
+	if found == nil {
+		return 0
+	}
+	return found.(Coder).Code()
+}
@@ -0,0 +1,139 @@
+package errgo_test
+
+import (
+	"testing"
+
+	"github.com/juju/errgo"
+)
+
+func init() {
+	errgo.RegisterCode("test-quota", errgo.Is(errQuota), func(msg string) error {
+		return errgo.WithCausef(nil, errQuota, "%s", msg)
+	})
+}
+
+func TestEncodeDecode(t *testing.T) {
+	tests := []struct {
+		about      string
+		err        error
+		expectCode string
+		expectMsg  string
+		cause      error
+	}{{
+		about: "nil error",
+	}, {
+		about:      "registered code",
+		err:        errgo.NoteMask(errQuota, "cannot create", errgo.Any),
+		expectCode: "test-quota",
+		expectMsg:  "cannot create: " + errQuota.Error(),
+		cause:      errQuota,
+	}, {
+		about:     "masked cause",
+		err:       errgo.Notef(errQuota, "cannot create"),
+		expectMsg: "cannot create: " + errQuota.Error(),
+	}}
+	for i, test := range tests {
+		code, msg := errgo.Encode(test.err)
+		if code != test.expectCode || msg != test.expectMsg {
+			t.Errorf("test %d (%s): got %q, %q", i, test.about, code, msg)
+			continue
+		}
+		err := errgo.Decode(code, msg)
+		if test.err == nil {
+			if err != nil {
+				t.Errorf("test %d (%s): unexpected error %#v", i, test.about, err)
+			}
+			continue
+		}
+		if err.Error() != test.expectMsg {
+			t.Errorf("test %d (%s): unexpected message %q", i, test.about, err.Error())
+		}
+		cause := test.cause
+		if cause == nil {
+			cause = err
+		}
+		if errgo.Cause(err) != cause {
+			t.Errorf("test %d (%s): unexpected cause %#v", i, test.about, errgo.Cause(err))
+		}
+	}
+	err := errgo.Decode("", "foo")
+	checkErr(t, err, nil, "foo", "[{codes_test.go: foo}]", err)
+}
+
+func TestRegisterCodePanics(t *testing.T) {
+	defer func() {
+		want := "errgo: RegisterCode called twice for code test-quota"
+		if r := recover(); r != want {
+			t.Fatalf("got panic %v want %q", r, want)
+		}
+	}()
+	errgo.RegisterCode("test-quota", errgo.Any, errgo.New)
+}
+
+func TestCode(t *testing.T) {
+	errgo.RegisterCodeText(4001, "quota exceeded")
+	err0 := errgo.WithCausef(nil, errQuota, "foo")
+	err := errgo.WithCode(err0, 4001)
+	checkErr(t, err, err0, "foo", "[{codes_test.go: } {codes_test.go: foo}]", errQuota)
+
+	tests := []struct {
+		about  string
+		err    error
+		expect errgo.Code
+	}{{
+		about: "nil error",
+	}, {
+		about: "no code",
+		err:   err0,
+	}, {
+		about:  "wrapped code",
+		err:    errgo.Notef(err, "bar"),
+		expect: 4001,
+	}, {
+		about:  "outermost code",
+		err:    errgo.WithCode(err, 4002),
+		expect: 4002,
+	}}
+	for _, test := range tests {
+		if got := errgo.CodeOf(test.err); got != test.expect {
+			t.Errorf("%s: got %d want %d", test.about, got, test.expect)
+		}
+	}
+	if s := errgo.Code(4001).String(); s != "quota exceeded" {
+		t.Errorf("unexpected description %q", s)
+	}
+	if s := errgo.Code(4002).String(); s != "code 4002" {
+		t.Errorf("unexpected description %q", s)
+	}
+	if text, ok := errgo.CodeText(4001); !ok || text != "quota exceeded" {
+		t.Errorf("unexpected code text %q, %v", text, ok)
+	}
+	if text, ok := errgo.CodeText(4002); ok {
+		t.Errorf("unexpected code text %q", text)
+	}
+	if errgo.WithCode(nil, 4001) != nil {
+		t.Errorf("expected nil error")
+	}
+}
+
+func TestRegisterCodeTextPanics(t *testing.T) {
+	defer func() {
+		want := "errgo: RegisterCodeText called with zero code"
+		if r := recover(); r != want {
+			t.Fatalf("got panic %v want %q", r, want)
+		}
+	}()
+	errgo.RegisterCodeText(0, "none")
+}
+
+func TestRegisterCodeTextTwice(t *testing.T) {
+	errgo.RegisterCodeText(4996, "duplicate")
+	errgo.RegisterCodeText(4996, "duplicate")
+	defer func() {
+		want := "errgo: RegisterCodeText called twice for code 4996"
+		if r := recover(); r != want {
+			t.Fatalf("got panic %v want %q", r, want)
+		}
+	}()
+	errgo.RegisterCodeText(4996, "other")
+}
@@ -0,0 +1,62 @@
+package errgo
+
+// Collector collects the errors reported by the stages of a
+// pipeline that run concurrently. Errors are sent over a channel
+// to a goroutine that records them, so stages never block
+// on one another while reporting failures.
+type Collector struct {
+	c    chan error
+	done chan struct{}
+	errs []error
+}
+
+// NewCollector returns a new Collector. Result must be called
+// to release its resources.
+func NewCollector() *Collector {
+	c := &Collector{
+		c:    make(chan error, 16),
+		done: make(chan struct{}),
+	}
+	go func() {
+		defer close(c.done)
+		for err := range c.c {
+			c.errs = append(c.errs, err)
+		}
+	}()
+	return c
+}
+
+// Send records err as a failure of the given pipeline stage,
+// annotated with the stage name and the location of the caller
+// of Send. The cause of err is passed through the annotation
+// unchanged. If err is nil, Send does nothing.
+//
+// Send may be called concurrently, but not after Result.
+func (c *Collector) Send(stage string, err error) {
+	if err == nil {
+		return
+	}
+	newErr := &Err{
+		Message_:    stage,
+		Underlying_: err,
+		Cause_:      Cause(err),
+	}
+	newErr.SetLocation(1)
+	c.c <- Created(newErr)
+}
+
+// Result waits for all the errors sent so far to be recorded and
+// returns them, in the order they were received, along with an
+// error wrapping all of them (see MultiErr), or nil if there
+// were none. It must be called exactly once, after all calls
+// to Send have returned.
+func (c *Collector) Result() (error, []error) {
+	close(c.c)
+	<-c.done
+	err := combine(c.errs, "%d pipeline errors", []interface{}{len(c.errs)})
+	if err == nil {
+		return nil, nil
+	}
+	err.SetLocation(1)
+	return Created(err), c.errs
+}
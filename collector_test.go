@@ -0,0 +1,47 @@
+package errgo_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/juju/errgo"
+)
+
+func TestCollector(t *testing.T) {
+	c := errgo.NewCollector()
+	c.Send("ok", nil)
+	c.Send("fetch", errgo.New("boom"))
+	c.Send("store", errNotFound)
+	err, errs := c.Result()
+	checkErr(t, err, nil, "2 pipeline errors: fetch: boom; store: not found",
+		"[{collector_test.go: 2 pipeline errors [{collector_test.go: fetch} {collector_test.go: boom}] [{collector_test.go: store} {not found}]}]", err)
+	if len(errs) != 2 {
+		t.Fatalf("unexpected errors %#v", errs)
+	}
+	if errgo.Cause(errs[1]) != errNotFound {
+		t.Fatalf("unexpected cause %#v", errgo.Cause(errs[1]))
+	}
+}
+
+func TestCollectorConcurrent(t *testing.T) {
+	c := errgo.NewCollector()
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.Send("stage", someErr)
+		}()
+	}
+	wg.Wait()
+	if _, errs := c.Result(); len(errs) != 100 {
+		t.Fatalf("got %d errors want 100", len(errs))
+	}
+}
+
+func TestCollectorNoErrors(t *testing.T) {
+	c := errgo.NewCollector()
+	if err, errs := c.Result(); err != nil || errs != nil {
+		t.Fatalf("unexpected result %#v %#v", err, errs)
+	}
+}
@@ -0,0 +1,73 @@
+package errgo_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/juju/errgo"
+)
+
+// TestConcurrentUse checks that shared errors can be inspected
+// and formatted from many goroutines at once. It is most useful
+// when run with the race detector.
+func TestConcurrentUse(t *testing.T) {
+	errgo.AssignIDs = true
+	err0 := errgo.WithCausef(nil, errNotFound, "foo")
+	err1 := errgo.Combine(errgo.Notef(err0, "bar"), errgo.New("baz"))
+	err := errgo.Tag(errgo.WithCode(errgo.Mask(err1, errgo.Any), 4001), "billing")
+	errgo.AssignIDs = false
+
+	want := errgo.Details(err)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				if got := errgo.Details(err); got != want {
+					t.Errorf("unexpected details %q", got)
+					return
+				}
+				_ = err.Error()
+				errgo.Cause(err)
+				errgo.Causes(err)
+				errgo.ID(err)
+				errgo.CodeOf(err)
+				errgo.HasTag(err, "billing")
+				errgo.CompactDetails(err)
+				errgo.OneLine(err)
+				errgo.Links(err)
+				errgo.Fingerprint(err)
+				errgo.Classify(err)
+				errgo.Diff(err, err0)
+				// Wrapping a shared error must not modify it.
+				errgo.Notef(err, "wrapped")
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestSetLocationAfterCreated(t *testing.T) {
+	err := errgo.New("foo").(*errgo.Err)
+	defer func() {
+		want := "errgo: SetLocation called on error after Created"
+		if r := recover(); r != want {
+			t.Fatalf("got panic %v want %q", r, want)
+		}
+	}()
+	err.SetLocation(0)
+}
+
+func TestValidationErrIsSnapshot(t *testing.T) {
+	var v errgo.Validation
+	v.Addf("name", "is required")
+	err := v.Err()
+	v.Addf("age", "is required")
+	if got := len(err.(errgo.MultiWrapper).UnderlyingErrors()); got != 1 {
+		t.Fatalf("got %d problems want 1", got)
+	}
+	if got := len(v.Err().(errgo.MultiWrapper).UnderlyingErrors()); got != 2 {
+		t.Fatalf("got %d problems want 2", got)
+	}
+}
@@ -0,0 +1,54 @@
+// The datadog package renders errgo errors as the
+// attributes used by Datadog Error Tracking.
+package datadog
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/juju/errgo"
+)
+
+// ToDatadog returns the attributes used by Datadog Error Tracking
+// to describe err, which must not be nil: error.kind holds its kind
+// (see errgo.Classify), or the type of its cause (see errgo.Cause)
+// if it has none, error.message holds its message, escaped as
+// arranged by errgo.EscapeMessages, and error.stack holds a stack
+// trace made from the locations recorded in its chain, in the
+// format produced by the Datadog Go tracer, so that errors created
+// at the same places are grouped together.
+//
+// The stack holds the frames of the trace payload of err (see
+// errgo.ToTracePayload), innermost first. Each frame is shown as
+// the name of the function holding it, or "unknown" if that was
+// not recorded, followed by a line holding its location indented
+// by a tab, for example:
+//
+//	example.com/db.(*Conn).Query
+//		/home/user/src/db/conn.go:99
+//	example.com/user.Get
+//		/home/user/src/user/user.go:20
+func ToDatadog(err error) map[string]string {
+	p := errgo.ToTracePayload(err)
+	kind := string(errgo.Classify(err))
+	if kind == "" {
+		kind = p.Class
+	}
+	var stack strings.Builder
+	for _, frame := range p.Frames {
+		method := frame.Method
+		if method == "" {
+			method = "unknown"
+		}
+		stack.WriteString(method + "\n\t" + frame.File + ":" + strconv.Itoa(frame.Line) + "\n")
+	}
+	msg := err.Error()
+	if errgo.EscapeMessages {
+		msg = errgo.SafeError(err)
+	}
+	return map[string]string{
+		"error.kind":    kind,
+		"error.message": msg,
+		"error.stack":   stack.String(),
+	}
+}
@@ -0,0 +1,77 @@
+package datadog_test
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/juju/errgo"
+	"github.com/juju/errgo/datadog"
+)
+
+// sourceLocations reports whether errors record the source
+// locations of their callers, which they do not in minimal
+// builds or on runtimes with limited support for finding
+// callers (see errgo.RestrictedLocationLabel).
+var sourceLocations = func() bool {
+	loc, ok := errgo.InnermostLocation(errgo.New(""))
+	return ok && loc.File != errgo.RestrictedLocationLabel
+}()
+
+var errNotFound = errors.New("not found")
+
+func inner() error {
+	return errgo.WithCausef(nil, errNotFound, "no rows")
+}
+
+// location returns the location recorded
+// by err itself, as a string.
+func location(err error) string {
+	return err.(errgo.Locationer).Location().String()
+}
+
+func TestToDatadog(t *testing.T) {
+	if kind := datadog.ToDatadog(errgo.Mask(os.ErrNotExist, errgo.Any))["error.kind"]; kind != string(errgo.KindNotFound) {
+		t.Fatalf("unexpected kind %q", kind)
+	}
+	if !sourceLocations {
+		t.Skip("errors do not record source locations")
+	}
+	err0 := inner()
+	err := errgo.Notef(err0, "cannot get user")
+	got := datadog.ToDatadog(err)
+	want := map[string]string{
+		"error.kind":    "*errgo.Err",
+		"error.message": "cannot get user: no rows",
+		"error.stack": "github.com/juju/errgo/datadog_test.inner\n\t" + location(err0) + "\n" +
+			"github.com/juju/errgo/datadog_test.TestToDatadog\n\t" + location(err) + "\n",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected attributes\ngot  %q\nwant %q", got, want)
+	}
+
+	defer errgo.SetFrameDepth(1)
+	errgo.SetFrameDepth(3)
+	err = errgo.Mask(err0, errgo.Any)
+	got = datadog.ToDatadog(err)
+	if kind := got["error.kind"]; kind != "*errors.errorString" {
+		t.Fatalf("unexpected kind %q", kind)
+	}
+	wantStack := "github.com/juju/errgo/datadog_test.inner\n\t" + location(err0) + "\n" +
+		"github.com/juju/errgo/datadog_test.TestToDatadog\n\t" + location(err) + "\n"
+	if stack := got["error.stack"]; len(stack) < len(wantStack) || stack[:len(wantStack)] != wantStack {
+		t.Fatalf("unexpected stack %q", stack)
+	}
+}
+
+func TestToDatadogEscapeMessages(t *testing.T) {
+	defer func() {
+		errgo.EscapeMessages = false
+	}()
+	errgo.EscapeMessages = true
+	err := errgo.Notef(errgo.New("foo"), "cannot parse: line\n2")
+	if got, want := datadog.ToDatadog(err)["error.message"], errgo.SafeError(err); got != want {
+		t.Fatalf("unexpected message %q; want %q", got, want)
+	}
+}
@@ -0,0 +1,92 @@
+package errgo
+
+// The functions in this file are variants of the usual
+// constructors that record the source location callDepth
+// stack frames above their caller, as with Err.SetLocation.
+// With a callDepth of zero they are equivalent to the
+// corresponding functions without the WithDepth suffix.
+// They are intended for use by helper functions that create
+// errors on behalf of their callers; see also SkipPackage.
+
+// NewWithDepth is like New but records the location
+// callDepth frames above its caller.
+func NewWithDepth(callDepth int, s string) error {
+	err := &Err{Message_: s}
+	err.SetLocation(callDepth + 1)
+	return Created(err)
+}
+
+// NewfWithDepth is like Newf but records the location
+// callDepth frames above its caller.
+func NewfWithDepth(callDepth int, f string, a ...interface{}) error {
+	err := &Err{}
+	err.setMessagef(f, a)
+	err.SetLocation(callDepth + 1)
+	return Created(err)
+}
+
+// MaskWithDepth is like Mask but records the location
+// callDepth frames above its caller.
+func MaskWithDepth(callDepth int, underlying error, pass ...func(error) bool) error {
+	if underlying == nil {
+		return nil
+	}
+	err := noteMask(underlying, "", pass...)
+	err.SetLocation(callDepth + 1)
+	return Created(err)
+}
+
+// NotefWithDepth is like Notef but records the location
+// callDepth frames above its caller.
+func NotefWithDepth(callDepth int, underlying error, f string, a ...interface{}) error {
+	err := noteMask(underlying, "")
+	err.setMessagef(f, a)
+	err.SetLocation(callDepth + 1)
+	return Created(err)
+}
+
+// WithCausefWithDepth is like WithCausef but records the
+// location callDepth frames above its caller.
+func WithCausefWithDepth(callDepth int, underlying, cause error, f string, a ...interface{}) error {
+	err := &Err{
+		Underlying_: underlying,
+		Cause_:      cause,
+	}
+	err.setMessagef(f, a)
+	err.SetLocation(callDepth + 1)
+	return Created(err)
+}
+
+// WithCodeWithDepth is like WithCode but records the
+// location callDepth frames above its caller.
+func WithCodeWithDepth(callDepth int, err error, code Code) error {
+	if err == nil {
+		return nil
+	}
+	newErr := &codeErr{
+		Err: Err{
+			Underlying_: err,
+			Cause_:      Cause(err),
+		},
+		code: code,
+	}
+	newErr.SetLocation(callDepth + 1)
+	return Created(newErr)
+}
+
+// WithHTTPStatusWithDepth is like WithHTTPStatus but records
+// the location callDepth frames above its caller.
+func WithHTTPStatusWithDepth(callDepth int, err error, status int) error {
+	if err == nil {
+		return nil
+	}
+	newErr := &statusErr{
+		Err: Err{
+			Underlying_: err,
+			Cause_:      Cause(err),
+		},
+		status: status,
+	}
+	newErr.SetLocation(callDepth + 1)
+	return Created(newErr)
+}
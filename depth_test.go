@@ -0,0 +1,73 @@
+package errgo_test
+
+import (
+	"testing"
+
+	"github.com/juju/errgo"
+)
+
+func depthHelper(f func(callDepth int) error) error {
+	return f(1)
+}
+
+func TestWithDepth(t *testing.T) {
+	tests := []struct {
+		about string
+		f     func(callDepth int) error
+	}{{
+		about: "NewWithDepth",
+		f: func(callDepth int) error {
+			return errgo.NewWithDepth(callDepth+1, "foo")
+		},
+	}, {
+		about: "NewfWithDepth",
+		f: func(callDepth int) error {
+			return errgo.NewfWithDepth(callDepth+1, "foo %d", 1)
+		},
+	}, {
+		about: "MaskWithDepth",
+		f: func(callDepth int) error {
+			return errgo.MaskWithDepth(callDepth+1, someErr)
+		},
+	}, {
+		about: "NotefWithDepth",
+		f: func(callDepth int) error {
+			return errgo.NotefWithDepth(callDepth+1, someErr, "foo")
+		},
+	}, {
+		about: "WithCausefWithDepth",
+		f: func(callDepth int) error {
+			return errgo.WithCausefWithDepth(callDepth+1, nil, someErr, "foo")
+		},
+	}, {
+		about: "WithCodeWithDepth",
+		f: func(callDepth int) error {
+			return errgo.WithCodeWithDepth(callDepth+1, someErr, 1001)
+		},
+	}, {
+		about: "WithHTTPStatusWithDepth",
+		f: func(callDepth int) error {
+			return errgo.WithHTTPStatusWithDepth(callDepth+1, someErr, 404)
+		},
+	}}
+	for _, test := range tests {
+		err := depthHelper(test.f)
+		if !sourceLocations {
+			continue
+		}
+		// The location is that of the call to depthHelper.
+		fn := err.(errgo.Functioner).Function()
+		if want := "github.com/juju/errgo_test.TestWithDepth"; fn != want {
+			t.Errorf("%s: got function %q want %q", test.about, fn, want)
+		}
+	}
+	if err := errgo.MaskWithDepth(0, nil); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+	if err := errgo.WithCodeWithDepth(0, nil, 1001); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+	if err := errgo.WithHTTPStatusWithDepth(0, nil, 404); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+}
@@ -0,0 +1,44 @@
+package errgo
+
+// docURLErr holds an error along with the URL
+// of a page documenting it.
+type docURLErr struct {
+	Err
+	url string
+}
+
+// WithDocURL returns an error that wraps err and associates it
+// with the URL of a page documenting it, such as a help page that
+// explains how to resolve it. The URL is included by Exit and
+// in the problem documents of the httperr package. The returned
+// error has the same message and cause as err. If err is nil, WithDocURL returns nil.
+func WithDocURL(err error, url string) error {
+	if err == nil {
+		return nil
+	}
+	newErr := &docURLErr{
+		Err: Err{
+			Underlying_: err,
+			Cause_:      Cause(err),
+		},
+		url: url,
+	}
+	newErr.SetLocation(1)
+	return Created(newErr)
+}
+
+// DocURL returns the documentation URL associated with err
+// by the outermost call to WithDocURL in its chain, or else
+// the documentation URL of the kind of err in the taxonomy
+// (see SetTaxonomy), or the empty string if there is none.
+func DocURL(err error) string {
+	found := Find(err, func(err error) bool {
+		_, ok := err.(*docURLErr)
+		return ok
+	})
+	if found != nil {
+		return found.(*docURLErr).url
+	}
+	info, _ := kindInfo(err)
+	return info.DocURL
+}
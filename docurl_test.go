@@ -0,0 +1,36 @@
+package errgo_test
+
+import (
+	"testing"
+
+	"github.com/juju/errgo"
+)
+
+func TestWithDocURL(t *testing.T) {
+	err0 := errgo.WithCausef(nil, errNotFound, "foo")
+	err := errgo.WithDocURL(err0, "https://docs.example.com/errors/E1")
+	checkErr(t, err, err0, "foo", "[{docurl_test.go: } {docurl_test.go: foo}]", errNotFound)
+
+	tests := []struct {
+		err    error
+		expect string
+	}{{
+		err: nil,
+	}, {
+		err: err0,
+	}, {
+		err:    errgo.Notef(err, "bar"),
+		expect: "https://docs.example.com/errors/E1",
+	}, {
+		err:    errgo.WithDocURL(errgo.Mask(err), "https://docs.example.com/errors/E2"),
+		expect: "https://docs.example.com/errors/E2",
+	}}
+	for i, test := range tests {
+		if got := errgo.DocURL(test.err); got != test.expect {
+			t.Errorf("test %d: got %q want %q", i, got, test.expect)
+		}
+	}
+	if err := errgo.WithDocURL(nil, "https://docs.example.com"); err != nil {
+		t.Fatalf("unexpected error %#v", err)
+	}
+}
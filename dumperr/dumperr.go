@@ -0,0 +1,124 @@
+// The dumperr package writes diagnostic reports
+// describing errgo errors, suitable for crash handlers.
+package dumperr
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/juju/errgo"
+)
+
+// Options holds options for Dump.
+type Options struct {
+	// Details holds the options used to format the
+	// details of the error (see errgo.FormatDetails).
+	Details errgo.DetailsOptions
+
+	// Goroutines causes the stacks of all
+	// goroutines to be included in the dump.
+	Goroutines bool
+}
+
+// Dump writes a diagnostic report describing err to w. The report
+// is divided into sections, each starting with a line naming it:
+//
+//   - "error:" holds the error message.
+//   - "details:" holds the details of the error (see errgo.FormatDetails).
+//   - "chain:" holds a line for each error in the chain in the order
+//     they are visited by errgo.Find, giving its type, location and
+//     message and the type and message of any cause it records.
+//   - "fields:" holds the structured metadata of the error: its
+//     severity, kind, code, identifier, trace identifier, creation
+//     time, fingerprint and profiler labels (see errgo.ProfileLabels),
+//     when set.
+//   - "build:" holds the build information of the running
+//     binary (see debug.ReadBuildInfo), when available.
+//   - "goroutines:" holds the stacks of all goroutines,
+//     when requested by opts.
+//
+// If err is nil, only the error section is written,
+// holding "<nil>".
+func Dump(w io.Writer, err error, opts Options) error {
+	var b strings.Builder
+	if err == nil {
+		b.WriteString("error:\n<nil>\n")
+	} else {
+		writeDump(&b, err, opts)
+	}
+	if _, werr := io.WriteString(w, b.String()); werr != nil {
+		return errgo.Notef(werr, "cannot write dump")
+	}
+	return nil
+}
+
+// writeDump writes the sections of the report
+// written by Dump for err, which must not be nil.
+func writeDump(b *strings.Builder, err error, opts Options) {
+	b.WriteString("error:\n" + err.Error() + "\n")
+	b.WriteString("\ndetails:\n" + errgo.FormatDetails(err, opts.Details) + "\n")
+	b.WriteString("\nchain:\n")
+	for i, link := range errgo.Links(err) {
+		fmt.Fprintf(b, "%d: %T", i, link)
+		if link, ok := link.(errgo.Locationer); ok && link.Location().IsSet() {
+			b.WriteString(" " + link.Location().String())
+		}
+		fmt.Fprintf(b, ": %q", errgo.Message(link))
+		if link, ok := link.(errgo.Causer); ok && link.Cause() != nil {
+			fmt.Fprintf(b, " (cause %T: %q)", link.Cause(), link.Cause().Error())
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("\nfields:\n")
+	fmt.Fprintf(b, "severity: %v\n", errgo.SeverityOf(err))
+	if kind := errgo.Classify(err); kind != "" {
+		fmt.Fprintf(b, "kind: %s\n", kind)
+	}
+	if code := errgo.CodeOf(err); code != 0 {
+		fmt.Fprintf(b, "code: %d (%v)\n", int(code), code)
+	}
+	if id := errgo.ID(err); id != "" {
+		fmt.Fprintf(b, "id: %s\n", id)
+	}
+	if tc, ok := errgo.TraceContextOf(err); ok {
+		fmt.Fprintf(b, "trace_id: %s\n", tc.TraceID)
+	}
+	if err, ok := err.(errgo.Timestamper); ok && !err.Time().IsZero() {
+		fmt.Fprintf(b, "time: %s\n", err.Time().Format(time.RFC3339Nano))
+	}
+	fmt.Fprintf(b, "fingerprint: %s\n", errgo.Fingerprint(err))
+	labels := errgo.ProfileLabels(err)
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(b, "label %s: %s\n", key, labels[key])
+	}
+	if info, ok := debug.ReadBuildInfo(); ok {
+		b.WriteString("\nbuild:\n" + info.String())
+	}
+	if opts.Goroutines {
+		b.WriteString("\ngoroutines:\n")
+		b.Write(goroutineStacks())
+	}
+}
+
+// goroutineStacks returns the stacks of
+// all goroutines, as formatted by runtime.Stack.
+func goroutineStacks() []byte {
+	buf := make([]byte, 64*1024)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			return buf[:n]
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+}
@@ -0,0 +1,102 @@
+package dumperr_test
+
+import (
+	"context"
+	"errors"
+	"runtime/pprof"
+	"strings"
+	"testing"
+
+	"github.com/juju/errgo"
+	"github.com/juju/errgo/dumperr"
+	"github.com/juju/errgo/pproferr"
+)
+
+var errNotFound = errors.New("not found")
+
+type failWriter struct{}
+
+func (failWriter) Write([]byte) (int, error) {
+	return 0, errors.New("write failure")
+}
+
+func TestDump(t *testing.T) {
+	var b strings.Builder
+	if err := dumperr.Dump(&b, nil, dumperr.Options{}); err != nil {
+		t.Fatal(err)
+	}
+	if got := b.String(); got != "error:\n<nil>\n" {
+		t.Fatalf("unexpected dump for nil error %q", got)
+	}
+
+	err0 := &errgo.Err{
+		Message_:  "foo",
+		Cause_:    errNotFound,
+		Location_: errgo.Location{File: "a.go", Line: 10},
+	}
+	err1 := &errgo.Err{
+		Message_:    "bar",
+		Underlying_: err0,
+		Cause_:      err0,
+		Location_:   errgo.Location{File: "b.go", Line: 20},
+	}
+	err := errgo.WithCode(err1, 4999)
+	loc := ""
+	if loc1, ok := errgo.OutermostLocation(err); ok && loc1.File != "b.go" {
+		loc = " " + loc1.String()
+	}
+	b.Reset()
+	if derr := dumperr.Dump(&b, err, dumperr.Options{}); derr != nil {
+		t.Fatal(derr)
+	}
+	want := `error:
+bar: foo
+
+details:
+` + errgo.Details(err) + `
+
+chain:
+0: *errgo.codeErr` + loc + `: "" (cause *errgo.Err: "foo")
+1: *errgo.Err b.go:20: "bar" (cause *errgo.Err: "foo")
+2: *errgo.Err a.go:10: "foo" (cause *errors.errorString: "not found")
+
+fields:
+severity: error
+code: 4999 (code 4999)
+fingerprint: ` + errgo.Fingerprint(err) + "\n"
+	got := b.String()
+	if i := strings.Index(got, "\nbuild:\n"); i >= 0 {
+		got = got[:i]
+	}
+	if got != want {
+		t.Fatalf("unexpected dump; got\n%s\nwant\n%s", got, want)
+	}
+	if strings.Contains(b.String(), "goroutines:") {
+		t.Fatalf("unexpected goroutines in dump")
+	}
+
+	b.Reset()
+	if derr := dumperr.Dump(&b, err, dumperr.Options{Goroutines: true}); derr != nil {
+		t.Fatal(derr)
+	}
+	if got := b.String(); !strings.Contains(got, "\ngoroutines:\ngoroutine ") || !strings.Contains(got, "TestDump") {
+		t.Fatalf("goroutine stacks missing from dump %q", got)
+	}
+
+	derr := dumperr.Dump(failWriter{}, err, dumperr.Options{})
+	if derr == nil || derr.Error() != "cannot write dump: write failure" {
+		t.Fatalf("unexpected error %v", derr)
+	}
+}
+
+func TestDumpLabels(t *testing.T) {
+	ctx := pprof.WithLabels(context.Background(), pprof.Labels("request_id", "r42", "handler", "users"))
+	err := pproferr.WithLabels(ctx, errNotFound)
+	var b strings.Builder
+	if derr := dumperr.Dump(&b, err, dumperr.Options{}); derr != nil {
+		t.Fatal(derr)
+	}
+	if got := b.String(); !strings.Contains(got, "label handler: users\nlabel request_id: r42\n") {
+		t.Fatalf("labels missing from dump %q", got)
+	}
+}
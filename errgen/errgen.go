@@ -0,0 +1,280 @@
+// The errgen package generates Go source code declaring typed error
+// kinds wired into errgo, so that services need not maintain the
+// constructors, predicates and protocol mappings for each kind of
+// error by hand. See the errgen command for a way to use it
+// with go generate.
+package errgen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"text/template"
+
+	"github.com/juju/errgo"
+)
+
+// Spec describes the error kinds to generate.
+type Spec struct {
+	// Package holds the name of the package holding
+	// the generated code.
+	Package string `json:"package"`
+
+	// Kinds holds the error kinds to generate.
+	Kinds []Kind `json:"kinds"`
+}
+
+// Kind describes a single error kind.
+type Kind struct {
+	// Name holds the exported Go name of the kind, for example
+	// "NotFound". It is used to name the generated error
+	// variable (ErrNotFound), constructor (NotFoundf)
+	// and predicate (IsNotFound).
+	Name string `json:"name"`
+
+	// Message holds the message of the error variable,
+	// for example "not found".
+	Message string `json:"message"`
+
+	// Code holds the numeric error code of the kind (see
+	// errgo.CodeOf). If it is non-zero, Message is registered
+	// as its description unless there is one already.
+	Code int `json:"code,omitempty"`
+
+	// HTTPStatus holds the HTTP status code used for errors
+	// of the kind (see errgo.HTTPStatus). If it is zero,
+	// http.StatusInternalServerError is used.
+	HTTPStatus int `json:"httpStatus,omitempty"`
+
+	// GRPCCode holds the numeric gRPC status code used
+	// for errors of the kind, as defined by the
+	// google.golang.org/grpc/codes package. If it
+	// is zero, codes.Unknown is used.
+	GRPCCode int `json:"grpcCode,omitempty"`
+}
+
+// Generate returns Go source code declaring the error kinds described
+// by spec and source code for tests of the generated code, to be placed
+// in a file in the same package. For each kind, the source declares an
+// error variable that is the cause of all errors of that kind, a
+// constructor, a predicate, and entries in the mappings returned by
+// the generated Code, HTTPStatus and GRPCCode functions. The errors
+// returned by the constructor also carry the code and HTTP status
+// of the kind, as reported by errgo.CodeOf and errgo.HTTPStatus.
+func Generate(spec Spec) (src, testSrc []byte, err error) {
+	if err := validate(spec); err != nil {
+		return nil, nil, errgo.Mask(err)
+	}
+	src, err = execute(srcTemplate, spec)
+	if err != nil {
+		return nil, nil, errgo.Notef(err, "cannot generate code")
+	}
+	testSrc, err = execute(testTemplate, spec)
+	if err != nil {
+		return nil, nil, errgo.Notef(err, "cannot generate tests")
+	}
+	return src, testSrc, nil
+}
+
+// validate checks that spec can be used to generate valid code.
+func validate(spec Spec) error {
+	if !token.IsIdentifier(spec.Package) {
+		return errgo.Newf("invalid package name %q", spec.Package)
+	}
+	names := make(map[string]bool)
+	codes := make(map[int]bool)
+	for _, k := range spec.Kinds {
+		if !token.IsIdentifier(k.Name) || !token.IsExported(k.Name) {
+			return errgo.Newf("invalid kind name %q", k.Name)
+		}
+		if names[k.Name] {
+			return errgo.Newf("duplicate kind name %q", k.Name)
+		}
+		names[k.Name] = true
+		if k.Code != 0 {
+			if codes[k.Code] {
+				return errgo.Newf("duplicate code %d for kind %q", k.Code, k.Name)
+			}
+			codes[k.Code] = true
+		}
+	}
+	return nil
+}
+
+func execute(t *template.Template, spec Spec) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, spec); err != nil {
+		return nil, errgo.Mask(err)
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, errgo.Notef(err, "cannot format generated code")
+	}
+	return src, nil
+}
+
+var funcs = template.FuncMap{
+	"quote": func(s string) string {
+		return fmt.Sprintf("%q", s)
+	},
+}
+
+var srcTemplate = template.Must(template.New("src").Funcs(funcs).Parse(`// Code generated by errgen. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"net/http"
+
+	"github.com/juju/errgo"
+)
+
+var (
+{{- range $i, $k := .Kinds}}{{if $i}}
+{{end}}
+	// Err{{.Name}} is the cause of errors of kind {{.Name}}.
+	Err{{.Name}} = errgo.New({{quote .Message}})
+{{- end}}
+)
+{{range .Kinds}}
+// {{.Name}}f returns an error of kind {{.Name}} with the
+// given formatted message, located at its caller.
+func {{.Name}}f(format string, args ...interface{}) error {
+	err := errgo.WithCausefWithDepth(1, nil, Err{{.Name}}, format, args...)
+{{- if .Code}}
+	err = errgo.WithCodeWithDepth(1, err, {{.Code}})
+{{- end}}
+{{- if .HTTPStatus}}
+	err = errgo.WithHTTPStatusWithDepth(1, err, {{.HTTPStatus}})
+{{- end}}
+	return err
+}
+
+// Is{{.Name}} reports whether the cause of err is Err{{.Name}}.
+func Is{{.Name}}(err error) bool {
+	return errgo.Cause(err) == Err{{.Name}}
+}
+{{end}}
+func init() {
+{{- range .Kinds}}{{if .Code}}
+	if _, ok := errgo.CodeText({{.Code}}); !ok {
+		errgo.RegisterCodeText({{.Code}}, {{quote .Message}})
+	}
+{{- end}}{{end}}
+}
+
+// Code returns the numeric code of the kind of err,
+// or zero if it has none.
+func Code(err error) errgo.Code {
+	switch errgo.Cause(err) {
+{{- range .Kinds}}{{if .Code}}
+	case Err{{.Name}}:
+		return {{.Code}}
+{{- end}}{{end}}
+	}
+	return 0
+}
+
+// HTTPStatus returns the HTTP status code
+// corresponding to the kind of err.
+func HTTPStatus(err error) int {
+	switch errgo.Cause(err) {
+{{- range .Kinds}}{{if .HTTPStatus}}
+	case Err{{.Name}}:
+		return {{.HTTPStatus}}
+{{- end}}{{end}}
+	}
+	return http.StatusInternalServerError
+}
+
+// GRPCCode returns the numeric gRPC status code
+// corresponding to the kind of err.
+func GRPCCode(err error) uint32 {
+	switch errgo.Cause(err) {
+{{- range .Kinds}}{{if .GRPCCode}}
+	case Err{{.Name}}:
+		return {{.GRPCCode}}
+{{- end}}{{end}}
+	}
+	return 2 // codes.Unknown
+}
+`))
+
+var testTemplate = template.Must(template.New("test").Funcs(funcs).Parse(`// Code generated by errgen. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/juju/errgo"
+)
+
+func TestGeneratedKinds(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		is         func(error) bool
+		code       errgo.Code
+		httpStatus int
+		grpcCode   uint32
+	}{
+{{- range .Kinds}}
+		{
+			name:       {{quote .Name}},
+			err:        errgo.Mask({{.Name}}f("foo"), errgo.Any),
+			is:         Is{{.Name}},
+			code:       {{.Code}},
+			httpStatus: {{if .HTTPStatus}}{{.HTTPStatus}}{{else}}http.StatusInternalServerError{{end}},
+			grpcCode:   {{if .GRPCCode}}{{.GRPCCode}}{{else}}2{{end}},
+		},
+{{- end}}
+	}
+	for _, test := range tests {
+		if !test.is(test.err) {
+			t.Errorf("%s: predicate does not match", test.name)
+		}
+		if test.err.Error() != "foo" {
+			t.Errorf("%s: unexpected message %q", test.name, test.err.Error())
+		}
+		if got := Code(test.err); got != test.code {
+			t.Errorf("%s: got code %d want %d", test.name, got, test.code)
+		}
+		if got := HTTPStatus(test.err); got != test.httpStatus {
+			t.Errorf("%s: got HTTP status %d want %d", test.name, got, test.httpStatus)
+		}
+		if got := GRPCCode(test.err); got != test.grpcCode {
+			t.Errorf("%s: got gRPC code %d want %d", test.name, got, test.grpcCode)
+		}
+		if got := errgo.CodeOf(test.err); got != test.code {
+			t.Errorf("%s: got errgo code %d want %d", test.name, got, test.code)
+		}
+		if got := errgo.HTTPStatus(test.err); got != test.httpStatus {
+			t.Errorf("%s: got errgo HTTP status %d want %d", test.name, got, test.httpStatus)
+		}
+		for _, link := range errgo.Links(test.err) {
+			lerr, ok := link.(errgo.Locationer)
+			if !ok {
+				continue
+			}
+			// All the errors in the chain are located
+			// at the caller of the constructor.
+			if loc := lerr.Location(); loc.IsSet() && loc.File != errgo.RestrictedLocationLabel && !strings.HasSuffix(loc.File, "_test.go") {
+				t.Errorf("%s: unexpected location %v", test.name, loc)
+			}
+		}
+		for _, other := range tests {
+			if other.name != test.name && other.is(test.err) {
+				t.Errorf("%s: predicate for %s matches", test.name, other.name)
+			}
+		}
+	}
+	other := errgo.New("other")
+	if Code(other) != 0 || HTTPStatus(other) != http.StatusInternalServerError || GRPCCode(other) != 2 {
+		t.Errorf("unexpected mapping for unknown error")
+	}
+}
+`))
@@ -0,0 +1,80 @@
+package errgen_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/juju/errgo/errgen"
+)
+
+// TestGenerateExample checks that the code in the example
+// package is up to date. Its generated tests are run along
+// with those of the example package.
+func TestGenerateExample(t *testing.T) {
+	dir := filepath.Join("internal", "example")
+	data, err := os.ReadFile(filepath.Join(dir, "kinds.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var spec errgen.Spec
+	if err := json.Unmarshal(data, &spec); err != nil {
+		t.Fatal(err)
+	}
+	src, testSrc, err := errgen.Generate(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for file, want := range map[string][]byte{
+		"kinds_gen.go":      src,
+		"kinds_gen_test.go": testSrc,
+	} {
+		got, err := os.ReadFile(filepath.Join(dir, file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != string(want) {
+			t.Errorf("%s is out of date; run go generate", file)
+		}
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	tests := []struct {
+		about       string
+		spec        errgen.Spec
+		expectError string
+	}{{
+		about:       "invalid package",
+		spec:        errgen.Spec{Package: "a-b"},
+		expectError: `invalid package name "a-b"`,
+	}, {
+		about: "unexported name",
+		spec: errgen.Spec{
+			Package: "a",
+			Kinds:   []errgen.Kind{{Name: "notFound"}},
+		},
+		expectError: `invalid kind name "notFound"`,
+	}, {
+		about: "duplicate name",
+		spec: errgen.Spec{
+			Package: "a",
+			Kinds:   []errgen.Kind{{Name: "NotFound"}, {Name: "NotFound"}},
+		},
+		expectError: `duplicate kind name "NotFound"`,
+	}, {
+		about: "duplicate code",
+		spec: errgen.Spec{
+			Package: "a",
+			Kinds:   []errgen.Kind{{Name: "NotFound", Code: 1}, {Name: "Gone", Code: 1}},
+		},
+		expectError: `duplicate code 1 for kind "Gone"`,
+	}}
+	for _, test := range tests {
+		_, _, err := errgen.Generate(test.spec)
+		if err == nil || err.Error() != test.expectError {
+			t.Errorf("%s: got error %v want %q", test.about, err, test.expectError)
+		}
+	}
+}
@@ -0,0 +1,5 @@
+// The example package holds code generated by errgen,
+// used to test the generator.
+package example
+
+//go:generate go run github.com/juju/errgo/cmd/errgen -o kinds_gen.go kinds.json
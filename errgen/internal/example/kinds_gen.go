@@ -0,0 +1,107 @@
+// Code generated by errgen. DO NOT EDIT.
+
+package example
+
+import (
+	"net/http"
+
+	"github.com/juju/errgo"
+)
+
+var (
+	// ErrNotFound is the cause of errors of kind NotFound.
+	ErrNotFound = errgo.New("not found")
+
+	// ErrConflict is the cause of errors of kind Conflict.
+	ErrConflict = errgo.New("conflict")
+
+	// ErrUnavailable is the cause of errors of kind Unavailable.
+	ErrUnavailable = errgo.New("unavailable")
+)
+
+// NotFoundf returns an error of kind NotFound with the
+// given formatted message, located at its caller.
+func NotFoundf(format string, args ...interface{}) error {
+	err := errgo.WithCausefWithDepth(1, nil, ErrNotFound, format, args...)
+	err = errgo.WithCodeWithDepth(1, err, 1001)
+	err = errgo.WithHTTPStatusWithDepth(1, err, 404)
+	return err
+}
+
+// IsNotFound reports whether the cause of err is ErrNotFound.
+func IsNotFound(err error) bool {
+	return errgo.Cause(err) == ErrNotFound
+}
+
+// Conflictf returns an error of kind Conflict with the
+// given formatted message, located at its caller.
+func Conflictf(format string, args ...interface{}) error {
+	err := errgo.WithCausefWithDepth(1, nil, ErrConflict, format, args...)
+	err = errgo.WithCodeWithDepth(1, err, 1002)
+	err = errgo.WithHTTPStatusWithDepth(1, err, 409)
+	return err
+}
+
+// IsConflict reports whether the cause of err is ErrConflict.
+func IsConflict(err error) bool {
+	return errgo.Cause(err) == ErrConflict
+}
+
+// Unavailablef returns an error of kind Unavailable with the
+// given formatted message, located at its caller.
+func Unavailablef(format string, args ...interface{}) error {
+	err := errgo.WithCausefWithDepth(1, nil, ErrUnavailable, format, args...)
+	return err
+}
+
+// IsUnavailable reports whether the cause of err is ErrUnavailable.
+func IsUnavailable(err error) bool {
+	return errgo.Cause(err) == ErrUnavailable
+}
+
+func init() {
+	if _, ok := errgo.CodeText(1001); !ok {
+		errgo.RegisterCodeText(1001, "not found")
+	}
+	if _, ok := errgo.CodeText(1002); !ok {
+		errgo.RegisterCodeText(1002, "conflict")
+	}
+}
+
+// Code returns the numeric code of the kind of err,
+// or zero if it has none.
+func Code(err error) errgo.Code {
+	switch errgo.Cause(err) {
+	case ErrNotFound:
+		return 1001
+	case ErrConflict:
+		return 1002
+	}
+	return 0
+}
+
+// HTTPStatus returns the HTTP status code
+// corresponding to the kind of err.
+func HTTPStatus(err error) int {
+	switch errgo.Cause(err) {
+	case ErrNotFound:
+		return 404
+	case ErrConflict:
+		return 409
+	}
+	return http.StatusInternalServerError
+}
+
+// GRPCCode returns the numeric gRPC status code
+// corresponding to the kind of err.
+func GRPCCode(err error) uint32 {
+	switch errgo.Cause(err) {
+	case ErrNotFound:
+		return 5
+	case ErrConflict:
+		return 6
+	case ErrUnavailable:
+		return 14
+	}
+	return 2 // codes.Unknown
+}
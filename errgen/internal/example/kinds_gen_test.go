@@ -0,0 +1,90 @@
+// Code generated by errgen. DO NOT EDIT.
+
+package example
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/juju/errgo"
+)
+
+func TestGeneratedKinds(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		is         func(error) bool
+		code       errgo.Code
+		httpStatus int
+		grpcCode   uint32
+	}{
+		{
+			name:       "NotFound",
+			err:        errgo.Mask(NotFoundf("foo"), errgo.Any),
+			is:         IsNotFound,
+			code:       1001,
+			httpStatus: 404,
+			grpcCode:   5,
+		},
+		{
+			name:       "Conflict",
+			err:        errgo.Mask(Conflictf("foo"), errgo.Any),
+			is:         IsConflict,
+			code:       1002,
+			httpStatus: 409,
+			grpcCode:   6,
+		},
+		{
+			name:       "Unavailable",
+			err:        errgo.Mask(Unavailablef("foo"), errgo.Any),
+			is:         IsUnavailable,
+			code:       0,
+			httpStatus: http.StatusInternalServerError,
+			grpcCode:   14,
+		},
+	}
+	for _, test := range tests {
+		if !test.is(test.err) {
+			t.Errorf("%s: predicate does not match", test.name)
+		}
+		if test.err.Error() != "foo" {
+			t.Errorf("%s: unexpected message %q", test.name, test.err.Error())
+		}
+		if got := Code(test.err); got != test.code {
+			t.Errorf("%s: got code %d want %d", test.name, got, test.code)
+		}
+		if got := HTTPStatus(test.err); got != test.httpStatus {
+			t.Errorf("%s: got HTTP status %d want %d", test.name, got, test.httpStatus)
+		}
+		if got := GRPCCode(test.err); got != test.grpcCode {
+			t.Errorf("%s: got gRPC code %d want %d", test.name, got, test.grpcCode)
+		}
+		if got := errgo.CodeOf(test.err); got != test.code {
+			t.Errorf("%s: got errgo code %d want %d", test.name, got, test.code)
+		}
+		if got := errgo.HTTPStatus(test.err); got != test.httpStatus {
+			t.Errorf("%s: got errgo HTTP status %d want %d", test.name, got, test.httpStatus)
+		}
+		for _, link := range errgo.Links(test.err) {
+			lerr, ok := link.(errgo.Locationer)
+			if !ok {
+				continue
+			}
+			// All the errors in the chain are located
+			// at the caller of the constructor.
+			if loc := lerr.Location(); loc.IsSet() && loc.File != errgo.RestrictedLocationLabel && !strings.HasSuffix(loc.File, "_test.go") {
+				t.Errorf("%s: unexpected location %v", test.name, loc)
+			}
+		}
+		for _, other := range tests {
+			if other.name != test.name && other.is(test.err) {
+				t.Errorf("%s: predicate for %s matches", test.name, other.name)
+			}
+		}
+	}
+	other := errgo.New("other")
+	if Code(other) != 0 || HTTPStatus(other) != http.StatusInternalServerError || GRPCCode(other) != 2 {
+		t.Errorf("unexpected mapping for unknown error")
+	}
+}
@@ -0,0 +1,31 @@
+package errgotest
+
+import (
+	"testing"
+
+	"github.com/juju/errgo"
+)
+
+// AssertNoError fails the test immediately if err is non-nil.
+// Unlike a plain check for nil, the failure message includes
+// the details of err (see errgo.Details), so that the source
+// locations recorded in the error chain are not lost.
+func AssertNoError(t testing.TB, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("unexpected error: %v\ndetails: %s", err, errgo.Details(err))
+	}
+}
+
+// AssertCause fails the test immediately unless the cause of
+// err (see errgo.Cause) is want. The failure message includes
+// the details of err.
+func AssertCause(t testing.TB, err, want error) {
+	t.Helper()
+	if err == nil {
+		t.Fatalf("unexpected nil error; want cause %v", want)
+	}
+	if cause := errgo.Cause(err); cause != want {
+		t.Fatalf("unexpected cause: got %v; want %v\ndetails: %s", cause, want, errgo.Details(err))
+	}
+}
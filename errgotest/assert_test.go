@@ -0,0 +1,87 @@
+package errgotest_test
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/juju/errgo"
+	"github.com/juju/errgo/errgotest"
+)
+
+var errAssert = errgo.New("assert error")
+
+// recordingT records calls to Helper and Fatalf without
+// stopping the test.
+type recordingT struct {
+	testing.TB
+	helper bool
+	failed string
+}
+
+func (t *recordingT) Helper() {
+	t.helper = true
+}
+
+func (t *recordingT) Fatalf(f string, a ...interface{}) {
+	t.failed = fmt.Sprintf(f, a...)
+	runtime.Goexit()
+}
+
+// runAssert calls f with a recordingT in its own goroutine, so
+// that Fatalf can stop it.
+func runAssert(f func(t testing.TB)) *recordingT {
+	t := &recordingT{}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		f(t)
+	}()
+	<-done
+	return t
+}
+
+func TestAssertNoError(t *testing.T) {
+	rt := runAssert(func(t testing.TB) {
+		errgotest.AssertNoError(t, nil)
+	})
+	if rt.failed != "" {
+		t.Fatalf("unexpected failure %q", rt.failed)
+	}
+	err := errgo.Notef(errAssert, "foo")
+	rt = runAssert(func(t testing.TB) {
+		errgotest.AssertNoError(t, err)
+	})
+	if !rt.helper {
+		t.Errorf("Helper was not called")
+	}
+	if want := "details: " + errgo.Details(err); !strings.Contains(rt.failed, want) {
+		t.Errorf("failure %q does not contain %q", rt.failed, want)
+	}
+}
+
+func TestAssertCause(t *testing.T) {
+	rt := runAssert(func(t testing.TB) {
+		errgotest.AssertCause(t, errgo.Mask(errAssert, errgo.Is(errAssert)), errAssert)
+	})
+	if rt.failed != "" {
+		t.Fatalf("unexpected failure %q", rt.failed)
+	}
+	err := errgo.Mask(errAssert)
+	rt = runAssert(func(t testing.TB) {
+		errgotest.AssertCause(t, err, errAssert)
+	})
+	if !rt.helper {
+		t.Errorf("Helper was not called")
+	}
+	if want := "details: " + errgo.Details(err); !strings.Contains(rt.failed, want) {
+		t.Errorf("failure %q does not contain %q", rt.failed, want)
+	}
+	rt = runAssert(func(t testing.TB) {
+		errgotest.AssertCause(t, nil, errAssert)
+	})
+	if want := "unexpected nil error; want cause assert error"; rt.failed != want {
+		t.Errorf("got failure %q want %q", rt.failed, want)
+	}
+}
@@ -0,0 +1,119 @@
+// The errgotest package provides gocheck checkers and plain
+// testing helpers for making assertions about errors created
+// by the errgo package. It is separate from errgo so that
+// programs using errgo do not import the testing package.
+package errgotest
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/errgo"
+)
+
+// ErrorIsCause checks whether the cause of the obtained
+// error (see errgo.Cause) is the expected error.
+//
+// For example:
+//
+//	c.Assert(err, errgotest.ErrorIsCause, os.ErrNotExist)
+var ErrorIsCause gc.Checker = &errorIsCauseChecker{
+	&gc.CheckerInfo{Name: "ErrorIsCause", Params: []string{"obtained", "cause"}},
+}
+
+type errorIsCauseChecker struct {
+	*gc.CheckerInfo
+}
+
+func (checker *errorIsCauseChecker) Check(params []interface{}, names []string) (result bool, msg string) {
+	err, ok := params[0].(error)
+	if !ok {
+		return false, "obtained value is not an error"
+	}
+	cause, ok := params[1].(error)
+	if !ok && params[1] != nil {
+		return false, "expected cause is not an error"
+	}
+	if got := errgo.Cause(err); got != cause {
+		return false, fmt.Sprintf("cause is %s", errgo.Details(got))
+	}
+	return true, ""
+}
+
+// DetailsMatches checks whether the details of the
+// obtained error (see errgo.Details) match the
+// given regular expression. The regular expression
+// must match the entire details string.
+//
+// For example:
+//
+//	c.Assert(err, errgotest.DetailsMatches, `\[\{.*: cannot open\} \{.*: not found\}\]`)
+var DetailsMatches gc.Checker = &detailsMatchesChecker{
+	&gc.CheckerInfo{Name: "DetailsMatches", Params: []string{"obtained", "regex"}},
+}
+
+type detailsMatchesChecker struct {
+	*gc.CheckerInfo
+}
+
+func (checker *detailsMatchesChecker) Check(params []interface{}, names []string) (result bool, msg string) {
+	err, ok := params[0].(error)
+	if !ok {
+		return false, "obtained value is not an error"
+	}
+	pattern, ok := params[1].(string)
+	if !ok {
+		return false, "regex must be a string"
+	}
+	re, rerr := regexp.Compile("^(" + pattern + ")$")
+	if rerr != nil {
+		return false, "cannot compile regex: " + rerr.Error()
+	}
+	details := errgo.Details(err)
+	if !re.MatchString(details) {
+		return false, fmt.Sprintf("details are %q", details)
+	}
+	return true, ""
+}
+
+// HasLocationIn checks whether the obtained error
+// records a source location in the given file. The file
+// name matches if it is equal to the recorded file name
+// or to a trailing slash-separated part of it, so
+// "errors_test.go" and "errgo/errors_test.go" both
+// match "/home/user/src/errgo/errors_test.go".
+//
+// For example:
+//
+//	c.Assert(err, errgotest.HasLocationIn, "server.go")
+var HasLocationIn gc.Checker = &hasLocationInChecker{
+	&gc.CheckerInfo{Name: "HasLocationIn", Params: []string{"obtained", "file"}},
+}
+
+type hasLocationInChecker struct {
+	*gc.CheckerInfo
+}
+
+func (checker *hasLocationInChecker) Check(params []interface{}, names []string) (result bool, msg string) {
+	err, ok := params[0].(errgo.Locationer)
+	if !ok {
+		return false, "obtained value does not implement errgo.Locationer"
+	}
+	file, ok := params[1].(string)
+	if !ok {
+		return false, "file must be a string"
+	}
+	loc := err.Location()
+	if !loc.IsSet() {
+		return false, "error has no location"
+	}
+	got := filepath.ToSlash(loc.File)
+	if got != file && !strings.HasSuffix(got, "/"+file) {
+		return false, fmt.Sprintf("location is %v", loc)
+	}
+	return true, ""
+}
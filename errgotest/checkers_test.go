@@ -0,0 +1,139 @@
+package errgotest_test
+
+import (
+	"fmt"
+	"testing"
+
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/errgo"
+	"github.com/juju/errgo/errgotest"
+)
+
+func Test(t *testing.T) {
+	gc.TestingT(t)
+}
+
+type checkersSuite struct{}
+
+var _ = gc.Suite(&checkersSuite{})
+
+var someErr = errgo.New("some error")
+
+// sourceLocations reports whether errors record the source
+// locations of their callers, which they do not in minimal
+// builds or on runtimes with limited support for finding
+// callers (see errgo.RestrictedLocationLabel).
+var sourceLocations = func() bool {
+	loc, ok := errgo.InnermostLocation(someErr)
+	return ok && loc.File != errgo.RestrictedLocationLabel
+}()
+
+var checkerTests = []struct {
+	about    string
+	checker  gc.Checker
+	obtained interface{}
+	arg      interface{}
+	result   bool
+	msg      string
+
+	// needsLocations is set when the result depends on
+	// the locations recorded by the obtained error.
+	needsLocations bool
+}{{
+	about:    "ErrorIsCause with matching cause",
+	checker:  errgotest.ErrorIsCause,
+	obtained: errgo.Mask(someErr, errgo.Is(someErr)),
+	arg:      someErr,
+	result:   true,
+}, {
+	about:    "ErrorIsCause with masked cause",
+	checker:  errgotest.ErrorIsCause,
+	obtained: errgo.Notef(someErr, "bar"),
+	arg:      someErr,
+	result:   false,
+	msg:      `cause is \[\{.*checkers_test.go:\d+: bar\} \{.*checkers_test.go:\d+: some error\}\]`,
+
+	needsLocations: true,
+}, {
+	about:    "ErrorIsCause with non-error",
+	checker:  errgotest.ErrorIsCause,
+	obtained: "foo",
+	arg:      someErr,
+	result:   false,
+	msg:      "obtained value is not an error",
+}, {
+	about:    "DetailsMatches with matching details",
+	checker:  errgotest.DetailsMatches,
+	obtained: errgo.Notef(someErr, "bar"),
+	arg:      `\[\{.*: bar\} \{.*: some error\}\]`,
+	result:   true,
+
+	needsLocations: true,
+}, {
+	about:    "DetailsMatches with partial match",
+	checker:  errgotest.DetailsMatches,
+	obtained: errgo.Notef(someErr, "bar"),
+	arg:      `.*: bar\}`,
+	result:   false,
+	msg:      `details are ".*"`,
+}, {
+	about:    "DetailsMatches with bad regex",
+	checker:  errgotest.DetailsMatches,
+	obtained: someErr,
+	arg:      `(`,
+	result:   false,
+	msg:      "cannot compile regex: .*",
+}, {
+	about:    "HasLocationIn with base name",
+	checker:  errgotest.HasLocationIn,
+	obtained: someErr,
+	arg:      "checkers_test.go",
+	result:   true,
+
+	needsLocations: true,
+}, {
+	about:    "HasLocationIn with directory",
+	checker:  errgotest.HasLocationIn,
+	obtained: someErr,
+	arg:      "errgotest/checkers_test.go",
+	result:   true,
+
+	needsLocations: true,
+}, {
+	about:    "HasLocationIn with partial base name",
+	checker:  errgotest.HasLocationIn,
+	obtained: someErr,
+	arg:      "s_test.go",
+	result:   false,
+	msg:      "location is .*checkers_test.go:\\d+",
+
+	needsLocations: true,
+}, {
+	about:    "HasLocationIn with foreign error",
+	checker:  errgotest.HasLocationIn,
+	obtained: fmt.Errorf("foo"),
+	arg:      "checkers_test.go",
+	result:   false,
+	msg:      "obtained value does not implement errgo.Locationer",
+}, {
+	about:    "HasLocationIn with no location",
+	checker:  errgotest.HasLocationIn,
+	obtained: &errgo.Err{Message_: "foo"},
+	arg:      "checkers_test.go",
+	result:   false,
+	msg:      "error has no location",
+}}
+
+func (*checkersSuite) TestCheckers(c *gc.C) {
+	for i, test := range checkerTests {
+		c.Logf("test %d: %s", i, test.about)
+		if test.needsLocations && !sourceLocations {
+			c.Logf("skipped: errors record no locations")
+			continue
+		}
+		result, msg := test.checker.Check([]interface{}{test.obtained, test.arg}, nil)
+		c.Check(result, gc.Equals, test.result)
+		c.Check(msg, gc.Matches, test.msg)
+	}
+}
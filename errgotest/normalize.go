@@ -0,0 +1,50 @@
+package errgotest
+
+import "regexp"
+
+// NormalizeOptions holds options for NormalizeStack.
+type NormalizeOptions struct {
+	// Dir holds the text that replaces the directory
+	// of each source file name, including its final
+	// separator. If it is empty, the directory is removed,
+	// leaving only the base name of the file.
+	Dir string
+
+	// Line holds the text that replaces each line number.
+	// If it is empty, the line number is removed along with
+	// the colon that precedes it.
+	Line string
+}
+
+var stackLocationPattern = regexp.MustCompile(`([^\s{}\[\]()]*[/\\])?([^\s{}\[\]()/\\:]+\.go):(\d+)`)
+
+// NormalizeStack replaces source locations of the form
+// /path/to/file.go:99 in s, as produced by errgo.Details,
+// according to the given options, so that the result can
+// be compared against golden output without depending on
+// where the source code lives or on exact line numbers.
+// If opts is nil, directories and line numbers are removed.
+//
+// For example, with a nil opts,
+//
+//	[{/home/user/src/server.go:99: cannot start} {/home/user/src/conn.go:55: refused}]
+//
+// is normalized to:
+//
+//	[{server.go: cannot start} {conn.go: refused}]
+func NormalizeStack(s string, opts *NormalizeOptions) string {
+	if opts == nil {
+		opts = &NormalizeOptions{}
+	}
+	return stackLocationPattern.ReplaceAllStringFunc(s, func(loc string) string {
+		m := stackLocationPattern.FindStringSubmatch(loc)
+		file := m[2]
+		if m[1] != "" && opts.Dir != "" {
+			file = opts.Dir + file
+		}
+		if opts.Line == "" {
+			return file
+		}
+		return file + ":" + opts.Line
+	})
+}
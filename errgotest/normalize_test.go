@@ -0,0 +1,53 @@
+package errgotest_test
+
+import (
+	"testing"
+
+	"github.com/juju/errgo"
+	"github.com/juju/errgo/errgotest"
+)
+
+var normalizeStackTests = []struct {
+	about  string
+	stack  string
+	opts   *errgotest.NormalizeOptions
+	expect string
+}{{
+	about:  "nil options",
+	stack:  "[{/home/user/src/server.go:99: cannot start} {/home/user/src/conn.go:55: refused}]",
+	expect: "[{server.go: cannot start} {conn.go: refused}]",
+}, {
+	about:  "tokens",
+	stack:  "[{/home/user/src/server.go:99: cannot start} {conn.go:55: refused}]",
+	opts:   &errgotest.NormalizeOptions{Dir: "$DIR/", Line: "$LINE"},
+	expect: "[{$DIR/server.go:$LINE: cannot start} {conn.go:$LINE: refused}]",
+}, {
+	about:  "windows path",
+	stack:  `[{C:\src\server.go:99: cannot start}]`,
+	expect: "[{server.go: cannot start}]",
+}, {
+	about:  "parenthesized locations",
+	stack:  "cannot start <- refused (/src/server.go:99 → /src/conn.go:55)",
+	expect: "cannot start <- refused (server.go → conn.go)",
+}, {
+	about:  "no locations",
+	stack:  "[{: foo} {bar.go is missing}]",
+	expect: "[{: foo} {bar.go is missing}]",
+}}
+
+func TestNormalizeStack(t *testing.T) {
+	for i, test := range normalizeStackTests {
+		if got := errgotest.NormalizeStack(test.stack, test.opts); got != test.expect {
+			t.Errorf("test %d (%s): got %q want %q", i, test.about, got, test.expect)
+		}
+	}
+
+	if !sourceLocations {
+		t.Skip("errors do not record source locations")
+	}
+	err := errgo.Notef(errgo.New("foo"), "bar")
+	want := "[{normalize_test.go:$LINE: bar} {normalize_test.go:$LINE: foo}]"
+	if got := errgotest.NormalizeStack(errgo.Details(err), &errgotest.NormalizeOptions{Line: "$LINE"}); got != want {
+		t.Errorf("got %q want %q", got, want)
+	}
+}
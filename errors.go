@@ -11,6 +11,9 @@ import (
 	"bytes"
 	"fmt"
 	"runtime"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/juju/loggo"
 )
@@ -31,9 +34,10 @@ type Location struct {
 	Line int
 }
 
-// String returns a location in filename.go:99 format.
+// String returns a location in filename.go:99 format,
+// with any path mapping applied (see RegisterPathMapping).
 func (loc Location) String() string {
-	return fmt.Sprintf("%s:%d", loc.File, loc.Line)
+	return loc.Formatted(LocationFull)
 }
 
 // IsSet reports whether the location has been set.
@@ -47,6 +51,15 @@ func (loc Location) IsSet() bool {
 // It may be embedded  in custom error types to add
 // extra information that this errors package can
 // understand.
+//
+// An Err must not be modified once it has been returned by its
+// constructor (see Created), so that errors can be shared freely
+// between goroutines: all the methods of Err and all the
+// functions in this package that inspect or format errors
+// are then safe for concurrent use.
+//
+// Err values are comparable, so that error types embedding
+// Err by value may be compared with ==.
 type Err struct {
 	// Message_ holds the text of the error message. It may be empty
 	// if Underlying is set.
@@ -62,6 +75,55 @@ type Err struct {
 	// Location holds the source code location where the error was
 	// created.
 	Location_ Location
+
+	// Function_ holds the fully qualified name of the
+	// function holding Location_, if recorded.
+	Function_ string
+
+	// ID_ holds the unique identifier of the error, if any.
+	// See AssignIDs.
+	ID_ string
+
+	// Time_ holds the time the error was created, if recorded.
+	// See RecordTimes.
+	Time_ time.Time
+
+	// Format_ holds the format string used to create
+	// Message_, if it was formatted.
+	Format_ string
+
+	// Extra_ holds the stack frames and format arguments
+	// of the error, if any were recorded.
+	Extra_ *Extra
+
+	// frozen records that the error has been passed
+	// to Created and must no longer be modified.
+	frozen bool
+}
+
+// Extra holds the information recorded by an error in slices,
+// which is kept apart from Err so that Err values are comparable.
+// It must not be modified once the error holding it has been
+// returned by its constructor.
+type Extra struct {
+	// Frames holds the locations of the callers of the
+	// function holding the location of the error, if
+	// recorded. See SetFrameDepth.
+	Frames []Location
+
+	// FrameFunctions holds the fully qualified names of
+	// the functions holding Frames, in the same order,
+	// if recorded.
+	FrameFunctions []string
+
+	// Args holds the arguments formatted with
+	// the format string of the error.
+	Args []interface{}
+}
+
+// freeze marks e as complete. It is called by Created.
+func (e *Err) freeze() {
+	e.frozen = true
 }
 
 // Location implements Locationer.
@@ -69,11 +131,42 @@ func (e *Err) Location() Location {
 	return e.Location_
 }
 
+// Time implements Timestamper.
+func (e *Err) Time() time.Time {
+	return e.Time_
+}
+
+// Function implements Functioner.
+func (e *Err) Function() string {
+	return e.Function_
+}
+
+// Frames implements Framer.
+func (e *Err) Frames() []Location {
+	if e.Extra_ == nil {
+		return nil
+	}
+	return e.Extra_.Frames
+}
+
+// FrameFunctions implements FrameFunctioner.
+func (e *Err) FrameFunctions() []string {
+	if e.Extra_ == nil {
+		return nil
+	}
+	return e.Extra_.FrameFunctions
+}
+
 // Underlying returns the underlying error if any.
 func (e *Err) Underlying() error {
 	return e.Underlying_
 }
 
+// ID implements Identifier.
+func (e *Err) ID() string {
+	return e.ID_
+}
+
 // Cause implements Causer.
 func (e *Err) Cause() error {
 	return e.Cause_
@@ -84,17 +177,109 @@ func (e *Err) Message() string {
 	return e.Message_
 }
 
+// FormatArgs returns the format string and arguments from which
+// the message was created by a formatting function such as Newf or
+// Notef, so that logging adapters can record the arguments as
+// separate fields. The format string is empty if the message
+// was not formatted.
+func (e *Err) FormatArgs() (format string, args []interface{}) {
+	if e.Extra_ == nil {
+		return e.Format_, nil
+	}
+	return e.Format_, e.Extra_.Args
+}
+
+// setMessagef sets the message of e by formatting
+// the given arguments, recording the format string
+// and a copy of the arguments, so that the error is
+// not changed if the caller reuses its slice.
+func (e *Err) setMessagef(f string, a []interface{}) {
+	e.Message_ = fmt.Sprintf(f, a...)
+	e.Format_ = f
+	e.extra().Args = append([]interface{}(nil), a...)
+}
+
+// extra returns the extra information of e,
+// allocating it if necessary.
+func (e *Err) extra() *Extra {
+	if e.Extra_ == nil {
+		e.Extra_ = &Extra{}
+	}
+	return e.Extra_
+}
+
+// setFrames sets the stack frames recorded by e.
+func (e *Err) setFrames(frames []Location, funcs []string) {
+	if e.Extra_ == nil && frames == nil {
+		return
+	}
+	x := e.extra()
+	x.Frames, x.FrameFunctions = frames, funcs
+}
+
+// MessageSeparator holds the separator placed by Error between
+// the message of an error and the message of its underlying error.
+//
+// It should be set before any errors are formatted, usually
+// during program initialization.
+var MessageSeparator = ": "
+
+// UnderlyingFirst controls whether Error places the message of an
+// underlying error before the message of the error that wraps it,
+// for example "not found: cannot get user" rather than
+// "cannot get user: not found".
+//
+// It should be set before any errors are formatted, usually
+// during program initialization.
+var UnderlyingFirst = false
+
+// DeduplicateMessages controls whether Error omits the message of
+// an error when it is the same as the next message in its chain,
+// as happens when the same annotation is applied at several
+// layers, so that "timeout: connection refused" is produced
+// rather than "timeout: timeout: connection refused". Errors
+// that do not add a message, such as those created by Mask,
+// are skipped when finding the next message.
+//
+// It should be set before any errors are formatted, usually
+// during program initialization.
+var DeduplicateMessages = false
+
+// nextMessage returns the first non-empty message
+// in the chain of err, or the empty string if
+// there is none.
+func nextMessage(err error) string {
+	for ; err != nil; err = underlying(err) {
+		if msg := message(err); msg != "" {
+			return msg
+		}
+	}
+	return ""
+}
+
+// joinMessage returns the message of an error made from its own
+// message, msg, and that of its underlying errors, according to
+// MessageSeparator and UnderlyingFirst.
+func joinMessage(msg, underlying string) string {
+	if UnderlyingFirst {
+		return underlying + MessageSeparator + msg
+	}
+	return msg + MessageSeparator + underlying
+}
+
 // Error implements error.Error.
 func (e *Err) Error() string {
 	switch {
-	case e.Message_ == "" && e.Underlying == nil:
+	case e.Message_ == "" && e.Underlying_ == nil:
 		return "<no error>"
 	case e.Message_ == "":
 		return e.Underlying_.Error()
 	case e.Underlying_ == nil:
 		return e.Message_
+	case DeduplicateMessages && e.Message_ == nextMessage(e.Underlying_):
+		return e.Underlying_.Error()
 	}
-	return fmt.Sprintf("%s: %v", e.Message_, e.Underlying_)
+	return joinMessage(e.Message_, e.Underlying_.Error())
 }
 
 // GoString returns the details of the receiving error
@@ -142,56 +327,317 @@ type Locationer interface {
 // Details of the underlying stack are found by
 // recursively calling Underlying when the
 // underlying error implements Wrapper.
+//
+// If an error implements MultiWrapper, or is a multi-error
+// container from the github.com/hashicorp/go-multierror or
+// go.uber.org/multierr packages, or wraps several errors as
+// errors.Join does, the details of each of its
+// underlying errors are included after its message,
+// for example:
+//
+// 	[{filename:99: 2 of 3 failed [{a.go:10: one}] [{b.go:20: two}]}]
+//
+// If an error has been assigned a unique identifier (see
+// AssignIDs), it is included at the start of its entry,
+// for example:
+//
+// 	[{#Q4ZKX7RM filename:99: error one}]
+//
+// Errors with a formatter registered by RegisterFormatter
+// are rendered using that formatter instead of their message.
+// Errors with a stack registered by RegisterStack, such as those
+// created by the github.com/pkg/errors package, are shown with
+// the locations of that stack, in the same way as errors that
+// implement Framer (see SetFrameDepth).
 func Details(err error) string {
-	if err == nil {
-		return "[]"
-	}
-	var s []byte
-	s = append(s, '[')
-	for {
-		s = append(s, '{')
-		if err, ok := err.(Locationer); ok {
-			loc := err.Location()
-			if loc.IsSet() {
-				s = append(s, loc.String()...)
-				s = append(s, ": "...)
-			}
+	return FormatDetails(err, DetailsOptions{})
+}
+
+// DetailsOptions holds options for FormatDetails.
+type DetailsOptions struct {
+	// CollapseMasks causes consecutive errors in the chain that
+	// only record a location, such as those created by Mask
+	// when passing an error through trivial layers, to be
+	// collapsed into a single entry listing their locations,
+	// for example:
+	//
+	// 	[{a.go:10: cannot open} {via 3 frames: b.go:20, c.go:30, d.go:40} {os error}]
+	CollapseMasks bool
+
+	// OmitMasks causes errors in the chain that only record a
+	// location and do not change the cause of the error (see
+	// Cause), such as those created by Mask with a pass function
+	// that matches, to be omitted. Causes are considered
+	// unchanged if they are equal or if either reports that it
+	// is the other (see errors.Is). It takes precedence
+	// over CollapseMasks.
+	OmitMasks bool
+
+	// Elapsed causes each entry whose error recorded its creation
+	// time (see RecordTimes) to be prefixed with the time elapsed
+	// since the creation of the next such error in the chain,
+	// for example:
+	//
+	// 	[{+30.2s a.go:10: giving up} {b.go:20: connection refused}]
+	Elapsed bool
+
+	// LocationFormat specifies how the file names
+	// of locations are shown.
+	LocationFormat LocationFormat
+}
+
+// CompactDetails is like Details except that errors that only record
+// a location and leave the cause unchanged are omitted, so that
+// only entries that add a message or change the cause remain.
+// It is intended for operator-facing logs; Details remains
+// available for deeper debugging.
+func CompactDetails(err error) string {
+	return FormatDetails(err, DetailsOptions{
+		OmitMasks: true,
+	})
+}
+
+// FormatDetails is like Details but allows the
+// output to be customized with the given options.
+func FormatDetails(err error, opts DetailsOptions) string {
+	var b strings.Builder
+	b.Grow(detailsSize(err))
+	writeDetails(&b, err, opts)
+	return b.String()
+}
+
+// detailsSize returns an estimate of the length of
+// the details of err, so that the output can be
+// allocated in one go.
+func detailsSize(err error) int {
+	n := 2
+	for ; err != nil; err = underlying(err) {
+		n += len(message(err)) + 48
+	}
+	return n
+}
+
+// writeDetails writes the details of err to b.
+func writeDetails(b *strings.Builder, err error, opts DetailsOptions) {
+	b.WriteByte('[')
+	start := b.Len()
+	var masks []error
+	for err != nil {
+		if opts.OmitMasks && isMask(err) && sameError(Cause(err), Cause(underlying(err))) {
+			err = underlying(err)
+			continue
 		}
-		if cerr, ok := err.(Wrapper); ok {
-			s = append(s, cerr.Message()...)
-			err = cerr.Underlying()
-		} else {
-			s = append(s, err.Error()...)
-			err = nil
+		if opts.CollapseMasks && isMask(err) {
+			masks = append(masks, err)
+			err = underlying(err)
+			continue
+		}
+		writeMasks(b, masks, opts, start)
+		masks = masks[:0]
+		writeEntry(b, err, opts, start)
+		err = underlying(err)
+	}
+	writeMasks(b, masks, opts, start)
+	b.WriteByte(']')
+}
+
+// isMask reports whether err is a link in an error chain
+// that adds nothing to Details except its location.
+func isMask(err error) bool {
+	w, ok := err.(Wrapper)
+	if !ok || w.Message() != "" || w.Underlying() == nil || len(branches(err)) > 0 {
+		return false
+	}
+	if err, ok := err.(Identifier); ok && err.ID() != "" {
+		return false
+	}
+	_, formatted := format(err)
+	return !formatted
+}
+
+// writeMasks writes a single entry describing the given
+// mask errors (see isMask) to b, preceded by a space
+// if b has grown beyond start.
+func writeMasks(b *strings.Builder, masks []error, opts DetailsOptions, start int) {
+	if len(masks) == 1 {
+		writeEntry(b, masks[0], opts, start)
+		return
+	}
+	if len(masks) == 0 {
+		return
+	}
+	if b.Len() > start {
+		b.WriteByte(' ')
+	}
+	b.WriteString("{via ")
+	b.WriteString(strconv.Itoa(len(masks)))
+	b.WriteString(" frames")
+	sep := ": "
+	for _, err := range masks {
+		if err, ok := err.(Locationer); ok && err.Location().IsSet() {
+			b.WriteString(sep)
+			writeLocation(b, err.Location(), opts.LocationFormat)
+			sep = ", "
+		}
+	}
+	b.WriteByte('}')
+}
+
+// writeEntry writes the Details entry for the given link
+// in an error chain to b, preceded by a space if b has
+// grown beyond start.
+func writeEntry(b *strings.Builder, err error, opts DetailsOptions, start int) {
+	if b.Len() > start {
+		b.WriteByte(' ')
+	}
+	b.WriteByte('{')
+	if err, ok := err.(Identifier); ok {
+		if id := err.ID(); id != "" {
+			b.WriteByte('#')
+			b.WriteString(id)
+			b.WriteByte(' ')
+		}
+	}
+	if opts.Elapsed {
+		if d, ok := elapsed(err); ok {
+			b.WriteByte('+')
+			b.WriteString(d.String())
+			b.WriteByte(' ')
 		}
-		if debug {
-			if err, ok := err.(Causer); ok {
-				if cause := err.Cause(); cause != nil {
-					s = append(s, fmt.Sprintf("=%T", cause)...)
-					s = append(s, Details(cause)...)
+	}
+	var loc Location
+	var frames []Location
+	if lerr, ok := err.(Locationer); ok {
+		loc = lerr.Location()
+		if ferr, ok := err.(Framer); ok {
+			frames = ferr.Frames()
+		}
+	} else if locs, _ := stackLocations(err); len(locs) > 0 {
+		loc, frames = locs[0], locs[1:]
+	}
+	if loc.IsSet() {
+		writeLocation(b, loc, opts.LocationFormat)
+		if len(frames) > 0 {
+			b.WriteString(" (from ")
+			for i, frame := range frames {
+				if i > 0 {
+					b.WriteString(", ")
 				}
+				writeLocation(b, frame, opts.LocationFormat)
 			}
+			b.WriteByte(')')
 		}
-		s = append(s, '}')
-		if err == nil {
-			break
+		b.WriteString(": ")
+	}
+	text, formatted := format(err)
+	if !formatted {
+		text = message(err)
+	}
+	b.WriteString(text)
+	writeDetailFields(b, err)
+	for _, branch := range branches(err) {
+		if s := b.String(); s[len(s)-1] != '{' && s[len(s)-1] != ' ' {
+			b.WriteByte(' ')
+		}
+		writeDetails(b, branch, opts)
+	}
+	if debug {
+		if err, ok := underlying(err).(Causer); ok {
+			if cause := err.Cause(); cause != nil {
+				fmt.Fprintf(b, "=%T", cause)
+				writeDetails(b, cause, DetailsOptions{})
+			}
 		}
-		s = append(s, ' ')
 	}
-	s = append(s, ']')
-	return string(s)
+	b.WriteByte('}')
+}
+
+// writeLocation writes loc to b in the
+// format of Location.Formatted.
+func writeLocation(b *strings.Builder, loc Location, f LocationFormat) {
+	if f == LocationEditor {
+		b.WriteString(loc.Formatted(f))
+		return
+	}
+	b.WriteString(loc.file(f))
+	b.WriteByte(':')
+	var buf [20]byte
+	b.Write(strconv.AppendInt(buf[:0], int64(loc.Line), 10))
 }
 
 // Locate records the source location of the error by setting
 // e.Location, at callDepth stack frames above the call.
+// Frames in packages registered with SkipPackage are
+// not counted. If SetFrameDepth has been called, the
+// locations of callers are recorded in e.Frames too.
+//
+// If AssignIDs is true and the error has no identifier,
+// it is also assigned a new unique identifier. If RecordTimes
+// is true and the error has no time, the current time
+// is recorded.
+//
+// SetLocation is intended for use by the constructors of custom
+// error types. It panics if the error has already been passed
+// to Created, because the error may then be shared with other
+// goroutines.
 func (e *Err) SetLocation(callDepth int) {
-	_, file, line, _ := runtime.Caller(callDepth + 1)
-	e.Location_ = Location{file, line}
+	if e.frozen {
+		panic("errgo: SetLocation called on error after Created")
+	}
+	e.setLocation(callDepth + 1)
+	e.setMetadata()
+}
+
+// setLocation records the location as SetLocation
+// does, without assigning metadata.
+func (e *Err) setLocation(callDepth int) {
+	if minimal {
+		return
+	}
+	if frameDepth <= 1 || sampler != nil {
+		// The location is recorded in local variables so
+		// that nothing is allocated when no frames are kept.
+		var loc [1]Location
+		var function [1]string
+		n := callerLocations(loc[:], function[:], callDepth+1)
+		if frameDepth <= 1 || n == 0 || !sampler(loc[0]) {
+			e.Location_, e.Function_ = loc[0], function[0]
+			e.setFrames(nil, nil)
+			return
+		}
+	}
+	locs, funcs := make([]Location, frameDepth), make([]string, frameDepth)
+	n := callerLocations(locs, funcs, callDepth+1)
+	e.Location_, e.Function_ = locs[0], funcs[0]
+	if n > 1 {
+		e.setFrames(locs[1:n:n], funcs[1:n:n])
+	} else {
+		e.setFrames(nil, nil)
+	}
+}
+
+// setSite sets the location of e to that recorded in site
+// with setLocation and assigns its metadata, for errors made
+// away from the call they are attributed to, such as in
+// another goroutine.
+func (e *Err) setSite(site *Err) {
+	e.Location_, e.Function_ = site.Location_, site.Function_
+	e.setFrames(site.Frames(), site.FrameFunctions())
+	e.setMetadata()
 }
 
-func setLocation(err error, callDepth int) {
-	if e, _ := err.(*Err); e != nil {
-		e.SetLocation(callDepth + 1)
+// setMetadata assigns an identifier to e and records
+// its creation time if required (see AssignIDs
+// and RecordTimes).
+func (e *Err) setMetadata() {
+	if minimal {
+		return
+	}
+	if AssignIDs && e.ID_ == "" {
+		e.ID_ = newID()
+	}
+	if RecordTimes && e.Time_.IsZero() {
+		e.Time_ = now()
 	}
 }
 
@@ -200,15 +646,16 @@ func setLocation(err error, callDepth int) {
 func New(s string) error {
 	err := &Err{Message_: s}
 	err.SetLocation(1)
-	return err
+	return Created(err)
 }
 
 // Newf returns a new error with the given printf-formatted error
 // message and no cause.
 func Newf(f string, a ...interface{}) error {
-	err := &Err{Message_: fmt.Sprintf(f, a...)}
+	err := &Err{}
+	err.setMessagef(f, a)
 	err.SetLocation(1)
-	return err
+	return Created(err)
 }
 
 // match returns whether any of the given
@@ -252,15 +699,48 @@ func Any(error) bool {
 // the result if allowed by the specific pass functions
 // (see Mask for an explanation of the pass parameter).
 func NoteMask(underlying error, msg string, pass ...func(error) bool) error {
-	newErr := &Err{
-		Underlying_: underlying,
-		Message_:    msg,
+	err := noteMask(underlying, msg, pass...)
+	err.SetLocation(1)
+	return Created(err)
+}
+
+// NoteMaskf is like NoteMask except that the context message is
+// formatted and the cause is retained only if allowed by pass.
+// It adds a message and masks in one call, so the returned
+// error has a single location, that of its caller. If pass
+// is nil, the cause is concealed, as with Notef.
+func NoteMaskf(underlying error, pass func(error) bool, f string, a ...interface{}) error {
+	var err *Err
+	if pass == nil {
+		err = noteMask(underlying, "")
+	} else {
+		err = noteMask(underlying, "", pass)
 	}
+	err.setMessagef(f, a)
+	err.SetLocation(1)
+	return Created(err)
+}
+
+// PassedCause returns the cause of an error wrapping the given
+// underlying error, as determined by the given pass functions
+// (see Mask for an explanation of the pass parameter), or
+// nil if the cause is concealed. It is intended for use
+// by the constructors of custom error types.
+func PassedCause(underlying error, pass ...func(error) bool) error {
 	if len(pass) > 0 {
-		if cause := Cause(underlying); match(cause, pass...) {
-			newErr.Cause_ = cause
+		if cause := Cause(underlying); matchCause(cause, pass...) {
+			return cause
 		}
 	}
+	return nil
+}
+
+func noteMask(underlying error, msg string, pass ...func(error) bool) *Err {
+	newErr := &Err{
+		Underlying_: underlying,
+		Message_:    msg,
+		Cause_:      PassedCause(underlying, pass...),
+	}
 	if debug {
 		if newd, oldd := newErr.Cause_, Cause(underlying); newd != oldd {
 			logger.Infof("Mask cause %[1]T(%[1]v)->%[2]T(%[2]v)", oldd, newd)
@@ -281,7 +761,9 @@ func NoteMask(underlying error, msg string, pass ...func(error) bool) error {
 //
 // By default Mask conceals the cause of the wrapped error, but if
 // pass(Cause(err)) returns true for any of the provided pass functions,
-// the cause of the returned error will be Cause(err).
+// the cause of the returned error will be Cause(err). If the cause
+// wraps several errors (see MultiWrapper), it is also passed through
+// when pass returns true for any of their causes (see Causes).
 //
 // For example, the following code will return an error whose cause is
 // the error from the os.Open call when (and only when) the file does
@@ -300,9 +782,9 @@ func Mask(underlying error, pass ...func(error) bool) error {
 	if underlying == nil {
 		return nil
 	}
-	err := NoteMask(underlying, "", pass...)
-	setLocation(err, 1)
-	return err
+	err := noteMask(underlying, "", pass...)
+	err.SetLocation(1)
+	return Created(err)
 }
 
 // Notef returns an Error that wraps the given underlying
@@ -310,9 +792,10 @@ func Mask(underlying error, pass ...func(error) bool) error {
 // The returned error has no cause (use NoteMask
 // or WithCausef to add a message while retaining a cause).
 func Notef(underlying error, f string, a ...interface{}) error {
-	err := NoteMask(underlying, fmt.Sprintf(f, a...))
-	setLocation(err, 1)
-	return err
+	err := noteMask(underlying, "")
+	err.setMessagef(f, a)
+	err.SetLocation(1)
+	return Created(err)
 }
 
 // MaskFunc returns an equivalent of Mask that always allows the
@@ -323,6 +806,9 @@ func Notef(underlying error, f string, a ...interface{}) error {
 // a given package wish to allow the same set of causes to be returned.
 func MaskFunc(allow ...func(error) bool) func(error, ...func(error) bool) error {
 	return func(err error, allow1 ...func(error) bool) error {
+		if err == nil {
+			return nil
+		}
 		var allowEither []func(error) bool
 		if len(allow1) > 0 {
 			// This is more efficient than using a function literal,
@@ -333,9 +819,9 @@ func MaskFunc(allow ...func(error) bool) func(error, ...func(error) bool) error
 		} else {
 			allowEither = allow
 		}
-		err = Mask(err, allowEither...)
-		setLocation(err, 1)
-		return err
+		newErr := noteMask(err, "", allowEither...)
+		newErr.SetLocation(1)
+		return Created(newErr)
 	}
 }
 
@@ -347,10 +833,41 @@ func WithCausef(underlying, cause error, f string, a ...interface{}) error {
 	err := &Err{
 		Underlying_: underlying,
 		Cause_:      cause,
-		Message_:    fmt.Sprintf(f, a...),
 	}
+	err.setMessagef(f, a)
 	err.SetLocation(1)
-	return err
+	return Created(err)
+}
+
+// WithCause returns an error that wraps the given underlying error
+// and has the given cause, without adding a message, so that the
+// error message is unchanged while code inspecting the error sees
+// the new cause. The location of the error is the caller of
+// WithCause. If underlying is nil, WithCause returns nil.
+func WithCause(underlying, cause error) error {
+	if underlying == nil {
+		return nil
+	}
+	err := &Err{
+		Underlying_: underlying,
+		Cause_:      cause,
+	}
+	err.SetLocation(1)
+	return Created(err)
+}
+
+// Because is like WithCausef except that msg is used as the
+// message without formatting. It replaces the cause of the
+// underlying error with cause, adding msg as context, in a
+// single error located at its caller.
+func Because(underlying, cause error, msg string) error {
+	err := &Err{
+		Underlying_: underlying,
+		Cause_:      cause,
+		Message_:    msg,
+	}
+	err.SetLocation(1)
+	return Created(err)
 }
 
 // Cause returns the cause of the given error.  If err does not
@@ -369,23 +886,63 @@ func Cause(err error) error {
 	return err
 }
 
+// RootCause returns the innermost error in the chain of err,
+// found by following underlying errors (see Wrapper) and errors
+// wrapped with an Unwrap method, regardless of whether any of
+// them masked the cause. Unlike Cause, it is intended for
+// post-mortem diagnosis rather than for deciding how to handle
+// an error. An error that wraps several errors (see MultiWrapper)
+// ends the chain. RootCause returns nil if err is nil.
+func RootCause(err error) error {
+	for err != nil {
+		u := next(err)
+		if u == nil {
+			break
+		}
+		err = u
+	}
+	return err
+}
+
+// CauseAs returns the cause of err (see Cause) as a value
+// of type T. If the cause is not of type T, for example because
+// it has been masked, the causes of each of the underlying errors
+// of err (see Wrapper) are tried in turn. It returns false if
+// no cause of type T is found.
+//
+// For example:
+//
+//	if perr, ok := errgo.CauseAs[*os.PathError](err); ok {
+//		log.Printf("cannot access %s", perr.Path)
+//	}
+func CauseAs[T error](err error) (T, bool) {
+	for e := err; e != nil; e = underlying(e) {
+		if cause, ok := Cause(e).(T); ok {
+			return cause, true
+		}
+	}
+	var zero T
+	return zero, false
+}
+
 // callers returns the stack trace of the goroutine that called it,
 // starting n entries above the caller of callers, as a space-separated list
 // of filename:line-number pairs with no new lines.
 func callers(n, max int) []byte {
 	var b bytes.Buffer
-	prev := false
-	for i := 0; i < max; i++ {
-		_, file, line, ok := runtime.Caller(n + 1)
-		if !ok {
+	pcs := make([]uintptr, max)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(n+2, pcs)])
+	for prev := false; ; prev = true {
+		frame, more := frames.Next()
+		if frame.File == "" {
 			return b.Bytes()
 		}
 		if prev {
 			fmt.Fprintf(&b, " ")
 		}
-		fmt.Fprintf(&b, "%s:%d", file, line)
-		n++
-		prev = true
+		fmt.Fprintf(&b, "%s:%d", frame.File, frame.Line)
+		if !more {
+			return b.Bytes()
+		}
 	}
-	return b.Bytes()
 }
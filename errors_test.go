@@ -2,11 +2,13 @@ package errgo_test
 
 import (
 	"fmt"
-	"github.com/juju/errgo"
-	"io/ioutil"
-	"runtime"
+	"reflect"
 	"strings"
 	"testing"
+	"unsafe"
+
+	"github.com/juju/errgo"
+	"github.com/juju/errgo/errgotest"
 )
 
 var (
@@ -16,21 +18,19 @@ var (
 )
 
 func TestNew(t *testing.T) {
-	err := errgo.New("foo") //err TestNew
-	checkErr(t, err, nil, "foo", "[{$TestNew$: foo}]", err)
+	err := errgo.New("foo")
+	checkErr(t, err, nil, "foo", "[{errors_test.go: foo}]", err)
 }
 
 func TestNewf(t *testing.T) {
-	err := errgo.Newf("foo %d", 5) //err TestNewf
-	checkErr(t, err, nil, "foo 5", "[{$TestNewf$: foo 5}]", err)
+	err := errgo.Newf("foo %d", 5)
+	checkErr(t, err, nil, "foo 5", "[{errors_test.go: foo 5}]", err)
 }
 
-var someErr = errgo.New("some error")
-
 func TestMask(t *testing.T) {
-	err0 := errgo.WithCausef(nil, someErr, "foo") //err TestMask#0
-	err := errgo.Mask(err0)                       //err TestMask#1
-	checkErr(t, err, err0, "foo", "[{$TestMask#1$: } {$TestMask#0$: foo}]", err)
+	err0 := errgo.WithCausef(nil, someErr, "foo")
+	err := errgo.Mask(err0)
+	checkErr(t, err, err0, "foo", "[{errors_test.go: } {errors_test.go: foo}]", err)
 
 	err = errgo.Mask(nil)
 	if err != nil {
@@ -39,12 +39,30 @@ func TestMask(t *testing.T) {
 }
 
 func TestNotef(t *testing.T) {
-	err0 := errgo.WithCausef(nil, someErr, "foo") //err TestNotef#0
-	err := errgo.Notef(err0, "bar")               //err TestNotef#1
-	checkErr(t, err, err0, "bar: foo", "[{$TestNotef#1$: bar} {$TestNotef#0$: foo}]", err)
+	err0 := errgo.WithCausef(nil, someErr, "foo")
+	err := errgo.Notef(err0, "bar")
+	checkErr(t, err, err0, "bar: foo", "[{errors_test.go: bar} {errors_test.go: foo}]", err)
+
+	err = errgo.Notef(nil, "bar")
+	checkErr(t, err, nil, "bar", "[{errors_test.go: bar}]", err)
+}
 
-	err = errgo.Notef(nil, "bar") //err TestNotef#2
-	checkErr(t, err, nil, "bar", "[{$TestNotef#2$: bar}]", err)
+func TestNoteMask(t *testing.T) {
+	err0 := errgo.WithCausef(nil, someErr, "foo")
+	err := errgo.NoteMask(err0, "bar", errgo.Any)
+	checkErr(t, err, err0, "bar: foo", "[{errors_test.go: bar} {errors_test.go: foo}]", someErr)
+}
+
+func TestNoteMaskf(t *testing.T) {
+	err0 := errgo.WithCausef(nil, someErr, "foo")
+	err := errgo.NoteMaskf(err0, errgo.Is(someErr), "bar %d", 1)
+	checkErr(t, err, err0, "bar 1: foo", "[{errors_test.go: bar 1} {errors_test.go: foo}]", someErr)
+
+	err = errgo.NoteMaskf(err0, errgo.Is(errNotFound), "bar")
+	checkErr(t, err, err0, "bar: foo", "[{errors_test.go: bar} {errors_test.go: foo}]", err)
+
+	err = errgo.NoteMaskf(err0, nil, "bar")
+	checkErr(t, err, err0, "bar: foo", "[{errors_test.go: bar} {errors_test.go: foo}]", err)
 }
 
 func TestMaskFunc(t *testing.T) {
@@ -119,18 +137,190 @@ func TestCause(t *testing.T) {
 		t.Fatalf("expected %q kind; got %#v", someErr, cause)
 	}
 	causeErr := errgo.New("cause error")
-	underlyingErr := errgo.New("underlying error")                 //err TestCause#1
-	err := errgo.WithCausef(underlyingErr, causeErr, "foo %d", 99) //err TestCause#2
+	underlyingErr := errgo.New("underlying error")
+	err := errgo.WithCausef(underlyingErr, causeErr, "foo %d", 99)
 	if errgo.Cause(err) != causeErr {
 		t.Fatalf("expected %q; got %#v", causeErr, errgo.Cause(err))
 	}
-	checkErr(t, err, underlyingErr, "foo 99: underlying error", "[{$TestCause#2$: foo 99} {$TestCause#1$: underlying error}]", causeErr)
+	checkErr(t, err, underlyingErr, "foo 99: underlying error", "[{errors_test.go: foo 99} {errors_test.go: underlying error}]", causeErr)
 	err = &embed{err.(*errgo.Err)}
 	if errgo.Cause(err) != causeErr {
 		t.Fatalf("expected %q; got %#v", causeErr, errgo.Cause(err))
 	}
 }
 
+func TestWithCause(t *testing.T) {
+	underlyingErr := errgo.New("connection refused")
+	err := errgo.WithCause(underlyingErr, errNotFound)
+	checkErr(t, err, underlyingErr, "connection refused", "[{errors_test.go: } {errors_test.go: connection refused}]", errNotFound)
+
+	if err := errgo.WithCause(nil, errNotFound); err != nil {
+		t.Fatalf("expected nil got %#v", err)
+	}
+}
+
+func TestRootCause(t *testing.T) {
+	err0 := errgo.WithCausef(errNotFound, someErr, "foo")
+	combined := errgo.Combine(err0, someErr)
+	tests := []struct {
+		about  string
+		err    error
+		expect error
+	}{{
+		about: "nil error",
+	}, {
+		about:  "foreign error",
+		err:    errNotFound,
+		expect: errNotFound,
+	}, {
+		about:  "masked cause",
+		err:    errgo.Notef(errgo.Mask(err0), "bar"),
+		expect: errNotFound,
+	}, {
+		about:  "standard wrapping",
+		err:    fmt.Errorf("baz: %w", errgo.Mask(err0)),
+		expect: errNotFound,
+	}, {
+		about:  "multiple errors",
+		err:    errgo.Notef(combined, "bar"),
+		expect: combined,
+	}}
+	for _, test := range tests {
+		if got := errgo.RootCause(test.err); got != test.expect {
+			t.Errorf("%s: got %#v want %#v", test.about, got, test.expect)
+		}
+	}
+}
+
+func TestBecause(t *testing.T) {
+	causeErr := errgo.New("cause error")
+	underlyingErr := errgo.New("underlying error")
+	err := errgo.Because(underlyingErr, causeErr, "100% fail")
+	checkErr(t, err, underlyingErr, "100% fail: underlying error", "[{errors_test.go: 100% fail} {errors_test.go: underlying error}]", causeErr)
+}
+
+func TestFormatArgs(t *testing.T) {
+	tests := []struct {
+		about      string
+		err        error
+		expectFmt  string
+		expectArgs []interface{}
+	}{{
+		about: "unformatted",
+		err:   errgo.New("foo"),
+	}, {
+		about:      "Newf",
+		err:        errgo.Newf("no table %q", "users"),
+		expectFmt:  "no table %q",
+		expectArgs: []interface{}{"users"},
+	}, {
+		about:      "Notef",
+		err:        errgo.Notef(someErr, "query %s took %d", "select", 3),
+		expectFmt:  "query %s took %d",
+		expectArgs: []interface{}{"select", 3},
+	}, {
+		about:      "NoteMaskf",
+		err:        errgo.NoteMaskf(someErr, errgo.Any, "query %s", "select"),
+		expectFmt:  "query %s",
+		expectArgs: []interface{}{"select"},
+	}, {
+		about:      "WithCausef",
+		err:        errgo.WithCausef(nil, someErr, "table %s", "users"),
+		expectFmt:  "table %s",
+		expectArgs: []interface{}{"users"},
+	}, {
+		about:      "NotefWithDepth",
+		err:        errgo.NotefWithDepth(0, someErr, "table %s", "users"),
+		expectFmt:  "table %s",
+		expectArgs: []interface{}{"users"},
+	}, {
+		about:      "Combinef",
+		err:        errgo.Combinef([]error{someErr}, "%d jobs", 1),
+		expectFmt:  "%d jobs",
+		expectArgs: []interface{}{1},
+	}, {
+		about: "Combine",
+		err:   errgo.Combine(someErr),
+	}, {
+		about:      "WithKindf",
+		err:        errgo.WithKindf(someErr, errgo.KindNotFound, "no user %q", "bob"),
+		expectFmt:  "no user %q",
+		expectArgs: []interface{}{"bob"},
+	}, {
+		about: "CombineClose",
+		err: func() (err error) {
+			errgo.CombineClose(&err, failCloser{}, "closing %s", "f")
+			return err
+		}(),
+		expectFmt:  "closing %s",
+		expectArgs: []interface{}{"f"},
+	}, {
+		about: "Validation.Addf",
+		err: func() error {
+			var v errgo.Validation
+			v.Addf("age", "must be at least %d", 18)
+			return v.Fields()[0]
+		}(),
+		expectFmt:  "must be at least %d",
+		expectArgs: []interface{}{18},
+	}}
+	for _, test := range tests {
+		f, args := test.err.(interface {
+			FormatArgs() (string, []interface{})
+		}).FormatArgs()
+		if f != test.expectFmt || !reflect.DeepEqual(args, test.expectArgs) {
+			t.Errorf("%s: got %q %#v", test.about, f, args)
+		}
+	}
+}
+
+func TestFormatArgsCopied(t *testing.T) {
+	args := []interface{}{"users"}
+	err := errgo.Notef(someErr, "table %s", args...)
+	args[0] = "changed"
+	if _, got := err.(*errgo.Err).FormatArgs(); got[0] != "users" {
+		t.Fatalf("arguments changed to %#v", got)
+	}
+}
+
+type causeAsErr struct {
+	code int
+}
+
+func (e *causeAsErr) Error() string {
+	return fmt.Sprintf("code %d", e.code)
+}
+
+func TestCauseAs(t *testing.T) {
+	err0 := &causeAsErr{1}
+	tests := []struct {
+		err    error
+		expect *causeAsErr
+	}{{
+		err:    err0,
+		expect: err0,
+	}, {
+		err:    errgo.Mask(err0, errgo.Any),
+		expect: err0,
+	}, {
+		err:    errgo.Notef(errgo.Mask(err0), "bar"),
+		expect: err0,
+	}, {
+		err:    errgo.WithCausef(someErr, err0, "bar"),
+		expect: err0,
+	}, {
+		err: errgo.Notef(someErr, "bar"),
+	}, {
+		err: nil,
+	}}
+	for i, test := range tests {
+		cause, ok := errgo.CauseAs[*causeAsErr](test.err)
+		if cause != test.expect || ok != (test.expect != nil) {
+			t.Errorf("test %d: got (%#v, %v) want %#v", i, cause, ok, test.expect)
+		}
+	}
+}
+
 func TestDetails(t *testing.T) {
 	if details := errgo.Details(nil); details != "[]" {
 		t.Fatalf("errgo.Details(nil) got %q want %q", details, "[]")
@@ -139,14 +329,127 @@ func TestDetails(t *testing.T) {
 	otherErr := fmt.Errorf("other")
 	checkErr(t, otherErr, nil, "other", "[{other}]", otherErr)
 
-	err0 := &embed{errgo.New("foo").(*errgo.Err)} //err TestStack#0
-	checkErr(t, err0, nil, "foo", "[{$TestStack#0$: foo}]", err0)
+	err0 := &embed{errgo.New("foo").(*errgo.Err)}
+	checkErr(t, err0, nil, "foo", "[{errors_test.go: foo}]", err0)
+
+	err1 := &embed{errgo.Notef(err0, "bar").(*errgo.Err)}
+	checkErr(t, err1, err0, "bar: foo", "[{errors_test.go: bar} {errors_test.go: foo}]", err1)
+
+	err2 := errgo.Mask(err1)
+	checkErr(t, err2, err1, "bar: foo", "[{errors_test.go: } {errors_test.go: bar} {errors_test.go: foo}]", err2)
+}
+
+func TestFormatDetailsCollapseMasks(t *testing.T) {
+	skipIfNoSourceLocations(t)
+	opts := errgo.DetailsOptions{
+		CollapseMasks: true,
+	}
+	err0 := errgo.New("foo")
+	err1 := errgo.Mask(err0)
+	err2 := errgo.Notef(err1, "bar")
+	err3 := errgo.Mask(err2)
+	err4 := errgo.Mask(err3, errgo.Any)
+	err5 := errgo.Mask(fmt.Errorf("other"))
+	err6 := errgo.Mask(err5)
+
+	tests := []struct {
+		err    error
+		expect string
+	}{{
+		err:    nil,
+		expect: "[]",
+	}, {
+		err:    err1,
+		expect: "[{errors_test.go: } {errors_test.go: foo}]",
+	}, {
+		err:    err4,
+		expect: "[{via 2 frames: errors_test.go, errors_test.go} {errors_test.go: bar} {errors_test.go: } {errors_test.go: foo}]",
+	}, {
+		err:    err6,
+		expect: "[{via 2 frames: errors_test.go, errors_test.go} {other}]",
+	}}
+	for i, test := range tests {
+		if got := errgotest.NormalizeStack(errgo.FormatDetails(test.err, opts), nil); got != test.expect {
+			t.Errorf("test %d: got %q want %q", i, got, test.expect)
+		}
+	}
+}
+
+func TestCompactDetails(t *testing.T) {
+	skipIfNoSourceLocations(t)
+	err0 := errgo.New("foo")
+	err1 := errgo.Mask(err0, errgo.Any)
+	err2 := errgo.Notef(err1, "bar")
+	err3 := errgo.Mask(err2)
+	err4 := errgo.Mask(err3, errgo.Any)
+
+	tests := []struct {
+		err    error
+		expect string
+	}{{
+		err:    nil,
+		expect: "[]",
+	}, {
+		err:    err1,
+		expect: "[{errors_test.go: foo}]",
+	}, {
+		err:    err4,
+		expect: "[{errors_test.go: } {errors_test.go: bar} {errors_test.go: foo}]",
+	}}
+	for i, test := range tests {
+		if got := errgotest.NormalizeStack(errgo.CompactDetails(test.err), nil); got != test.expect {
+			t.Errorf("test %d: got %q want %q", i, got, test.expect)
+		}
+	}
+}
+
+// sliceErr is an error type that cannot be compared with ==.
+type sliceErr []string
 
-	err1 := &embed{errgo.Notef(err0, "bar").(*errgo.Err)} //err TestStack#1
-	checkErr(t, err1, err0, "bar: foo", "[{$TestStack#1$: bar} {$TestStack#0$: foo}]", err1)
+func (e sliceErr) Error() string {
+	return strings.Join(e, ", ")
+}
+
+// richErr is an error type that reports itself as
+// equivalent to errNotFound.
+type richErr struct {
+	msg string
+}
+
+func (e *richErr) Error() string {
+	return e.msg
+}
 
-	err2 := errgo.Mask(err1) //err TestStack#2
-	checkErr(t, err2, err1, "bar: foo", "[{$TestStack#2$: } {$TestStack#1$: bar} {$TestStack#0$: foo}]", err2)
+func (e *richErr) Is(target error) bool {
+	return target == errNotFound
+}
+
+func TestCompactDetailsCauseIdentity(t *testing.T) {
+	err0 := &errgo.Err{
+		Message_: "foo",
+		Cause_:   sliceErr{"a", "b"},
+	}
+	err1 := &errgo.Err{
+		Underlying_: err0,
+		Cause_:      sliceErr{"a", "b"},
+	}
+	// Uncomparable causes cannot be shown to be the same,
+	// so the mask is kept rather than causing a panic.
+	if got := errgo.CompactDetails(err1); got != "[{} {foo}]" {
+		t.Errorf("unexpected details %q", got)
+	}
+
+	err0 = &errgo.Err{
+		Message_: "bar",
+		Cause_:   &richErr{"rich not found"},
+	}
+	err1 = &errgo.Err{
+		Underlying_: err0,
+		Cause_:      errNotFound,
+	}
+	if got := errgo.CompactDetails(err1); got != "[{bar}]" {
+		t.Errorf("unexpected details %q", got)
+	}
 }
 
 func TestMatch(t *testing.T) {
@@ -202,7 +505,55 @@ func TestLocation(t *testing.T) {
 	}
 }
 
+// inlinedNew and inlinedOuter are small enough to be
+// inlined by the compiler.
+func inlinedNew() error {
+	return errgo.NewWithDepth(1, "inlined")
+}
+
+func inlinedOuter() error {
+	return inlinedMiddle()
+}
+
+func inlinedMiddle() error {
+	return errgo.NewWithDepth(2, "inlined")
+}
+
+// TestLocationStringsShared checks that errors created at the
+// same site share the backing data of their file and function
+// names, which point into the binary's symbol table, so memory
+// use does not grow with the number of errors created.
+func TestLocationStringsShared(t *testing.T) {
+	var errs [2]*errgo.Err
+	for i := range errs {
+		errs[i] = errgo.New("foo").(*errgo.Err)
+	}
+	if unsafe.StringData(errs[0].Location().File) != unsafe.StringData(errs[1].Location().File) {
+		t.Errorf("file names are not shared")
+	}
+	if unsafe.StringData(errs[0].Function()) != unsafe.StringData(errs[1].Function()) {
+		t.Errorf("function names are not shared")
+	}
+}
+
+func TestInlinedLocation(t *testing.T) {
+	err0 := inlinedNew()
+	checkErr(t, err0, nil, "inlined", "[{errors_test.go: inlined}]", err0)
+
+	err1 := inlinedOuter()
+	checkErr(t, err1, nil, "inlined", "[{errors_test.go: inlined}]", err1)
+
+	skipIfNoSourceLocations(t)
+	want := "github.com/juju/errgo_test.TestInlinedLocation"
+	for _, err := range []error{err0, err1} {
+		if got := err.(errgo.Functioner).Function(); got != want {
+			t.Errorf("got function %q want %q", got, want)
+		}
+	}
+}
+
 func checkErr(t *testing.T, err, underlying error, msg string, details string, cause error) {
+	t.Helper()
 	if err == nil {
 		t.Fatalf("err is nil; want %q", msg)
 	}
@@ -216,59 +567,150 @@ func checkErr(t *testing.T, err, underlying error, msg string, details string, c
 	} else if underlying != nil {
 		t.Fatalf("no underlying error found; want %q", underlying)
 	}
-	if errgo.Cause(err) != cause {
-		t.Fatalf("unexpected cause: want %#v; got %#v", cause, errgo.Cause(err))
+	errgotest.AssertCause(t, err, cause)
+	if !sourceLocations {
+		// The details hold locations.
+		return
 	}
-	wantDetails := replaceLocations(details)
-	if gotDetails := errgo.Details(err); gotDetails != wantDetails {
-		t.Fatalf("unexpected details: want %q; got %q", wantDetails, gotDetails)
+	if gotDetails := errgotest.NormalizeStack(errgo.Details(err), nil); gotDetails != details {
+		t.Fatalf("unexpected details: want %q; got %q", details, gotDetails)
 	}
 }
 
-func replaceLocations(s string) string {
-	t := ""
-	for {
-		i := strings.Index(s, "$")
-		if i == -1 {
-			break
+func deepChain(depth int) error {
+	err := errgo.New("root")
+	for i := 0; i < depth; i++ {
+		if i%2 == 0 {
+			err = errgo.Mask(err, errgo.Any)
+		} else {
+			err = errgo.Notef(err, "layer %d", i)
 		}
-		t += s[0:i]
-		s = s[i+1:]
-		i = strings.Index(s, "$")
-		if i == -1 {
-			panic("no second $")
-		}
-		t += location(s[0:i]).String()
-		s = s[i+1:]
 	}
-	t += s
-	return t
+	return err
+}
+
+func BenchmarkDetails(b *testing.B) {
+	for _, depth := range []int{1, 10, 100} {
+		err := deepChain(depth)
+		b.Run(fmt.Sprintf("depth-%d", depth), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				errgo.Details(err)
+			}
+		})
+	}
 }
 
-func location(tag string) errgo.Location {
-	line, ok := tagToLine[tag]
-	if !ok {
-		panic(fmt.Errorf("tag %q not found", tag))
+func BenchmarkFormatDetailsCollapseMasks(b *testing.B) {
+	err := deepChain(100)
+	opts := errgo.DetailsOptions{
+		CollapseMasks: true,
 	}
-	return errgo.Location{
-		File: filename,
-		Line: line,
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		errgo.FormatDetails(err, opts)
 	}
 }
 
-var tagToLine = make(map[string]int)
-var filename string
+func BenchmarkNew(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		errgo.New("foo")
+	}
+}
 
-func init() {
-	data, err := ioutil.ReadFile("errors_test.go")
-	if err != nil {
-		panic(err)
+func TestNewAllocs(t *testing.T) {
+	// Only the error itself should be allocated.
+	if n := testing.AllocsPerRun(100, func() {
+		errgo.New("foo")
+	}); n > 1 {
+		t.Fatalf("New made %v allocations", n)
+	}
+}
+
+func TestErrComparable(t *testing.T) {
+	errgo.SetFrameDepth(3)
+	defer errgo.SetFrameDepth(0)
+	err := errgo.Newf("foo %d", 1).(*errgo.Err)
+	copied := *err
+	if copied != *err {
+		t.Fatalf("copy of error not equal to original")
+	}
+	if other := errgo.Newf("foo %d", 1).(*errgo.Err); *other == *err {
+		t.Fatalf("distinct errors are equal")
+	}
+}
+
+func BenchmarkMask(b *testing.B) {
+	err := errgo.New("foo")
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		errgo.Mask(err, errgo.Any)
 	}
-	lines := strings.Split(string(data), "\n")
-	for i, line := range lines {
-		if j := strings.Index(line, "//err "); j >= 0 {
-			tagToLine[line[j+len("//err "):]] = i + 1
+}
+
+func TestMessageLayout(t *testing.T) {
+	defer func() {
+		errgo.MessageSeparator = ": "
+		errgo.UnderlyingFirst = false
+	}()
+	err := errgo.Notef(errgo.Combine(errgo.Notef(errNotFound, "one"), errgo.New("two")), "cannot get user")
+	tests := []struct {
+		separator       string
+		underlyingFirst bool
+		expect          string
+	}{{
+		separator: ": ",
+		expect:    "cannot get user: one: not found; two",
+	}, {
+		separator: " — ",
+		expect:    "cannot get user — one — not found; two",
+	}, {
+		separator:       ": ",
+		underlyingFirst: true,
+		expect:          "not found: one; two: cannot get user",
+	}}
+	for i, test := range tests {
+		errgo.MessageSeparator = test.separator
+		errgo.UnderlyingFirst = test.underlyingFirst
+		if got := err.Error(); got != test.expect {
+			t.Errorf("test %d: got %q want %q", i, got, test.expect)
+		}
+	}
+	if got := (&errgo.Err{}).Error(); got != "<no error>" {
+		t.Errorf("unexpected message for empty error %q", got)
+	}
+}
+
+func TestDeduplicateMessages(t *testing.T) {
+	defer func() {
+		errgo.DeduplicateMessages = false
+	}()
+	inner := errgo.Notef(errgo.New("connection refused"), "timeout")
+	tests := []struct {
+		about  string
+		err    error
+		expect string
+	}{{
+		about:  "consecutive annotations",
+		err:    errgo.Notef(inner, "timeout"),
+		expect: "timeout: connection refused",
+	}, {
+		about:  "annotations separated by a mask",
+		err:    errgo.Notef(errgo.Mask(errgo.Notef(inner, "timeout")), "timeout"),
+		expect: "timeout: connection refused",
+	}, {
+		about:  "different annotations",
+		err:    errgo.Notef(errgo.Notef(inner, "retrying"), "timeout"),
+		expect: "timeout: retrying: timeout: connection refused",
+	}}
+	if got, want := errgo.Notef(inner, "timeout").Error(), "timeout: timeout: connection refused"; got != want {
+		t.Fatalf("unexpected message without deduplication %q", got)
+	}
+	errgo.DeduplicateMessages = true
+	for i, test := range tests {
+		if got := test.err.Error(); got != test.expect {
+			t.Errorf("test %d (%s): got %q want %q", i, test.about, got, test.expect)
 		}
 	}
-	_, filename, _, _ = runtime.Caller(0)
 }
@@ -0,0 +1,21 @@
+// The errstats package publishes the error counts recorded
+// by errgo.Stats as expvar variables. It is separate from
+// errgo because importing expvar registers the /debug/vars
+// handler with http.DefaultServeMux.
+package errstats
+
+import (
+	"expvar"
+
+	"github.com/juju/errgo"
+)
+
+// Publish publishes the current counts recorded by s (see
+// errgo.Stats.Snapshot) as an expvar variable with the given
+// name. Like expvar.Publish, it panics if the name is
+// already in use.
+func Publish(name string, s *errgo.Stats) {
+	expvar.Publish(name, expvar.Func(func() interface{} {
+		return s.Snapshot()
+	}))
+}
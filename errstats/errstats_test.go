@@ -0,0 +1,33 @@
+package errstats_test
+
+import (
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	"github.com/juju/errgo"
+	"github.com/juju/errgo/errstats"
+)
+
+// publishCount makes the published names unique
+// when the test is run several times.
+var publishCount atomic.Int64
+
+func TestPublish(t *testing.T) {
+	var s errgo.Stats
+	s.Record(errgo.New("foo"))
+	s.Record(errgo.New("bar"))
+	name := fmt.Sprintf("errstats-test-%d", publishCount.Add(1))
+	errstats.Publish(name, &s)
+	s.Record(errgo.New("baz"))
+
+	var published errgo.StatsSnapshot
+	if err := json.Unmarshal([]byte(expvar.Get(name).String()), &published); err != nil {
+		t.Fatal(err)
+	}
+	if published.Total != 3 {
+		t.Fatalf("unexpected published total %d", published.Total)
+	}
+}
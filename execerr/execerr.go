@@ -0,0 +1,105 @@
+// The execerr package describes the failures of
+// external commands run with the os/exec package.
+package execerr
+
+import (
+	"errors"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/juju/errgo"
+)
+
+// maxStderr holds the maximum number of bytes of standard
+// error output recorded by Wrap.
+const maxStderr = 2048
+
+// Error holds a description of the failure of an
+// external command.
+type Error struct {
+	errgo.Err
+
+	// Command_ holds the command line of the command.
+	Command_ []string
+
+	// ExitCode_ holds the exit code of the command, or -1 if
+	// the command did not exit normally.
+	ExitCode_ int
+
+	// Stderr_ holds the end of the standard error
+	// output of the command.
+	Stderr_ string
+}
+
+// Command returns the command line of the command.
+func (e *Error) Command() []string {
+	return e.Command_
+}
+
+// ExitCode returns the exit code of the command, or -1 if
+// the command did not exit normally.
+func (e *Error) ExitCode() int {
+	return e.ExitCode_
+}
+
+// Stderr returns the end of the standard error output of the
+// command, with surrounding white space removed.
+func (e *Error) Stderr() string {
+	return e.Stderr_
+}
+
+// Wrap returns an error that wraps the given error returned
+// from running cmd. The message of the returned error includes
+// the command line, and the exit code and standard error output
+// of the command are available from the Error methods. Only the
+// last part of stderr is recorded if it is long.
+//
+// The cause of err, usually an *exec.ExitError, is preserved as the
+// cause of the returned error.
+//
+// If err is nil, Wrap returns nil.
+func Wrap(err error, cmd *exec.Cmd, stderr []byte) error {
+	if err == nil {
+		return nil
+	}
+	exitCode := -1
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		exitCode = exitErr.ExitCode()
+	}
+	newErr := &Error{
+		Err: errgo.Err{
+			Message_:    "running " + commandLine(cmd.Args),
+			Underlying_: err,
+			Cause_:      errgo.Cause(err),
+		},
+		Command_:  cmd.Args,
+		ExitCode_: exitCode,
+		Stderr_:   trimStderr(stderr),
+	}
+	newErr.SetLocation(1)
+	return errgo.Created(newErr)
+}
+
+// commandLine returns the given arguments joined by spaces,
+// with any argument that is empty or contains white space
+// or quotes being quoted.
+func commandLine(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		if arg == "" || strings.ContainsAny(arg, " \t\n\"'") {
+			arg = strconv.Quote(arg)
+		}
+		quoted[i] = arg
+	}
+	return strings.Join(quoted, " ")
+}
+
+func trimStderr(stderr []byte) string {
+	s := strings.TrimSpace(string(stderr))
+	if len(s) > maxStderr {
+		s = "..." + s[len(s)-maxStderr:]
+	}
+	return s
+}
@@ -0,0 +1,51 @@
+package execerr_test
+
+import (
+	"bytes"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/juju/errgo"
+	"github.com/juju/errgo/execerr"
+)
+
+func TestWrap(t *testing.T) {
+	if err := execerr.Wrap(nil, exec.Command("true"), nil); err != nil {
+		t.Fatalf("unexpected error %#v", err)
+	}
+	cmd := exec.Command("sh", "-c", "echo oops >&2; exit 3")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	runErr := cmd.Run()
+	err := execerr.Wrap(runErr, cmd, stderr.Bytes())
+	if got, want := err.Error(), `running sh -c "echo oops >&2; exit 3": exit status 3`; got != want {
+		t.Fatalf("unexpected message %q; want %q", got, want)
+	}
+	if loc, ok := errgo.OutermostLocation(err); ok && loc.File != errgo.RestrictedLocationLabel && !strings.HasSuffix(loc.File, "/execerr_test.go") {
+		t.Fatalf("unexpected location %v", loc)
+	}
+	if got := errgo.Underlying(err); got != runErr {
+		t.Fatalf("unexpected underlying error %#v", got)
+	}
+	eerr := err.(*execerr.Error)
+	if eerr.ExitCode() != 3 {
+		t.Errorf("unexpected exit code %d", eerr.ExitCode())
+	}
+	if eerr.Stderr() != "oops" {
+		t.Errorf("unexpected stderr %q", eerr.Stderr())
+	}
+	if _, ok := errgo.Cause(err).(*exec.ExitError); !ok {
+		t.Errorf("unexpected cause %#v", errgo.Cause(err))
+	}
+
+	cmd = exec.Command("/non-existent-command")
+	err = execerr.Wrap(cmd.Run(), cmd, []byte(strings.Repeat("x", 3000)+"\n"))
+	eerr = err.(*execerr.Error)
+	if eerr.ExitCode() != -1 {
+		t.Errorf("unexpected exit code %d", eerr.ExitCode())
+	}
+	if want := "..." + strings.Repeat("x", 2048); eerr.Stderr() != want {
+		t.Errorf("unexpected stderr %q", eerr.Stderr())
+	}
+}
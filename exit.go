@@ -0,0 +1,91 @@
+package errgo
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// ExitVerbose controls the output of Exit. If it is true,
+// Exit prints the details of the error (see Details) as well
+// as its message.
+var ExitVerbose = false
+
+// These variables are changed when testing.
+var (
+	osExit           = os.Exit
+	stderr io.Writer = os.Stderr
+)
+
+// exitCodeErr holds an error associated with an exit code.
+type exitCodeErr struct {
+	Err
+	code int
+}
+
+// WithExitCode returns an error that wraps err and associates it
+// with the given exit code, as returned by ExitCode.
+// The returned error has the same message and cause as err.
+// If err is nil, WithExitCode returns nil.
+func WithExitCode(err error, code int) error {
+	if err == nil {
+		return nil
+	}
+	newErr := &exitCodeErr{
+		Err: Err{
+			Underlying_: err,
+			Cause_:      Cause(err),
+		},
+		code: code,
+	}
+	newErr.SetLocation(1)
+	return Created(newErr)
+}
+
+// ExitCode returns the exit code associated with err by the
+// outermost call to WithExitCode in its error chain, or else the
+// exit code of the kind of err in the taxonomy (see SetTaxonomy).
+// It returns 0 if err is nil and 1 if no exit code has been
+// associated with err.
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	found := Find(err, func(err error) bool {
+		_, ok := err.(*exitCodeErr)
+		return ok
+	})
+	if found != nil {
+		return found.(*exitCodeErr).code
+	}
+	if info, _ := kindInfo(err); info.ExitCode != 0 {
+		return info.ExitCode
+	}
+	return 1
+}
+
+// Exit exits the program with the exit code returned by
+// ExitCode(err). If err is not nil, its message is first
+// printed to standard error, prefixed with the program name,
+// followed by a line referring to its documentation URL, if
+// any (see DocURL), and by its details if ExitVerbose is true.
+//
+// It is intended to be used at the end of a main function,
+// for example:
+//
+//	func main() {
+//		errgo.Exit(run())
+//	}
+func Exit(err error) {
+	if err != nil {
+		fmt.Fprintf(stderr, "%s: %v\n", filepath.Base(os.Args[0]), err)
+		if url := DocURL(err); url != "" {
+			fmt.Fprintf(stderr, "see %s\n", url)
+		}
+		if ExitVerbose {
+			fmt.Fprintf(stderr, "%s\n", Details(err))
+		}
+	}
+	osExit(ExitCode(err))
+}
@@ -0,0 +1,87 @@
+package errgo_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/juju/errgo"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		err    error
+		expect int
+	}{{
+		err:    nil,
+		expect: 0,
+	}, {
+		err:    someErr,
+		expect: 1,
+	}, {
+		err:    errgo.WithExitCode(someErr, 2),
+		expect: 2,
+	}, {
+		err:    errgo.Notef(errgo.WithExitCode(errgo.WithExitCode(someErr, 3), 4), "foo"),
+		expect: 4,
+	}}
+	for i, test := range tests {
+		if code := errgo.ExitCode(test.err); code != test.expect {
+			t.Errorf("test %d: got %d want %d", i, code, test.expect)
+		}
+	}
+	if err := errgo.WithExitCode(nil, 2); err != nil {
+		t.Errorf("unexpected error %#v", err)
+	}
+}
+
+func TestWithExitCodePreservesError(t *testing.T) {
+	err0 := errgo.WithCausef(nil, errNotFound, "foo")
+	err := errgo.WithExitCode(err0, 2)
+	checkErr(t, err, err0, "foo", "[{exit_test.go: } {exit_test.go: foo}]", errNotFound)
+}
+
+func TestExit(t *testing.T) {
+	prog := filepath.Base(os.Args[0])
+	err := errgo.WithExitCode(errgo.New("failed"), 3)
+	tests := []struct {
+		err     error
+		verbose bool
+		code    int
+		output  string
+	}{{
+		err:  nil,
+		code: 0,
+	}, {
+		err:    err,
+		code:   3,
+		output: prog + ": failed\n",
+	}, {
+		err:     err,
+		verbose: true,
+		code:    3,
+		output:  prog + ": failed\n" + errgo.Details(err) + "\n",
+	}, {
+		err:    errgo.WithDocURL(err, "https://docs.example.com/errors/E1234"),
+		code:   3,
+		output: prog + ": failed\nsee https://docs.example.com/errors/E1234\n",
+	}}
+	defer func(verbose bool) {
+		errgo.ExitVerbose = verbose
+	}(errgo.ExitVerbose)
+	for i, test := range tests {
+		var buf bytes.Buffer
+		code := -1
+		restore := errgo.PatchExit(func(c int) { code = c }, &buf)
+		errgo.ExitVerbose = test.verbose
+		errgo.Exit(test.err)
+		restore()
+		if code != test.code {
+			t.Errorf("test %d: got exit code %d want %d", i, code, test.code)
+		}
+		if buf.String() != test.output {
+			t.Errorf("test %d: got output %q want %q", i, buf.String(), test.output)
+		}
+	}
+}
@@ -1,3 +1,50 @@
 package errgo
 
+import (
+	"io"
+	"time"
+)
+
 var Match = match
+
+func PatchExit(exit func(int), w io.Writer) (restore func()) {
+	oldExit, oldStderr := osExit, stderr
+	osExit, stderr = exit, w
+	return func() {
+		osExit, stderr = oldExit, oldStderr
+	}
+}
+
+func ResetFactory() {
+	factoryMu.Lock()
+	defer factoryMu.Unlock()
+	factory.Store(Factory(nil))
+}
+
+var FuncPackage = funcPackage
+
+func ResetSkipPackages() {
+	skippedPkgsMu.Lock()
+	defer skippedPkgsMu.Unlock()
+	skippedPkgs.Store(map[string]bool{})
+}
+
+func SuppressorLen(s *Suppressor) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.seen)
+}
+
+func ResetFrameDepth() {
+	frameDepth = 1
+}
+
+func PatchNow(f func() time.Time) (restore func()) {
+	oldNow := now
+	now = f
+	return func() {
+		now = oldNow
+	}
+}
+
+const Minimal = minimal
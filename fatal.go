@@ -0,0 +1,77 @@
+package errgo
+
+// FatalPolicy is used by IsFatal to classify errors that have
+// not been marked with MarkFatal or MarkTransient. It is called
+// with the error passed to IsFatal. By default it is nil and
+// unmarked errors are considered transient. Programs may set it,
+// for example, to treat certain kinds of error (see Classify)
+// as fatal:
+//
+//	errgo.FatalPolicy = func(err error) bool {
+//		return errgo.Classify(err) == errgo.KindPermission
+//	}
+//
+// The policy is consulted each time IsFatal is called, so it
+// applies to errors created before it was set. It should be set
+// before IsFatal is first called, usually during program
+// initialization.
+var FatalPolicy func(err error) bool
+
+// fatalErr holds an error marked as fatal or transient.
+type fatalErr struct {
+	Err
+	fatal bool
+}
+
+// MarkFatal returns an error that wraps err and marks it as fatal:
+// a failure that will not go away by itself, such as a configuration
+// error, so that resilience mechanisms such as circuit breakers can
+// stop trying immediately rather than counting it as an ordinary
+// failure. The returned error has the same message and cause as err.
+// If err is nil, MarkFatal returns nil.
+func MarkFatal(err error) error {
+	return markFatal(err, true)
+}
+
+// MarkTransient is like MarkFatal but marks err as
+// transient, overriding any mark further down its chain
+// and the classification of FatalPolicy.
+func MarkTransient(err error) error {
+	return markFatal(err, false)
+}
+
+func markFatal(err error, fatal bool) error {
+	if err == nil {
+		return nil
+	}
+	newErr := &fatalErr{
+		Err: Err{
+			Underlying_: err,
+			Cause_:      Cause(err),
+		},
+		fatal: fatal,
+	}
+	newErr.SetLocation(2)
+	return Created(newErr)
+}
+
+// IsFatal reports whether err is fatal. The outermost mark made
+// by MarkFatal or MarkTransient in the chain of err is used;
+// if there is none, the result of FatalPolicy is returned,
+// or false if it is nil. IsFatal returns false if err is nil.
+//
+// Retry does not retry fatal errors unless its policy
+// specifies otherwise.
+func IsFatal(err error) bool {
+	if err == nil {
+		return false
+	}
+	found := Find(err, func(err error) bool {
+		_, ok := err.(*fatalErr)
+		return ok
+	})
+	if found != nil {
+		return found.(*fatalErr).fatal
+	}
+	return FatalPolicy != nil && FatalPolicy(err)
+}
@@ -0,0 +1,60 @@
+package errgo_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/juju/errgo"
+)
+
+func TestIsFatal(t *testing.T) {
+	fatal := errgo.MarkFatal(errgo.WithCausef(nil, errNotFound, "foo"))
+	checkErr(t, fatal, fatal.(errgo.Wrapper).Underlying(), "foo", "[{fatal_test.go: } {fatal_test.go: foo}]", errNotFound)
+
+	tests := []struct {
+		about  string
+		err    error
+		expect bool
+	}{{
+		about: "nil error",
+	}, {
+		about: "unmarked error",
+		err:   someErr,
+	}, {
+		about:  "fatal error",
+		err:    errgo.Notef(fatal, "bar"),
+		expect: true,
+	}, {
+		about: "transient error",
+		err:   errgo.MarkTransient(fatal),
+	}, {
+		about:  "policy",
+		err:    errgo.Mask(errQuota, errgo.Any),
+		expect: true,
+	}}
+	errgo.FatalPolicy = func(err error) bool {
+		return errgo.Cause(err) == errQuota
+	}
+	defer func() {
+		errgo.FatalPolicy = nil
+	}()
+	for i, test := range tests {
+		if got := errgo.IsFatal(test.err); got != test.expect {
+			t.Errorf("test %d (%s): got %v want %v", i, test.about, got, test.expect)
+		}
+	}
+	if errgo.MarkFatal(nil) != nil || errgo.MarkTransient(nil) != nil {
+		t.Errorf("unexpected error for nil")
+	}
+}
+
+func TestRetryFatal(t *testing.T) {
+	n := 0
+	errgo.Retry(context.Background(), errgo.RetryPolicy{Attempts: 3}, func() error {
+		n++
+		return errgo.MarkFatal(someErr)
+	})
+	if n != 1 {
+		t.Fatalf("unexpected attempt count %d", n)
+	}
+}
@@ -0,0 +1,35 @@
+package errgo
+
+import (
+	"fmt"
+	"hash/fnv"
+)
+
+// Fingerprint returns a short string that identifies the
+// source of err, so that occurrences of the same error can be
+// grouped together. It is derived from the locations recorded
+// in the error chain (see Locationer) and the messages of any
+// errors that have no location. Messages of errors with
+// locations are ignored, because they often contain values
+// that vary between occurrences. Locations holding
+// RestrictedLocationLabel are numbered in the order the
+// errors were created, so they are treated as if there were
+// no location.
+//
+// Fingerprint returns the empty string if err is nil.
+func Fingerprint(err error) string {
+	if err == nil {
+		return ""
+	}
+	h := fnv.New64a()
+	for _, err := range links(err) {
+		if lerr, ok := err.(Locationer); ok {
+			if loc := lerr.Location(); loc.IsSet() && loc.File != RestrictedLocationLabel {
+				fmt.Fprintf(h, "%s\x00", loc)
+				continue
+			}
+		}
+		fmt.Fprintf(h, "%s\x00", message(err))
+	}
+	return fmt.Sprintf("%016x", h.Sum64())
+}
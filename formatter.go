@@ -0,0 +1,185 @@
+package errgo
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var formatters = struct {
+	mu           sync.RWMutex
+	types        map[reflect.Type]bool
+	funcs        []func(error) (string, bool)
+	fieldTypes   map[reflect.Type]bool
+	fields       []func(error) []DetailField
+	wrapperTypes map[reflect.Type]bool
+	wrappers     []func(error) (string, error, bool)
+}{
+	types:        make(map[reflect.Type]bool),
+	fieldTypes:   make(map[reflect.Type]bool),
+	wrapperTypes: make(map[reflect.Type]bool),
+}
+
+// RegisterFormatter registers a function that formats errors of
+// type T for Details. When an error in the chain has type T (or
+// implements T, if T is an interface type), Details uses the result
+// of format in place of the error's message, so that errors can
+// render information beyond their Error string. Formatters are
+// tried in the order they were registered.
+//
+// If RegisterFormatter is called twice for the same type or
+// if format is nil, it panics.
+func RegisterFormatter[T error](format func(T) string) {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	formatters.mu.Lock()
+	defer formatters.mu.Unlock()
+	if format == nil {
+		panic("errgo: RegisterFormatter format is nil")
+	}
+	if formatters.types[t] {
+		panic("errgo: RegisterFormatter called twice for type " + t.String())
+	}
+	formatters.types[t] = true
+	formatters.funcs = append(formatters.funcs, func(err error) (string, bool) {
+		if err, ok := err.(T); ok {
+			return format(err), true
+		}
+		return "", false
+	})
+}
+
+// format returns the text of err as formatted by the
+// first matching registered formatter, and reports whether
+// there was one.
+func format(err error) (string, bool) {
+	// The formatters are called without the lock held,
+	// as they may format errors themselves.
+	formatters.mu.RLock()
+	funcs := formatters.funcs
+	formatters.mu.RUnlock()
+	for _, f := range funcs {
+		if s, ok := f(err); ok {
+			return s, true
+		}
+	}
+	return "", false
+}
+
+// DetailField holds a named value shown by Details
+// alongside the message of an error.
+type DetailField struct {
+	Name  string
+	Value string
+}
+
+// RegisterDetailFields registers a function that returns extra
+// diagnostic fields for errors of type T, such as the status code
+// and an excerpt of the body of an HTTP error, so that they are
+// shown by Details (and GoString) without this package depending on
+// the package defining T. When an error in the chain has type T (or
+// implements T, if T is an interface type), the fields are shown
+// after its message as name=value pairs, with values quoted when
+// they contain spaces or other special characters, for example:
+//
+//	[{client.go:40: request failed status=503 body="service unavailable"}]
+//
+// The fields of all matching registrations are shown, in the
+// order they were registered.
+//
+// If RegisterDetailFields is called twice for the same
+// type or if fields is nil, it panics.
+func RegisterDetailFields[T error](fields func(T) []DetailField) {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	formatters.mu.Lock()
+	defer formatters.mu.Unlock()
+	if fields == nil {
+		panic("errgo: RegisterDetailFields fields is nil")
+	}
+	if formatters.fieldTypes[t] {
+		panic("errgo: RegisterDetailFields called twice for type " + t.String())
+	}
+	formatters.fieldTypes[t] = true
+	formatters.fields = append(formatters.fields, func(err error) []DetailField {
+		if err, ok := err.(T); ok {
+			return fields(err)
+		}
+		return nil
+	})
+}
+
+// writeDetailFields writes the fields registered
+// for err with RegisterDetailFields to b, each
+// preceded by a space.
+func writeDetailFields(b *strings.Builder, err error) {
+	formatters.mu.RLock()
+	fields := formatters.fields
+	formatters.mu.RUnlock()
+	for _, f := range fields {
+		for _, field := range f(err) {
+			b.WriteByte(' ')
+			b.WriteString(field.Name)
+			b.WriteByte('=')
+			if needsQuote(field.Value) {
+				b.WriteString(strconv.Quote(field.Value))
+			} else {
+				b.WriteString(field.Value)
+			}
+		}
+	}
+}
+
+// needsQuote reports whether s must be quoted
+// to be shown unambiguously as a field value.
+func needsQuote(s string) bool {
+	return s == "" || strings.ContainsFunc(s, func(r rune) bool {
+		return r <= ' ' || r == '"' || r == '=' || r == '{' || r == '}' || r == '[' || r == ']' || r >= 0x7f
+	})
+}
+
+// RegisterWrapper registers a function that splits errors of type
+// T, which do not implement Wrapper, into their own message and the
+// next error in their chain, so that chains built by other packages,
+// such as golang.org/x/xerrors (see the xerr package), are shown
+// link by link by Details. When an error in the chain has type T
+// (or implements T, if T is an interface type), the message returned
+// by split is used in place of its Error string and the returned
+// error is treated as its underlying error. Wrappers are tried in
+// the order they were registered.
+//
+// If RegisterWrapper is called twice for the same type or
+// if split is nil, it panics.
+func RegisterWrapper[T error](split func(T) (msg string, next error)) {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	formatters.mu.Lock()
+	defer formatters.mu.Unlock()
+	if split == nil {
+		panic("errgo: RegisterWrapper split is nil")
+	}
+	if formatters.wrapperTypes[t] {
+		panic("errgo: RegisterWrapper called twice for type " + t.String())
+	}
+	formatters.wrapperTypes[t] = true
+	formatters.wrappers = append(formatters.wrappers, func(err error) (string, error, bool) {
+		if err, ok := err.(T); ok {
+			msg, next := split(err)
+			return msg, next, true
+		}
+		return "", nil, false
+	})
+}
+
+// splitWrapped returns the message and next error of err
+// as split by the first matching function registered with
+// RegisterWrapper, and reports whether there was one.
+func splitWrapped(err error) (string, error, bool) {
+	formatters.mu.RLock()
+	wrappers := formatters.wrappers
+	formatters.mu.RUnlock()
+	for _, f := range wrappers {
+		if msg, next, ok := f(err); ok {
+			return msg, next, true
+		}
+	}
+	return "", nil, false
+}
@@ -0,0 +1,215 @@
+package errgo_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/juju/errgo"
+)
+
+type responseErr struct {
+	status int
+	body   string
+}
+
+func (e *responseErr) Error() string {
+	return fmt.Sprintf("bad response status %d", e.status)
+}
+
+type wrappedResponseErr struct {
+	errgo.Err
+	url string
+}
+
+func init() {
+	errgo.RegisterFormatter(func(e *responseErr) string {
+		return fmt.Sprintf("status %d body %q", e.status, e.body)
+	})
+	errgo.RegisterFormatter(func(e *wrappedResponseErr) string {
+		return "GET " + e.url
+	})
+}
+
+func TestRegisterFormatter(t *testing.T) {
+	err0 := &responseErr{status: 500, body: "oops"}
+	err1 := &wrappedResponseErr{
+		Err: errgo.Err{
+			Underlying_: err0,
+		},
+		url: "http://example.com",
+	}
+	err1.SetLocation(0)
+	err2 := errgo.Notef(err1, "fetch")
+
+	checkErr(t, err2, err1, "fetch: bad response status 500",
+		`[{formatter_test.go: fetch} {formatter_test.go: GET http://example.com} {status 500 body "oops"}]`,
+		err2)
+}
+
+type lazyErr struct{}
+
+func (*lazyErr) Error() string {
+	return "lazy"
+}
+
+type lazyFieldErr struct{}
+
+func (*lazyFieldErr) Error() string {
+	return "lazy field"
+}
+
+var lazyOnce sync.Once
+
+func init() {
+	errgo.RegisterFormatter(func(e *lazyErr) string {
+		// Formatters may register other formatting
+		// functions and format errors themselves.
+		lazyOnce.Do(func() {
+			errgo.RegisterDetailFields(func(e *lazyFieldErr) []errgo.DetailField {
+				return []errgo.DetailField{{Name: "lazy", Value: "yes"}}
+			})
+		})
+		return "lazy " + errgo.Details(&lazyFieldErr{})
+	})
+}
+
+func TestFormatterReentrant(t *testing.T) {
+	got := errgo.Details(&lazyErr{})
+	if want := "[{lazy [{lazy field lazy=yes}]}]"; got != want {
+		t.Fatalf("got %q want %q", got, want)
+	}
+}
+
+func TestRegisterFormatterPanics(t *testing.T) {
+	tests := []struct {
+		about  string
+		f      func()
+		expect string
+	}{{
+		about: "duplicate",
+		f: func() {
+			errgo.RegisterFormatter(func(e *responseErr) string { return "" })
+		},
+		expect: "errgo: RegisterFormatter called twice for type *errgo_test.responseErr",
+	}, {
+		about: "nil",
+		f: func() {
+			errgo.RegisterFormatter[*errgo.Err](nil)
+		},
+		expect: "errgo: RegisterFormatter format is nil",
+	}, {
+		about: "duplicate wrapper",
+		f: func() {
+			errgo.RegisterWrapper(func(e *prefixErr) (string, error) { return "", nil })
+		},
+		expect: "errgo: RegisterWrapper called twice for type *errgo_test.prefixErr",
+	}, {
+		about: "nil wrapper",
+		f: func() {
+			errgo.RegisterWrapper[*errgo.Err](nil)
+		},
+		expect: "errgo: RegisterWrapper split is nil",
+	}}
+	for _, test := range tests {
+		func() {
+			defer func() {
+				if r := recover(); r != test.expect {
+					t.Errorf("%s: got panic %v want %q", test.about, r, test.expect)
+				}
+			}()
+			test.f()
+		}()
+	}
+}
+
+type httpErr struct {
+	status int
+	body   string
+}
+
+func (e *httpErr) Error() string {
+	return "request failed"
+}
+
+func init() {
+	errgo.RegisterDetailFields(func(e *httpErr) []errgo.DetailField {
+		return []errgo.DetailField{
+			{Name: "status", Value: fmt.Sprint(e.status)},
+			{Name: "body", Value: e.body},
+		}
+	})
+}
+
+func TestRegisterDetailFields(t *testing.T) {
+	err0 := &httpErr{status: 503, body: "service unavailable"}
+	err1 := errgo.Notef(err0, "fetch")
+	checkErr(t, err1, err0, "fetch: request failed",
+		`[{formatter_test.go: fetch} {request failed status=503 body="service unavailable"}]`,
+		err1)
+
+	err2 := &httpErr{status: 404}
+	if got, want := errgo.Details(err2), `[{request failed status=404 body=""}]`; got != want {
+		t.Fatalf("got %q want %q", got, want)
+	}
+
+	for _, test := range []struct {
+		about  string
+		f      func()
+		expect string
+	}{{
+		about: "duplicate",
+		f: func() {
+			errgo.RegisterDetailFields(func(e *httpErr) []errgo.DetailField { return nil })
+		},
+		expect: "errgo: RegisterDetailFields called twice for type *errgo_test.httpErr",
+	}, {
+		about: "nil",
+		f: func() {
+			errgo.RegisterDetailFields[*errgo.Err](nil)
+		},
+		expect: "errgo: RegisterDetailFields fields is nil",
+	}} {
+		func() {
+			defer func() {
+				if r := recover(); r != test.expect {
+					t.Errorf("%s: got panic %v want %q", test.about, r, test.expect)
+				}
+			}()
+			test.f()
+		}()
+	}
+}
+
+// prefixErr adds a prefix to the message of
+// an error without implementing errgo.Wrapper.
+type prefixErr struct {
+	prefix string
+	err    error
+}
+
+func (e *prefixErr) Error() string {
+	return e.prefix + ": " + e.err.Error()
+}
+
+func init() {
+	errgo.RegisterWrapper(func(e *prefixErr) (string, error) {
+		return e.prefix, e.err
+	})
+}
+
+func TestRegisterWrapper(t *testing.T) {
+	err0 := errgo.New("foo")
+	err1 := &prefixErr{prefix: "ctx", err: err0}
+	err2 := errgo.Notef(err1, "bar")
+
+	checkErr(t, err2, err1, "bar: ctx: foo",
+		"[{formatter_test.go: bar} {ctx} {formatter_test.go: foo}]",
+		err2)
+	if got := errgo.Message(err1); got != "ctx" {
+		t.Fatalf("unexpected message %q", got)
+	}
+	if got := errgo.Underlying(err1); got != err0 {
+		t.Fatalf("unexpected underlying error %#v", got)
+	}
+}
@@ -0,0 +1,142 @@
+package errgo
+
+import (
+	"reflect"
+	"runtime"
+	"sync"
+)
+
+// frameDepth holds the number of stack frames
+// recorded by SetLocation.
+var frameDepth = 1
+
+// SetFrameDepth sets the number of stack frames recorded when an
+// error is created. By default only one frame is recorded, the
+// location of the error (see Locationer). When n is greater than
+// one, up to n-1 frames above that location are also recorded
+// (see Framer) and are shown by Details after the location,
+// for example:
+//
+//	[{plumbing.go:20 (from handler.go:35, server.go:99): cannot get user}]
+//
+// Values of n less than one are treated as one.
+// SetFrameDepth should be called before any errors
+// are created, usually during program initialization.
+func SetFrameDepth(n int) {
+	if n < 1 {
+		n = 1
+	}
+	frameDepth = n
+}
+
+// sampler holds the function set by SetSampler.
+var sampler func(site Location) bool
+
+// SetSampler sets a function that decides whether the stack frames
+// above the location of an error (see SetFrameDepth) are recorded
+// when it is created, so that complete stacks can be recorded only
+// for some errors, such as those created in particular packages or
+// a random sample of all errors, to limit the cost of recording
+// them. The function is called with the location of the error
+// and should return true if the frames are to be recorded. The
+// location itself is always recorded.
+//
+// If sample is nil, as it is by default, the frames are
+// recorded for all errors. SetSampler has no effect unless
+// SetFrameDepth has been called with a value greater than
+// one. It should be called before any errors are created,
+// usually during program initialization.
+func SetSampler(sample func(site Location) bool) {
+	sampler = sample
+}
+
+// Framer can be implemented by any error type that records
+// the stack frames leading to its location.
+type Framer interface {
+	// Frames returns the locations of the callers of
+	// the function holding the error's location, innermost
+	// first.
+	Frames() []Location
+}
+
+// FrameFunctioner can be implemented by any error type that
+// implements Framer and records the functions holding its
+// stack frames.
+type FrameFunctioner interface {
+	// FrameFunctions returns the fully qualified names of the
+	// functions holding the frames returned by Frames, in the
+	// same order.
+	FrameFunctions() []string
+}
+
+var stacks = struct {
+	mu    sync.RWMutex
+	types map[reflect.Type]bool
+	funcs []func(error) []uintptr
+}{
+	types: make(map[reflect.Type]bool),
+}
+
+// RegisterStack registers a function that returns the stack
+// recorded by errors of type T, so that errors created by other
+// packages, such as github.com/pkg/errors (see the pkgerr package),
+// are shown with their stack by Details and ToTracePayload. When
+// an error in the chain has type T (or implements T, if T is an
+// interface type) and does not implement Locationer, the first
+// program counter returned by stack, innermost first, gives its
+// location and the remainder its frames. The program counters
+// are those returned by runtime.Callers.
+//
+// If RegisterStack is called twice for the same type or
+// if stack is nil, it panics.
+func RegisterStack[T error](stack func(T) []uintptr) {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	stacks.mu.Lock()
+	defer stacks.mu.Unlock()
+	if stack == nil {
+		panic("errgo: RegisterStack stack is nil")
+	}
+	if stacks.types[t] {
+		panic("errgo: RegisterStack called twice for type " + t.String())
+	}
+	stacks.types[t] = true
+	stacks.funcs = append(stacks.funcs, func(err error) []uintptr {
+		if err, ok := err.(T); ok {
+			return stack(err)
+		}
+		return nil
+	})
+}
+
+// stackLocations returns the source locations of the stack
+// recorded by err as arranged by RegisterStack, innermost
+// first, and the fully qualified names of the functions
+// holding them, or nil if there is none.
+func stackLocations(err error) ([]Location, []string) {
+	stacks.mu.RLock()
+	funcs := stacks.funcs
+	stacks.mu.RUnlock()
+	for _, f := range funcs {
+		pcs := f(err)
+		if len(pcs) == 0 {
+			continue
+		}
+		locs := make([]Location, 0, len(pcs))
+		names := make([]string, 0, len(pcs))
+		for _, pc := range pcs {
+			// The program counters returned by runtime.Callers
+			// are return addresses, so the call instruction
+			// is the one before.
+			pc--
+			fn := runtime.FuncForPC(pc)
+			if fn == nil {
+				continue
+			}
+			file, line := fn.FileLine(pc)
+			locs = append(locs, Location{file, line})
+			names = append(names, fn.Name())
+		}
+		return locs, names
+	}
+	return nil, nil
+}
@@ -0,0 +1,113 @@
+package errgo_test
+
+import (
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/juju/errgo"
+	"github.com/juju/errgo/errgotest"
+)
+
+func framesPlumbing() error {
+	return errgo.New("foo")
+}
+
+func framesHandler() error {
+	return framesPlumbing()
+}
+
+func TestSetFrameDepth(t *testing.T) {
+	defer errgo.ResetFrameDepth()
+	errgo.SetFrameDepth(3)
+	err := framesHandler()
+	checkErr(t, err, nil, "foo", "[{frames_test.go (from frames_test.go, frames_test.go): foo}]", err)
+	skipIfNoSourceLocations(t)
+	frames := err.(errgo.Framer).Frames()
+	funcs := err.(errgo.FrameFunctioner).FrameFunctions()
+	if len(frames) != 2 || len(funcs) != 2 || funcs[0] != "github.com/juju/errgo_test.framesHandler" {
+		t.Fatalf("unexpected frames %v in %v", frames, funcs)
+	}
+
+	errgo.SetFrameDepth(0)
+	err = framesHandler()
+	checkErr(t, err, nil, "foo", "[{frames_test.go: foo}]", err)
+	if frames := err.(errgo.Framer).Frames(); len(frames) != 0 {
+		t.Fatalf("unexpected frames %v", frames)
+	}
+}
+
+func TestSetSampler(t *testing.T) {
+	defer errgo.ResetFrameDepth()
+	defer errgo.SetSampler(nil)
+	errgo.SetFrameDepth(3)
+	var sites []errgo.Location
+	sample := false
+	errgo.SetSampler(func(site errgo.Location) bool {
+		sites = append(sites, site)
+		return sample
+	})
+
+	err := framesHandler()
+	checkErr(t, err, nil, "foo", "[{frames_test.go: foo}]", err)
+	skipIfNoSourceLocations(t)
+	if len(sites) != 1 || sites[0] != err.(errgo.Locationer).Location() {
+		t.Fatalf("unexpected sampled sites %v", sites)
+	}
+
+	sample = true
+	err = framesHandler()
+	checkErr(t, err, nil, "foo", "[{frames_test.go (from frames_test.go, frames_test.go): foo}]", err)
+
+	errgo.SetFrameDepth(1)
+	sites = nil
+	framesHandler()
+	if len(sites) != 0 {
+		t.Fatalf("sampler called without frame depth")
+	}
+}
+
+// stackErr records a stack without implementing errgo.Locationer.
+type stackErr struct {
+	pcs []uintptr
+}
+
+func (e *stackErr) Error() string {
+	return "stack error"
+}
+
+func newStackErr() error {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(1, pcs)
+	return &stackErr{pcs[:n]}
+}
+
+func init() {
+	errgo.RegisterStack(func(e *stackErr) []uintptr {
+		return e.pcs
+	})
+}
+
+func TestRegisterStack(t *testing.T) {
+	err := errgo.Notef(newStackErr(), "bar")
+	details := errgo.Details(err)
+	prefix := "[{frames_test.go: bar} {frames_test.go (from frames_test.go, "
+	if !strings.HasPrefix(errgotest.NormalizeStack(details, nil), prefix) && sourceLocations {
+		t.Fatalf("unexpected details %q", details)
+	}
+	if !strings.HasSuffix(details, "): stack error}]") {
+		t.Fatalf("details do not include stack frames: %q", details)
+	}
+	frames := errgo.ToTracePayload(err).Frames
+	if len(frames) < 2 || frames[0].Method != "github.com/juju/errgo_test.newStackErr" {
+		t.Fatalf("unexpected frames %#v", frames)
+	}
+
+	defer func() {
+		want := "errgo: RegisterStack called twice for type *errgo_test.stackErr"
+		if r := recover(); r != want {
+			t.Fatalf("got panic %v want %q", r, want)
+		}
+	}()
+	errgo.RegisterStack(func(e *stackErr) []uintptr { return nil })
+}
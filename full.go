@@ -0,0 +1,7 @@
+//go:build !errgo_minimal
+
+package errgo
+
+// minimal reports whether the package is built
+// with the errgo_minimal build tag (see minimal.go).
+const minimal = false
@@ -0,0 +1,112 @@
+// The gcloud package renders errgo errors in the structured
+// logging format understood by Google Cloud Error Reporting.
+package gcloud
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/juju/errgo"
+)
+
+// ReportedErrorEventType holds the value of the @type field that
+// marks a log entry as an error event.
+const ReportedErrorEventType = "type.googleapis.com/google.devtools.clouderrorreporting.v1beta1.ReportedErrorEvent"
+
+// ErrorEvent holds the jsonPayload of a log entry that
+// reports an error.
+type ErrorEvent struct {
+	Type           string          `json:"@type"`
+	EventTime      string          `json:"eventTime,omitempty"`
+	ServiceContext *ServiceContext `json:"serviceContext,omitempty"`
+	Message        string          `json:"message"`
+	Context        *ErrorContext   `json:"context,omitempty"`
+}
+
+// ServiceContext identifies the service that reported an error.
+type ServiceContext struct {
+	Service string `json:"service"`
+	Version string `json:"version,omitempty"`
+}
+
+// ErrorContext holds information about the
+// circumstances of an error.
+type ErrorContext struct {
+	ReportLocation *ReportLocation `json:"reportLocation,omitempty"`
+}
+
+// ReportLocation holds the source location
+// where an error was reported.
+type ReportLocation struct {
+	FilePath     string `json:"filePath"`
+	LineNumber   int    `json:"lineNumber"`
+	FunctionName string `json:"functionName"`
+}
+
+// unknownFunction is used in place of the names of
+// functions that were not recorded (see errgo.Functioner).
+const unknownFunction = "unknown"
+
+// NewErrorEvent returns an error event describing err, which
+// must not be nil, reported at the given time by the given
+// service (which may be nil).
+//
+// Error Reporting groups errors by their stack traces, so the
+// message holds the error message followed by a stack trace in
+// the format of a Go panic, synthesized from the frames of the
+// trace payload of err (see errgo.ToTracePayload), innermost
+// first. The report location is the outermost location recorded
+// in the error chain (see errgo.OutermostLocation).
+func NewErrorEvent(err error, t time.Time, service *ServiceContext) *ErrorEvent {
+	frames := errgo.ToTracePayload(err).Frames
+	event := &ErrorEvent{
+		Type:           ReportedErrorEventType,
+		ServiceContext: service,
+		Message:        message(err, frames),
+	}
+	if !t.IsZero() {
+		event.EventTime = t.UTC().Format(time.RFC3339Nano)
+	}
+	if loc, ok := errgo.OutermostLocation(err); ok {
+		function := unknownFunction
+		for _, frame := range frames {
+			if frame.File == loc.File && frame.Line == loc.Line {
+				function = functionName(frame)
+				break
+			}
+		}
+		event.Context = &ErrorContext{
+			ReportLocation: &ReportLocation{
+				FilePath:     loc.File,
+				LineNumber:   loc.Line,
+				FunctionName: function,
+			},
+		}
+	}
+	return event
+}
+
+// functionName returns the name of the function holding
+// frame, or unknownFunction if it was not recorded.
+func functionName(frame errgo.TraceFrame) string {
+	if frame.Method == "" {
+		return unknownFunction
+	}
+	return frame.Method
+}
+
+// message returns the error message followed
+// by a synthesized stack trace.
+func message(err error, frames []errgo.TraceFrame) string {
+	var buf strings.Builder
+	buf.WriteString(err.Error())
+	if len(frames) == 0 {
+		return buf.String()
+	}
+	buf.WriteString("\n\ngoroutine 1 [running]:\n")
+	for _, frame := range frames {
+		fmt.Fprintf(&buf, "%s()\n\t%s:%d\n", functionName(frame), frame.File, frame.Line)
+	}
+	return buf.String()
+}
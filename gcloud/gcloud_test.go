@@ -0,0 +1,80 @@
+package gcloud_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/juju/errgo"
+	"github.com/juju/errgo/gcloud"
+)
+
+func TestNewErrorEvent(t *testing.T) {
+	err0 := &errgo.Err{
+		Message_:  "foo",
+		Location_: errgo.Location{File: "/src/a.go", Line: 10},
+	}
+	err := &errgo.Err{
+		Message_:    "bar",
+		Underlying_: err0,
+		Location_:   errgo.Location{File: "/src/b.go", Line: 20},
+	}
+	event := gcloud.NewErrorEvent(err, time.Date(2014, 2, 3, 4, 5, 6, 0, time.UTC), &gcloud.ServiceContext{
+		Service: "api",
+		Version: "1.2",
+	})
+	data, jerr := json.Marshal(event)
+	if jerr != nil {
+		t.Fatal(jerr)
+	}
+	want := `{"@type":"type.googleapis.com/google.devtools.clouderrorreporting.v1beta1.ReportedErrorEvent",` +
+		`"eventTime":"2014-02-03T04:05:06Z",` +
+		`"serviceContext":{"service":"api","version":"1.2"},` +
+		`"message":"bar: foo\n\ngoroutine 1 [running]:\nunknown()\n\t/src/a.go:10\nunknown()\n\t/src/b.go:20\n",` +
+		`"context":{"reportLocation":{"filePath":"/src/b.go","lineNumber":20,"functionName":"unknown"}}}`
+	if string(data) != want {
+		t.Fatalf("unexpected JSON\ngot  %s\nwant %s", data, want)
+	}
+}
+
+func TestNewErrorEventFunctions(t *testing.T) {
+	err0 := &errgo.Err{
+		Message_:  "foo",
+		Location_: errgo.Location{File: "/src/a.go", Line: 10},
+		Function_: "example.com/a.Get",
+		Extra_: &errgo.Extra{
+			Frames: []errgo.Location{{File: "/src/c.go", Line: 30}},
+		},
+	}
+	err := &errgo.Err{
+		Message_:    "bar",
+		Underlying_: err0,
+		Location_:   errgo.Location{File: "/src/b.go", Line: 20},
+		Function_:   "example.com/b.(*T).Serve",
+	}
+	event := gcloud.NewErrorEvent(err, time.Time{}, nil)
+	want := "bar: foo\n\ngoroutine 1 [running]:\n" +
+		"example.com/a.Get()\n\t/src/a.go:10\n" +
+		"unknown()\n\t/src/c.go:30\n" +
+		"example.com/b.(*T).Serve()\n\t/src/b.go:20\n"
+	if event.Message != want {
+		t.Fatalf("unexpected message\ngot  %q\nwant %q", event.Message, want)
+	}
+	want = "example.com/b.(*T).Serve"
+	if got := event.Context.ReportLocation.FunctionName; got != want {
+		t.Fatalf("unexpected function name %q", got)
+	}
+}
+
+func TestNewErrorEventWithoutLocations(t *testing.T) {
+	event := gcloud.NewErrorEvent(fmt.Errorf("foo"), time.Time{}, nil)
+	data, jerr := json.Marshal(event)
+	if jerr != nil {
+		t.Fatal(jerr)
+	}
+	want := `{"@type":"type.googleapis.com/google.devtools.clouderrorreporting.v1beta1.ReportedErrorEvent","message":"foo"}`
+	if string(data) != want {
+		t.Fatalf("unexpected JSON\ngot  %s\nwant %s", data, want)
+	}
+}
@@ -0,0 +1,63 @@
+// The gelf package renders errgo errors as messages in the
+// Graylog Extended Log Format (GELF).
+package gelf
+
+import (
+	"time"
+
+	"github.com/juju/errgo"
+)
+
+// ToGELF returns a GELF message describing err, which must
+// not be nil, reported by the given host. It may be encoded
+// with json.Marshal.
+//
+// The short_message field holds the error message, escaped as
+// arranged by errgo.EscapeMessages, full_message holds its details
+// (see errgo.Details) and level holds the syslog priority of its
+// severity (see errgo.SeverityOf). If the error recorded its
+// creation time (see errgo.RecordTimes), it is used as the
+// timestamp. The structured metadata of the error is held in
+// additional fields: _error_location holds the outermost location
+// in its chain, and _error_kind, _error_code, _error_id,
+// _error_trace_id and _error_fingerprint hold the values of
+// errgo.Classify, errgo.CodeOf, errgo.ID, errgo.TraceContextOf
+// and errgo.Fingerprint, when set.
+func ToGELF(err error, host string) map[string]interface{} {
+	m := map[string]interface{}{
+		"version":            "1.1",
+		"host":               host,
+		"short_message":      message(err),
+		"full_message":       errgo.Details(err),
+		"level":              errgo.SeverityOf(err).SyslogPriority(),
+		"_error_fingerprint": errgo.Fingerprint(err),
+	}
+	if loc, ok := errgo.OutermostLocation(err); ok {
+		m["_error_location"] = loc.String()
+	}
+	if err, ok := err.(errgo.Timestamper); ok && !err.Time().IsZero() {
+		m["timestamp"] = float64(err.Time().UnixNano()) / float64(time.Second)
+	}
+	if kind := errgo.Classify(err); kind != "" {
+		m["_error_kind"] = string(kind)
+	}
+	if code := errgo.CodeOf(err); code != 0 {
+		m["_error_code"] = int(code)
+	}
+	if id := errgo.ID(err); id != "" {
+		m["_error_id"] = id
+	}
+	if tc, ok := errgo.TraceContextOf(err); ok {
+		m["_error_trace_id"] = tc.TraceID
+	}
+	return m
+}
+
+// message returns the message of err,
+// escaped as arranged by errgo.EscapeMessages.
+func message(err error) string {
+	if errgo.EscapeMessages {
+		return errgo.SafeError(err)
+	}
+	return err.Error()
+}
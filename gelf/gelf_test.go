@@ -0,0 +1,68 @@
+package gelf_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/juju/errgo"
+	"github.com/juju/errgo/gelf"
+)
+
+func TestToGELF(t *testing.T) {
+	err0 := errgo.WithCode(errgo.New("foo"), 4001)
+	err := &errgo.Err{
+		Message_:    "bar",
+		Underlying_: errgo.WithSeverity(err0, errgo.SeverityWarning),
+		Cause_:      errgo.Cause(err0),
+		Location_:   errgo.Location{File: "/src/a.go", Line: 10},
+		Time_:       time.Unix(1500000000, 500000000),
+	}
+
+	data, jerr := json.Marshal(gelf.ToGELF(err, "web1"))
+	if jerr != nil {
+		t.Fatal(jerr)
+	}
+	var got map[string]interface{}
+	if jerr := json.Unmarshal(data, &got); jerr != nil {
+		t.Fatal(jerr)
+	}
+	want := map[string]interface{}{
+		"version":            "1.1",
+		"host":               "web1",
+		"short_message":      "bar: foo",
+		"full_message":       errgo.Details(err),
+		"level":              4.0,
+		"timestamp":          1500000000.5,
+		"_error_location":    "/src/a.go:10",
+		"_error_code":        4001.0,
+		"_error_fingerprint": errgo.Fingerprint(err),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected message\ngot  %v\nwant %v", got, want)
+	}
+}
+
+func TestToGELFMinimal(t *testing.T) {
+	got := gelf.ToGELF(&errgo.Err{Message_: "foo"}, "web1")
+	if got["level"] != 3 || got["short_message"] != "foo" {
+		t.Fatalf("unexpected message %v", got)
+	}
+	for _, key := range []string{"timestamp", "_error_location", "_error_code", "_error_id", "_error_kind", "_error_trace_id"} {
+		if _, ok := got[key]; ok {
+			t.Errorf("unexpected field %q", key)
+		}
+	}
+}
+
+func TestToGELFEscapeMessages(t *testing.T) {
+	defer func() {
+		errgo.EscapeMessages = false
+	}()
+	errgo.EscapeMessages = true
+	err := errgo.Notef(errgo.New("foo"), "cannot parse: line\n2")
+	if got, want := gelf.ToGELF(err, "host")["short_message"], errgo.SafeError(err); got != want {
+		t.Fatalf("unexpected message %q; want %q", got, want)
+	}
+}
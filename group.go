@@ -0,0 +1,84 @@
+package errgo
+
+import "sync"
+
+// Group runs a set of tasks concurrently and collects
+// the errors that they return.
+//
+// The zero value is ready to use. A Group must not be
+// copied after first use.
+type Group struct {
+	wg   sync.WaitGroup
+	mu   sync.Mutex
+	errs []error
+}
+
+// Go calls fn in a new goroutine. If fn returns an error or
+// panics, the error (or an error describing the panic) is
+// annotated with the name of the task and recorded for Wait
+// to return. The location of the annotation is the caller of Go.
+//
+// The cause of the returned error is passed through the
+// annotation unchanged.
+func (g *Group) Go(name string, fn func() error) {
+	var site Err
+	site.setLocation(1)
+	g.mu.Lock()
+	index := len(g.errs)
+	g.errs = append(g.errs, nil)
+	g.mu.Unlock()
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		err := runTask(fn)
+		if err == nil {
+			return
+		}
+		newErr := &Err{
+			Message_:    name,
+			Underlying_: err,
+			Cause_:      Cause(err),
+		}
+		newErr.setSite(&site)
+		err = Created(newErr)
+		g.mu.Lock()
+		g.errs[index] = err
+		g.mu.Unlock()
+	}()
+}
+
+// runTask calls fn, converting any panic into an
+// error located where the panic happened.
+func runTask(fn func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			newErr := &Err{}
+			newErr.setMessagef("panic: %v", []interface{}{r})
+			newErr.SetLocation(1)
+			err = Created(newErr)
+		}
+	}()
+	return fn()
+}
+
+// Wait waits for all the tasks started with Go to complete. If
+// any of them failed, it returns an error wrapping all their
+// errors (see MultiErr), in the order that the tasks were
+// started; otherwise it returns nil.
+func (g *Group) Wait() error {
+	g.wg.Wait()
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	var failed int
+	for _, err := range g.errs {
+		if err != nil {
+			failed++
+		}
+	}
+	err := combine(g.errs, "%d of %d tasks failed", []interface{}{failed, len(g.errs)})
+	if err == nil {
+		return nil
+	}
+	err.SetLocation(1)
+	return Created(err)
+}
@@ -0,0 +1,59 @@
+package errgo_test
+
+import (
+	"testing"
+
+	"github.com/juju/errgo"
+)
+
+func TestGroup(t *testing.T) {
+	var g errgo.Group
+	g.Go("ok", func() error {
+		return nil
+	})
+	g.Go("fetch", func() error {
+		return errgo.New("boom")
+	})
+	g.Go("store", func() error {
+		panic("oops")
+	})
+	err := g.Wait()
+	checkErr(t, err, nil, "2 of 3 tasks failed: fetch: boom; store: panic: oops",
+		"[{group_test.go: 2 of 3 tasks failed [{group_test.go: fetch} {group_test.go: boom}] [{group_test.go: store} {group_test.go: panic: oops}]}]", err)
+}
+
+func TestGroupRuntimePanic(t *testing.T) {
+	var g errgo.Group
+	g.Go("store", func() error {
+		var m map[string]int
+		m["x"] = 1
+		return nil
+	})
+	err := g.Wait()
+	checkErr(t, err, nil, "1 of 1 tasks failed: store: panic: assignment to entry in nil map",
+		"[{group_test.go: 1 of 1 tasks failed [{group_test.go: store} {group_test.go: panic: assignment to entry in nil map}]}]", err)
+}
+
+func TestGroupPassesCause(t *testing.T) {
+	var g errgo.Group
+	g.Go("fetch", func() error {
+		return errNotFound
+	})
+	err := g.Wait()
+	if causes := errgo.Causes(err); len(causes) != 1 || causes[0] != errNotFound {
+		t.Fatalf("unexpected causes %v", causes)
+	}
+}
+
+func TestGroupNoErrors(t *testing.T) {
+	var g errgo.Group
+	if err := g.Wait(); err != nil {
+		t.Fatalf("unexpected error %#v", err)
+	}
+	g.Go("ok", func() error {
+		return nil
+	})
+	if err := g.Wait(); err != nil {
+		t.Fatalf("unexpected error %#v", err)
+	}
+}
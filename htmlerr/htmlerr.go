@@ -0,0 +1,110 @@
+// The htmlerr package renders errgo errors as HTML fragments,
+// suitable for embedding in debugging pages.
+package htmlerr
+
+import (
+	"html/template"
+	"strconv"
+	"strings"
+
+	"github.com/juju/errgo"
+)
+
+// sourceURL holds the pattern used by ToHTML
+// to link locations to their source.
+var sourceURL string
+
+// SetSourceURL sets the pattern used by ToHTML to link error
+// locations to their source code. Any occurrences of "{file}" and
+// "{line}" in pattern are replaced by the file and line of each
+// location, for example:
+//
+//	htmlerr.SetSourceURL("https://example.com/src/{file}#L{line}")
+//
+// If pattern is empty, as it is by default, locations are not
+// linked. SetSourceURL should be called before ToHTML is
+// used, usually during program initialization.
+func SetSourceURL(pattern string) {
+	sourceURL = pattern
+}
+
+var htmlTemplate = template.Must(template.New("").Parse(`
+{{- define "chain"}}<ol class="errgo-chain">
+{{- range .}}<li><details open><summary>
+{{- if .Location}}<span class="errgo-location">
+{{- if .URL}}<a href="{{.URL}}">{{.Location}}</a>{{else}}{{.Location}}{{end}}</span> {{end}}
+{{- .Message}}</summary>
+{{- if .Cause}}<div class="errgo-cause">cause: {{.Cause}}</div>{{end}}
+{{- range .Branches}}{{template "chain" .}}{{end}}</details></li>
+{{- end}}</ol>
+{{- end}}<div class="errgo">{{template "chain" .}}</div>`))
+
+// entry holds the data rendered by ToHTML
+// for each error in a chain.
+type entry struct {
+	Message  string
+	Location string
+	URL      string
+	Cause    string
+	Branches [][]entry
+}
+
+// ToHTML returns an HTML fragment describing the error chain of err.
+// Each error in the chain (see errgo.Details), outermost first, is
+// shown as a collapsible element holding its location, message and
+// cause, and errors wrapped by an error (see errgo.MultiWrapper)
+// are shown as nested chains within it. Locations are linked to
+// their source as arranged by SetSourceURL.
+//
+// All text taken from the errors is escaped, so the result
+// is safe to include in a page even when error messages
+// hold untrusted input.
+//
+// If err is nil, ToHTML returns the empty string.
+func ToHTML(err error) template.HTML {
+	if err == nil {
+		return ""
+	}
+	var b strings.Builder
+	if err := htmlTemplate.Execute(&b, chain(err)); err != nil {
+		panic(errgo.Notef(err, "cannot execute HTML template"))
+	}
+	return template.HTML(b.String())
+}
+
+// chain returns the entries rendered by
+// ToHTML for the chain of err.
+func chain(err error) []entry {
+	var entries []entry
+	for ; err != nil; err = errgo.Underlying(err) {
+		e := entry{
+			Message: errgo.Message(err),
+		}
+		if err, ok := err.(errgo.Locationer); ok && err.Location().IsSet() {
+			loc := err.Location()
+			e.Location = loc.String()
+			e.URL = locationURL(loc)
+		}
+		if err, ok := err.(errgo.Causer); ok && err.Cause() != nil {
+			e.Cause = err.Cause().Error()
+		}
+		for _, branch := range errgo.Branches(err) {
+			e.Branches = append(e.Branches, chain(branch))
+		}
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+// locationURL returns the URL of the source at
+// loc as arranged by SetSourceURL, or the empty
+// string if there is none.
+func locationURL(loc errgo.Location) string {
+	if sourceURL == "" {
+		return ""
+	}
+	return strings.NewReplacer(
+		"{file}", loc.File,
+		"{line}", strconv.Itoa(loc.Line),
+	).Replace(sourceURL)
+}
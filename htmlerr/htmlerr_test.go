@@ -0,0 +1,71 @@
+package htmlerr_test
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/juju/errgo"
+	"github.com/juju/errgo/htmlerr"
+)
+
+// sourceLocations reports whether errors record the source
+// locations of their callers, which they do not in minimal
+// builds or on runtimes with limited support for finding
+// callers (see errgo.RestrictedLocationLabel).
+var sourceLocations = func() bool {
+	loc, ok := errgo.InnermostLocation(errgo.New(""))
+	return ok && loc.File != errgo.RestrictedLocationLabel
+}()
+
+func TestToHTML(t *testing.T) {
+	if got := htmlerr.ToHTML(nil); got != "" {
+		t.Fatalf("unexpected HTML for nil error %q", got)
+	}
+	err0 := &errgo.Err{
+		Message_:  "<script>alert(1)</script>",
+		Location_: errgo.Location{File: "a.go", Line: 10},
+	}
+	err1 := &errgo.MultiErr{
+		Err: errgo.Err{
+			Location_: errgo.Location{File: "b.go", Line: 20},
+		},
+		UnderlyingErrors_: []error{err0},
+	}
+	err2 := &errgo.Err{
+		Message_:    "bad request",
+		Underlying_: err1,
+		Cause_:      errgo.New("a & b"),
+		Location_:   errgo.Location{File: "c.go", Line: 30},
+	}
+
+	want := `<div class="errgo"><ol class="errgo-chain">` +
+		`<li><details open><summary><span class="errgo-location">c.go:30</span> bad request</summary>` +
+		`<div class="errgo-cause">cause: a &amp; b</div></details></li>` +
+		`<li><details open><summary><span class="errgo-location">b.go:20</span> </summary>` +
+		`<ol class="errgo-chain"><li><details open><summary><span class="errgo-location">a.go:10</span> ` +
+		`&lt;script&gt;alert(1)&lt;/script&gt;</summary></details></li></ol></details></li>` +
+		`</ol></div>`
+	if got := string(htmlerr.ToHTML(err2)); got != want {
+		t.Fatalf("unexpected HTML; got\n%s\nwant\n%s", got, want)
+	}
+
+	htmlerr.SetSourceURL("https://example.com/src/{file}#L{line}")
+	defer htmlerr.SetSourceURL("")
+	if got, link := string(htmlerr.ToHTML(err0)), `<a href="https://example.com/src/a.go#L10">a.go:10</a>`; !strings.Contains(got, link) {
+		t.Fatalf("HTML %q does not contain link %q", got, link)
+	}
+	if sourceLocations {
+		err := errgo.New("foo")
+		loc := err.(errgo.Locationer).Location()
+		link := `<a href="https://example.com/src/` + loc.File + `#L` + strconv.Itoa(loc.Line) + `">` + loc.String() + `</a>`
+		if got := string(htmlerr.ToHTML(err)); !strings.Contains(got, link) {
+			t.Fatalf("HTML %q does not contain link %q", got, link)
+		}
+	}
+
+	htmlerr.SetSourceURL("javascript:alert({line})")
+	if got := string(htmlerr.ToHTML(err0)); strings.Contains(got, "javascript:") {
+		t.Fatalf("unsafe URL included in HTML %q", got)
+	}
+}
@@ -0,0 +1,114 @@
+// The httperr package describes errors in HTTP responses: it
+// propagates a compact description of an error in HTTP headers,
+// so that services calling one another can preserve the identity
+// of an error without changing the contract of their response
+// bodies, and renders errors as RFC 7807 problem details documents.
+package httperr
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/juju/errgo"
+)
+
+// Header names used to hold the description of an error.
+const (
+	MessageHeader     = "Errgo-Message"
+	KindHeader        = "Errgo-Kind"
+	FingerprintHeader = "Errgo-Fingerprint"
+	TraceIDHeader     = "Errgo-Trace-Id"
+	IDHeader          = "Errgo-Id"
+)
+
+// SetHeaders sets headers in h describing err: its message, its
+// kind (see errgo.Classify), its fingerprint (see errgo.Fingerprint)
+// and, when known, its trace identifier (see errgo.TraceContextOf)
+// and unique identifier (see errgo.ID). The message is
+// percent-encoded so that it is valid in a header.
+//
+// If err is nil, SetHeaders does nothing.
+func SetHeaders(h http.Header, err error) {
+	if err == nil {
+		return
+	}
+	h.Set(MessageHeader, url.PathEscape(err.Error()))
+	h.Set(FingerprintHeader, errgo.Fingerprint(err))
+	if kind := errgo.Classify(err); kind != "" {
+		h.Set(KindHeader, string(kind))
+	}
+	if tc, ok := errgo.TraceContextOf(err); ok {
+		h.Set(TraceIDHeader, tc.TraceID)
+	}
+	if id := errgo.ID(err); id != "" {
+		h.Set(IDHeader, id)
+	}
+}
+
+// Error holds an error reconstructed from headers set by SetHeaders.
+type Error struct {
+	errgo.Err
+
+	// Kind_ holds the kind of the original error.
+	Kind_ errgo.Kind
+
+	// Fingerprint_ holds the fingerprint of the original error.
+	Fingerprint_ string
+
+	// TraceID_ holds the trace identifier of the original
+	// error, if known.
+	TraceID_ string
+
+	// RemoteID_ holds the unique identifier of the
+	// original error, if known.
+	RemoteID_ string
+}
+
+// Kind returns the kind of the original error, so that the
+// kind is found by errgo.Classify and errgo.KindOf.
+func (e *Error) Kind() errgo.Kind {
+	return e.Kind_
+}
+
+// Fingerprint returns the fingerprint of the original error.
+func (e *Error) Fingerprint() string {
+	return e.Fingerprint_
+}
+
+// TraceContext implements errgo.TraceContexter. Only the
+// trace identifier of the original error is known.
+func (e *Error) TraceContext() errgo.TraceContext {
+	return errgo.TraceContext{
+		TraceID: e.TraceID_,
+	}
+}
+
+// RemoteID returns the unique identifier of the original
+// error, if known.
+func (e *Error) RemoteID() string {
+	return e.RemoteID_
+}
+
+// FromHeaders returns an *Error reconstructed from headers set by
+// SetHeaders, or nil if h does not describe an error. The location
+// of the returned error is the caller of FromHeaders.
+func FromHeaders(h http.Header) error {
+	msg := h.Get(MessageHeader)
+	if msg == "" {
+		return nil
+	}
+	if unescaped, err := url.PathUnescape(msg); err == nil {
+		msg = unescaped
+	}
+	err := &Error{
+		Err: errgo.Err{
+			Message_: msg,
+		},
+		Kind_:        errgo.Kind(h.Get(KindHeader)),
+		Fingerprint_: h.Get(FingerprintHeader),
+		TraceID_:     h.Get(TraceIDHeader),
+		RemoteID_:    h.Get(IDHeader),
+	}
+	err.SetLocation(1)
+	return errgo.Created(err)
+}
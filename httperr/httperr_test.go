@@ -0,0 +1,66 @@
+package httperr_test
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/juju/errgo"
+	"github.com/juju/errgo/httperr"
+)
+
+const traceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+func TestRoundTrip(t *testing.T) {
+	_, openErr := os.Open("/non-existent-file")
+	ctx := errgo.ContextWithTraceparent(context.Background(), traceparent)
+	err := errgo.TraceCtx(ctx, errgo.Notef(openErr, "cannot read\nconfig"))
+
+	h := make(http.Header)
+	httperr.SetHeaders(h, err)
+	for _, name := range []string{
+		httperr.MessageHeader,
+		httperr.KindHeader,
+		httperr.FingerprintHeader,
+		httperr.TraceIDHeader,
+	} {
+		if h.Get(name) == "" {
+			t.Errorf("header %s not set", name)
+		}
+	}
+
+	got := httperr.FromHeaders(h)
+	if got.Error() != err.Error() {
+		t.Fatalf("unexpected message %q", got.Error())
+	}
+	if kind := errgo.Classify(got); kind != errgo.KindNotFound {
+		t.Fatalf("unexpected kind %q", kind)
+	}
+	if fp := got.(*httperr.Error).Fingerprint(); fp != errgo.Fingerprint(err) {
+		t.Fatalf("unexpected fingerprint %q", fp)
+	}
+	if tc, ok := errgo.TraceContextOf(errgo.Mask(got)); !ok || tc.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Fatalf("unexpected trace context %#v, %v", tc, ok)
+	}
+}
+
+func TestNoError(t *testing.T) {
+	h := make(http.Header)
+	httperr.SetHeaders(h, nil)
+	if len(h) != 0 {
+		t.Fatalf("unexpected headers %v", h)
+	}
+	if err := httperr.FromHeaders(h); err != nil {
+		t.Fatalf("unexpected error %#v", err)
+	}
+
+	httperr.SetHeaders(h, errgo.New("foo"))
+	err := httperr.FromHeaders(h)
+	if _, ok := errgo.TraceContextOf(err); ok {
+		t.Fatalf("unexpected trace context")
+	}
+	if kind := errgo.Classify(err); kind != "" {
+		t.Fatalf("unexpected kind %q", kind)
+	}
+}
@@ -0,0 +1,136 @@
+package httperr
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/juju/errgo"
+)
+
+// Problem holds an RFC 7807 problem details document.
+type Problem struct {
+	// Type holds a URI reference identifying the problem type.
+	Type string
+
+	// Title holds a short summary of the problem type.
+	Title string
+
+	// Status holds the HTTP status code.
+	Status int
+
+	// Detail holds an explanation specific to this
+	// occurrence of the problem.
+	Detail string
+
+	// Instance holds a URI reference identifying this
+	// occurrence of the problem.
+	Instance string
+
+	// Extensions holds any additional members
+	// of the document.
+	Extensions map[string]interface{}
+}
+
+// MarshalJSON implements json.Marshaler, encoding the
+// extensions alongside the standard members.
+func (p *Problem) MarshalJSON() ([]byte, error) {
+	m := make(map[string]interface{}, len(p.Extensions)+5)
+	for key, val := range p.Extensions {
+		m[key] = val
+	}
+	m["type"] = p.Type
+	m["title"] = p.Title
+	m["status"] = p.Status
+	if p.Detail != "" {
+		m["detail"] = p.Detail
+	}
+	if p.Instance != "" {
+		m["instance"] = p.Instance
+	}
+	return json.Marshal(m)
+}
+
+// FullDetail causes ToProblem to use the error message as the
+// detail of server errors (those with a 5xx status) too. As the
+// message of a server error usually describes the internal chain
+// of errors that caused it, this is off by default and should
+// only be enabled when the clients are trusted.
+var FullDetail = false
+
+// ToProblem returns a problem details document describing err,
+// which must not be nil. The status is taken from
+// errgo.HTTPStatus(err) and the detail is the error message for
+// client errors and the status text for server errors, unless
+// FullDetail is set. Use errgo.Sanitize to control which messages
+// of a client error are exposed.
+//
+// If the error has an identifier (see errgo.ID), the instance
+// is set to "urn:error:" followed by the identifier.
+// If the error has a documentation URL (see errgo.DocURL),
+// it is used as the type. The structured information
+// recorded in the chain is included in extension members:
+//
+//	categories  names of registered checkers that match (see errgo.Matching)
+//	errors      field errors of any errgo.Validation
+//	code        numeric code (see errgo.CodeOf)
+//	severity    name of the severity (see errgo.SeverityOf)
+//	tags        tags added with errgo.Tag
+//	labels      profiler labels (see errgo.ProfileLabels)
+//
+// Members with no information are omitted, except for the
+// severity, which is always known. Values added with
+// errgo.WithValue are not included, as their keys are opaque.
+func ToProblem(err error) *Problem {
+	status := errgo.HTTPStatus(err)
+	p := &Problem{
+		Type:       "about:blank",
+		Title:      http.StatusText(status),
+		Status:     status,
+		Detail:     err.Error(),
+		Extensions: make(map[string]interface{}),
+	}
+	if status >= http.StatusInternalServerError && !FullDetail {
+		p.Detail = p.Title
+	}
+	if url := errgo.DocURL(err); url != "" {
+		p.Type = url
+	}
+	if id := errgo.ID(err); id != "" {
+		p.Instance = "urn:error:" + id
+	}
+	if code := errgo.CodeOf(err); code != 0 {
+		p.Extensions["code"] = int(code)
+	}
+	p.Extensions["severity"] = errgo.SeverityOf(err).String()
+	if tags := errgo.Tags(err); len(tags) > 0 {
+		p.Extensions["tags"] = tags
+	}
+	if labels := errgo.ProfileLabels(err); len(labels) > 0 {
+		p.Extensions["labels"] = labels
+	}
+	if categories := errgo.Matching(err); len(categories) > 0 {
+		p.Extensions["categories"] = categories
+	}
+	if v := errgo.Find(err, func(err error) bool {
+		_, ok := err.(*errgo.Validation)
+		return ok
+	}); v != nil {
+		p.Extensions["errors"] = v
+	}
+	return p
+}
+
+// WriteProblem writes the problem details document describing
+// err (see ToProblem) as an HTTP response with content type
+// application/problem+json.
+func WriteProblem(w http.ResponseWriter, err error) {
+	p := ToProblem(err)
+	data, jerr := json.Marshal(p)
+	if jerr != nil {
+		http.Error(w, jerr.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(p.Status)
+	w.Write(data)
+}
@@ -0,0 +1,101 @@
+package httperr_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"runtime/pprof"
+	"testing"
+
+	"github.com/juju/errgo"
+	"github.com/juju/errgo/httperr"
+	"github.com/juju/errgo/pproferr"
+)
+
+var (
+	someErr  = errors.New("some error")
+	errQuota = errors.New("quota exceeded")
+)
+
+func init() {
+	errgo.RegisterChecker("httperr-quota", errgo.Is(errQuota))
+}
+
+func TestToProblem(t *testing.T) {
+	errgo.AssignIDs = true
+	err := errgo.Mask(errQuota, errgo.Is(errQuota))
+	errgo.AssignIDs = false
+
+	p := httperr.ToProblem(err)
+	if p.Status != http.StatusInternalServerError || p.Title != "Internal Server Error" {
+		t.Fatalf("unexpected status %d %q", p.Status, p.Title)
+	}
+	if p.Detail != "Internal Server Error" {
+		t.Fatalf("unexpected detail %q", p.Detail)
+	}
+	if id := errgo.ID(err); id != "" && p.Instance != "urn:error:"+id {
+		t.Fatalf("unexpected instance %q", p.Instance)
+	}
+	if got := p.Extensions["categories"]; !reflect.DeepEqual(got, []string{"httperr-quota"}) {
+		t.Fatalf("unexpected categories %v", got)
+	}
+	if _, ok := p.Extensions["code"]; ok {
+		t.Fatalf("unexpected code member")
+	}
+	if p.Type != "about:blank" {
+		t.Fatalf("unexpected type %q", p.Type)
+	}
+	p = httperr.ToProblem(errgo.Notef(errgo.WithDocURL(someErr, "https://docs.example.com/errors/E1234"), "foo"))
+	if p.Type != "https://docs.example.com/errors/E1234" {
+		t.Fatalf("unexpected type %q", p.Type)
+	}
+	p = httperr.ToProblem(errgo.Mask(errgo.WithCode(someErr, 4001)))
+	if got := p.Extensions["code"]; got != 4001 {
+		t.Fatalf("unexpected code %v", got)
+	}
+	p = httperr.ToProblem(errgo.Tag(errgo.WithSeverity(errgo.Tag(someErr, "billing", "user-visible"), errgo.SeverityWarning), "billing", "paging"))
+	if got := p.Extensions["severity"]; got != "warning" {
+		t.Fatalf("unexpected severity %v", got)
+	}
+	if got := p.Extensions["tags"]; !reflect.DeepEqual(got, []string{"billing", "paging", "user-visible"}) {
+		t.Fatalf("unexpected tags %v", got)
+	}
+	ctx := pprof.WithLabels(context.Background(), pprof.Labels("handler", "users"))
+	p = httperr.ToProblem(pproferr.WithLabels(ctx, someErr))
+	if got := p.Extensions["labels"]; !reflect.DeepEqual(got, map[string]string{"handler": "users"}) {
+		t.Fatalf("unexpected labels %v", got)
+	}
+}
+
+func TestToProblemFullDetail(t *testing.T) {
+	httperr.FullDetail = true
+	defer func() {
+		httperr.FullDetail = false
+	}()
+	err := errgo.Notef(someErr, "cannot query database")
+	p := httperr.ToProblem(err)
+	if p.Detail != "cannot query database: some error" {
+		t.Fatalf("unexpected detail %q", p.Detail)
+	}
+}
+
+func TestWriteProblem(t *testing.T) {
+	var v errgo.Validation
+	v.Addf("name", "is required")
+	err := errgo.WithHTTPStatus(errgo.Notef(v.Err(), "bad request"), http.StatusBadRequest)
+
+	rec := httptest.NewRecorder()
+	httperr.WriteProblem(rec, err)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("unexpected status %d", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/problem+json" {
+		t.Fatalf("unexpected content type %q", ct)
+	}
+	want := `{"detail":"bad request: validation failed: name: is required","errors":{"name":["is required"]},"severity":"error","status":400,"title":"Bad Request","type":"about:blank"}`
+	if body := rec.Body.String(); body != want {
+		t.Fatalf("unexpected body\ngot  %s\nwant %s", body, want)
+	}
+}
@@ -0,0 +1,45 @@
+package errgo
+
+import (
+	"encoding/base32"
+	"math/rand/v2"
+)
+
+// AssignIDs controls whether errors are assigned a unique
+// identifier when they are created. The identifier can be
+// shown to users (for example "error ref Q4ZKX7RM") and then
+// matched against the details of the error (see Details)
+// recorded in server logs.
+//
+// It should be set before any errors are created, usually
+// during program initialization.
+var AssignIDs = false
+
+// Identifier can be implemented by any error type that
+// has a unique identifier.
+type Identifier interface {
+	ID() string
+}
+
+// ID returns the identifier of the outermost error in the
+// chain of err that has one, or the empty string if none
+// does. See AssignIDs.
+func ID(err error) string {
+	found := Find(err, func(err error) bool {
+		err1, ok := err.(Identifier)
+		return ok && err1.ID() != ""
+	})
+	if found == nil {
+		return ""
+	}
+	return found.(Identifier).ID()
+}
+
+// newID returns a new short identifier made of 40 random bits.
+// The identifiers need to be unique, not unpredictable, so
+// the bits are taken from math/rand rather than crypto/rand.
+func newID() string {
+	n := rand.Uint64()
+	b := [5]byte{byte(n >> 32), byte(n >> 24), byte(n >> 16), byte(n >> 8), byte(n)}
+	return base32.StdEncoding.EncodeToString(b[:])
+}
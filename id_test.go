@@ -0,0 +1,42 @@
+package errgo_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/juju/errgo"
+)
+
+func TestID(t *testing.T) {
+	skipIfMinimal(t)
+	if id := errgo.ID(errgo.New("foo")); id != "" {
+		t.Fatalf("unexpected id %q", id)
+	}
+	errgo.AssignIDs = true
+	defer func() {
+		errgo.AssignIDs = false
+	}()
+	err0 := errgo.New("foo")
+	id0 := errgo.ID(err0)
+	if len(id0) != 8 {
+		t.Fatalf("unexpected id %q", id0)
+	}
+	err := errgo.Notef(err0, "bar")
+	id := errgo.ID(err)
+	if id == "" || id == id0 {
+		t.Fatalf("unexpected id %q", id)
+	}
+	if errgo.ID(errgo.Notef(errNotFound, "x")) == errgo.ID(errgo.Notef(errNotFound, "x")) {
+		t.Fatalf("ids are not unique")
+	}
+	details := errgo.Details(err)
+	if !strings.HasPrefix(details, "[{#"+id+" ") || !strings.Contains(details, "{#"+id0+" ") {
+		t.Fatalf("ids not found in details %q", details)
+	}
+	if id := errgo.ID(errNotFound); id != "" {
+		t.Fatalf("unexpected id %q", id)
+	}
+	if id := errgo.ID(errgo.MaskFunc()(err0)); id == "" || id == id0 {
+		t.Fatalf("unexpected id %q", id)
+	}
+}
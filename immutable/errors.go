@@ -0,0 +1,239 @@
+// The immutable package provides the same API as
+// github.com/juju/errgo, but the errors it creates
+// cannot be changed after they have been constructed.
+// All their state is held in unexported fields and is only
+// available through accessor methods, so an error may
+// safely be shared between goroutines, for example as a
+// package-level sentinel value.
+//
+// The errors record the same information as those created by the
+// original package, honoring settings such as errgo.SkipPackage,
+// errgo.SetFrameDepth and errgo.MessageSeparator, and are passed
+// through the middleware added with errgo.Use. They implement the
+// errgo.Wrapper, errgo.Causer and errgo.Locationer interfaces, so
+// they can be freely mixed with errors created by the original
+// package.
+package immutable
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/juju/errgo"
+)
+
+// Location describes a source code location.
+type Location = errgo.Location
+
+// Causer is the type of an error that may provide
+// an error cause for error diagnosis.
+type Causer = errgo.Causer
+
+// Wrapper is the type of an error that wraps another error.
+type Wrapper = errgo.Wrapper
+
+// Locationer can be implemented by any error type
+// that wants to expose the source location of an error.
+type Locationer = errgo.Locationer
+
+// errorInfo is the type of all errors created by this package.
+// It holds an errgo.Err, so that its message, location and other
+// information are recorded and reported exactly as for errors
+// created by the original package, but the Err is not exposed,
+// so it cannot be changed after it has been returned from a
+// constructor.
+type errorInfo struct {
+	err errgo.Err
+}
+
+// newError returns a new error holding the given information,
+// recording the location callDepth stack frames above the caller
+// of newError as errgo.Err.SetLocation does, and passes it
+// through the middleware added with errgo.Use.
+func newError(info errgo.Err, callDepth int) error {
+	e := &errorInfo{err: info}
+	e.err.SetLocation(callDepth + 1)
+	return errgo.Created(e)
+}
+
+// formatted returns the information for an error whose message
+// is formatted from f and a, recording the format string and a
+// copy of the arguments as the original package does.
+func formatted(f string, a []interface{}) errgo.Err {
+	return errgo.Err{
+		Message_: fmt.Sprintf(f, a...),
+		Format_:  f,
+		Extra_: &errgo.Extra{
+			Args: append([]interface{}(nil), a...),
+		},
+	}
+}
+
+// Location implements Locationer.
+func (e *errorInfo) Location() Location {
+	return e.err.Location()
+}
+
+// Function implements errgo.Functioner.
+func (e *errorInfo) Function() string {
+	return e.err.Function()
+}
+
+// Frames implements errgo.Framer. It returns a copy
+// of the recorded frames.
+func (e *errorInfo) Frames() []Location {
+	return slices.Clone(e.err.Frames())
+}
+
+// FrameFunctions implements errgo.FrameFunctioner. It returns
+// a copy of the recorded function names.
+func (e *errorInfo) FrameFunctions() []string {
+	return slices.Clone(e.err.FrameFunctions())
+}
+
+// ID implements errgo.Identifier.
+func (e *errorInfo) ID() string {
+	return e.err.ID()
+}
+
+// Time implements errgo.Timestamper.
+func (e *errorInfo) Time() time.Time {
+	return e.err.Time()
+}
+
+// Underlying implements Wrapper.
+func (e *errorInfo) Underlying() error {
+	return e.err.Underlying()
+}
+
+// Cause implements Causer.
+func (e *errorInfo) Cause() error {
+	return e.err.Cause()
+}
+
+// Message implements Wrapper.
+func (e *errorInfo) Message() string {
+	return e.err.Message()
+}
+
+// FormatArgs returns the format string and arguments
+// from which the message was created. See
+// errgo.Err.FormatArgs for details. The returned
+// arguments are a copy of those recorded.
+func (e *errorInfo) FormatArgs() (format string, args []interface{}) {
+	format, args = e.err.FormatArgs()
+	return format, slices.Clone(args)
+}
+
+// Error implements error.Error. The message is joined
+// to that of the underlying error as for errgo.Err.Error.
+func (e *errorInfo) Error() string {
+	return e.err.Error()
+}
+
+// GoString returns the details of the receiving error
+// message, so that printing an error with %#v will
+// produce useful information.
+func (e *errorInfo) GoString() string {
+	return errgo.Details(e)
+}
+
+// New returns a new error with the given error message and no cause.
+func New(s string) error {
+	return newError(errgo.Err{Message_: s}, 1)
+}
+
+// Newf returns a new error with the given printf-formatted error
+// message and no cause.
+func Newf(f string, a ...interface{}) error {
+	return newError(formatted(f, a), 1)
+}
+
+// noteMask is the implementation of NoteMask, recording the
+// location callDepth stack frames above its caller.
+func noteMask(underlying error, info errgo.Err, callDepth int, pass ...func(error) bool) error {
+	info.Cause_ = errgo.PassedCause(underlying, pass...)
+	info.Underlying_ = underlying
+	return newError(info, callDepth+1)
+}
+
+// NoteMask returns an error that has the given underlying error,
+// with the given message added as context, and allowing
+// the cause of the underlying error to pass through into
+// the result if allowed by the specific pass functions
+// (see errgo.Mask for an explanation of the pass parameter).
+func NoteMask(underlying error, msg string, pass ...func(error) bool) error {
+	return noteMask(underlying, errgo.Err{Message_: msg}, 1, pass...)
+}
+
+// Mask returns an error that wraps the given underyling error. The error
+// message is unchanged, but the error location records the caller of
+// Mask. If err is nil, Mask returns nil.
+//
+// See errgo.Mask for an explanation of the pass parameter.
+func Mask(underlying error, pass ...func(error) bool) error {
+	if underlying == nil {
+		return nil
+	}
+	return noteMask(underlying, errgo.Err{}, 1, pass...)
+}
+
+// Notef returns an error that wraps the given underlying
+// error and adds the given formatted context message.
+// The returned error has no cause.
+func Notef(underlying error, f string, a ...interface{}) error {
+	return noteMask(underlying, formatted(f, a), 1)
+}
+
+// MaskFunc returns an equivalent of Mask that always allows the
+// specified causes in addition to any causes specified when the
+// returned function is called.
+func MaskFunc(allow ...func(error) bool) func(error, ...func(error) bool) error {
+	return func(err error, allow1 ...func(error) bool) error {
+		if err == nil {
+			return nil
+		}
+		allowEither := make([]func(error) bool, len(allow)+len(allow1))
+		copy(allowEither, allow)
+		copy(allowEither[len(allow):], allow1)
+		return noteMask(err, errgo.Err{}, 1, allowEither...)
+	}
+}
+
+// WithCausef returns a new error that wraps the given
+// (possibly nil) underlying error and associates it with
+// the given cause. The given formatted message context
+// will also be added.
+func WithCausef(underlying, cause error, f string, a ...interface{}) error {
+	info := formatted(f, a)
+	info.Cause_, info.Underlying_ = cause, underlying
+	return newError(info, 1)
+}
+
+// Cause returns the cause of the given error.
+// See errgo.Cause for details.
+func Cause(err error) error {
+	return errgo.Cause(err)
+}
+
+// Details returns information about the stack of
+// underlying errors wrapped by err.
+// See errgo.Details for details.
+func Details(err error) string {
+	return errgo.Details(err)
+}
+
+// Is returns a function that returns whether the
+// an error is equal to the given error.
+// See errgo.Is for details.
+func Is(err error) func(error) bool {
+	return errgo.Is(err)
+}
+
+// Any returns true. It can be used as an argument to Mask
+// to allow any diagnosis to pass through to the wrapped
+// error.
+func Any(error) bool {
+	return true
+}
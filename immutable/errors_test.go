@@ -0,0 +1,189 @@
+package immutable_test
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"sync"
+	"testing"
+
+	"github.com/juju/errgo"
+	"github.com/juju/errgo/immutable"
+)
+
+var someErr = immutable.New("some error")
+
+// sourceLocations reports whether errors record the source
+// locations of their callers, which they do not in minimal
+// builds or on runtimes with limited support for finding
+// callers (see errgo.RestrictedLocationLabel).
+var sourceLocations = func() bool {
+	loc, ok := errgo.InnermostLocation(someErr)
+	return ok && loc.File != errgo.RestrictedLocationLabel
+}()
+
+// caller returns the line number of its caller.
+func caller() int {
+	_, _, line, _ := runtime.Caller(1)
+	return line
+}
+
+func TestConstructors(t *testing.T) {
+	other := fmt.Errorf("other")
+	tests := []struct {
+		err        error
+		line       int
+		msg        string
+		underlying error
+		cause      error
+	}{{
+		err: immutable.New("foo"), line: caller(),
+		msg: "foo",
+	}, {
+		err: immutable.Newf("foo %d", 5), line: caller(),
+		msg: "foo 5",
+	}, {
+		err: immutable.Mask(someErr), line: caller(),
+		msg:        "some error",
+		underlying: someErr,
+	}, {
+		err: immutable.Mask(someErr, immutable.Is(someErr)), line: caller(),
+		msg:        "some error",
+		underlying: someErr,
+		cause:      someErr,
+	}, {
+		err: immutable.MaskFunc(immutable.Is(other))(other), line: caller(),
+		msg:        "other",
+		underlying: other,
+		cause:      other,
+	}, {
+		err: immutable.Notef(someErr, "bar"), line: caller(),
+		msg:        "bar: some error",
+		underlying: someErr,
+	}, {
+		err: immutable.NoteMask(someErr, "bar", immutable.Any), line: caller(),
+		msg:        "bar: some error",
+		underlying: someErr,
+		cause:      someErr,
+	}, {
+		err: immutable.WithCausef(someErr, other, "bar"), line: caller(),
+		msg:        "bar: some error",
+		underlying: someErr,
+		cause:      other,
+	}}
+	for i, test := range tests {
+		if msg := test.err.Error(); msg != test.msg {
+			t.Errorf("test %d: got message %q want %q", i, msg, test.msg)
+		}
+		if u := test.err.(immutable.Wrapper).Underlying(); u != test.underlying {
+			t.Errorf("test %d: got underlying %#v want %#v", i, u, test.underlying)
+		}
+		cause := test.cause
+		if cause == nil {
+			cause = test.err
+		}
+		if c := immutable.Cause(test.err); c != cause {
+			t.Errorf("test %d: got cause %#v want %#v", i, c, cause)
+		}
+		if line := test.err.(immutable.Locationer).Location().Line; line != test.line && sourceLocations {
+			t.Errorf("test %d: got line %d want %d", i, line, test.line)
+		}
+	}
+	if err := immutable.Mask(nil); err != nil {
+		t.Errorf("Mask(nil) returned %#v", err)
+	}
+	if err := immutable.MaskFunc()(nil); err != nil {
+		t.Errorf("MaskFunc()(nil) returned %#v", err)
+	}
+}
+
+func TestNoExportedFields(t *testing.T) {
+	typ := reflect.TypeOf(immutable.New("foo")).Elem()
+	for i := 0; i < typ.NumField(); i++ {
+		if f := typ.Field(i); f.PkgPath == "" {
+			t.Errorf("field %s is exported", f.Name)
+		}
+	}
+}
+
+func TestMixedWithOriginal(t *testing.T) {
+	err := errgo.Notef(immutable.Mask(someErr, immutable.Is(someErr)), "bar")
+	if got, want := immutable.Details(err), errgo.Details(err); got != want {
+		t.Fatalf("unexpected details: got %q want %q", got, want)
+	}
+	if cause := errgo.Cause(errgo.Mask(immutable.Mask(someErr, immutable.Any), errgo.Any)); cause != someErr {
+		t.Fatalf("unexpected cause %#v", cause)
+	}
+	// Causes are passed as by the original package,
+	// including those of errors wrapping several errors.
+	errNotFound := errgo.New("not found")
+	multi := errgo.Combine(errgo.WithCausef(nil, errNotFound, "one"), errgo.New("two"))
+	if got, want := immutable.Cause(immutable.Mask(multi, immutable.Is(errNotFound))), errgo.Cause(errgo.Mask(multi, errgo.Is(errNotFound))); got != want || got == nil {
+		t.Fatalf("got cause %#v want %#v", got, want)
+	}
+}
+
+func TestConcurrentUse(t *testing.T) {
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			immutable.Details(immutable.Notef(someErr, "bar"))
+			_ = fmt.Sprintf("%#v", someErr)
+		}()
+	}
+	wg.Wait()
+}
+
+func TestOriginalSettings(t *testing.T) {
+	errgo.MessageSeparator, errgo.UnderlyingFirst = " <- ", true
+	defer func() {
+		errgo.MessageSeparator, errgo.UnderlyingFirst = ": ", false
+	}()
+	err := immutable.Notef(someErr, "bar %d", 5)
+	if got, want := err.Error(), "some error <- bar 5"; got != want {
+		t.Fatalf("got message %q want %q", got, want)
+	}
+	if f, args := err.(interface {
+		FormatArgs() (string, []interface{})
+	}).FormatArgs(); f != "bar %d" || !reflect.DeepEqual(args, []interface{}{5}) {
+		t.Fatalf("unexpected format args %q %v", f, args)
+	}
+	if got, want := err.(errgo.Functioner).Function(), "github.com/juju/errgo/immutable_test.TestOriginalSettings"; got != want && sourceLocations {
+		t.Fatalf("got function %q want %q", got, want)
+	}
+}
+
+func TestAccessorsReturnCopies(t *testing.T) {
+	errgo.SetFrameDepth(3)
+	defer errgo.SetFrameDepth(0)
+	err := immutable.Notef(someErr, "bar %d", 5)
+	_, args := err.(interface {
+		FormatArgs() (string, []interface{})
+	}).FormatArgs()
+	args[0] = 6
+	if _, args := err.(interface {
+		FormatArgs() (string, []interface{})
+	}).FormatArgs(); !reflect.DeepEqual(args, []interface{}{5}) {
+		t.Fatalf("format args changed to %v", args)
+	}
+	if !sourceLocations {
+		return
+	}
+	frames := err.(errgo.Framer).Frames()
+	funcs := err.(errgo.FrameFunctioner).FrameFunctions()
+	if len(frames) == 0 || len(funcs) == 0 {
+		t.Fatalf("no frames recorded")
+	}
+	want := frames[0]
+	frames[0] = errgo.Location{}
+	if got := err.(errgo.Framer).Frames()[0]; got != want {
+		t.Fatalf("frames changed to %v", got)
+	}
+	wantFunc := funcs[0]
+	funcs[0] = ""
+	if got := err.(errgo.FrameFunctioner).FrameFunctions()[0]; got != wantFunc {
+		t.Fatalf("frame functions changed to %q", got)
+	}
+}
@@ -0,0 +1,13 @@
+// The errhelper package creates errors on behalf of its
+// callers. The last element of its import path holds a dot,
+// as for gopkg.in packages, which the runtime escapes in the
+// names of its functions. It is used to test SkipPackage
+// and Origin.
+package errhelper
+
+import "github.com/juju/errgo"
+
+// New returns a new error with the given message.
+func New(msg string) error {
+	return errgo.New(msg)
+}
@@ -0,0 +1,49 @@
+// The journald package renders errgo errors as the structured
+// fields understood by the systemd journal, so that daemons
+// logging to journald keep the severity and source location
+// of their errors.
+package journald
+
+import (
+	"strconv"
+
+	"github.com/juju/errgo"
+)
+
+// Syslog priority levels, as used in the PRIORITY field.
+const (
+	PriorityEmerg   = 0
+	PriorityAlert   = 1
+	PriorityCrit    = 2
+	PriorityErr     = 3
+	PriorityWarning = 4
+	PriorityNotice  = 5
+	PriorityInfo    = 6
+	PriorityDebug   = 7
+)
+
+// Priority returns the syslog priority level corresponding
+// to the given severity. Unknown severities are treated
+// as errors.
+func Priority(s errgo.Severity) int {
+	return s.SyslogPriority()
+}
+
+// Fields returns the journal fields describing err, which must not
+// be nil: MESSAGE holds the error message, PRIORITY the syslog
+// priority of its severity (see errgo.SeverityOf and Priority),
+// and CODE_FILE and CODE_LINE the outermost location recorded
+// in its chain (see errgo.Locationer), if any. ERRGO_DETAILS
+// holds the details of the error (see errgo.Details).
+func Fields(err error) map[string]string {
+	fields := map[string]string{
+		"MESSAGE":       err.Error(),
+		"PRIORITY":      strconv.Itoa(Priority(errgo.SeverityOf(err))),
+		"ERRGO_DETAILS": errgo.Details(err),
+	}
+	if loc, ok := errgo.OutermostLocation(err); ok {
+		fields["CODE_FILE"] = loc.File
+		fields["CODE_LINE"] = strconv.Itoa(loc.Line)
+	}
+	return fields
+}
@@ -0,0 +1,68 @@
+package journald_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/juju/errgo"
+	"github.com/juju/errgo/journald"
+)
+
+// sourceLocations reports whether errors record the source
+// locations of their callers, which they do not in minimal
+// builds or on runtimes with limited support for finding
+// callers (see errgo.RestrictedLocationLabel).
+var sourceLocations = func() bool {
+	loc, ok := errgo.InnermostLocation(errgo.New(""))
+	return ok && loc.File != errgo.RestrictedLocationLabel
+}()
+
+func TestFields(t *testing.T) {
+	err0 := &errgo.Err{
+		Message_:  "foo",
+		Location_: errgo.Location{File: "/src/a.go", Line: 10},
+	}
+	err := &errgo.Err{
+		Message_:    "bar",
+		Underlying_: errgo.WithSeverity(err0, errgo.SeverityWarning),
+		Location_:   errgo.Location{File: "/src/b.go", Line: 20},
+	}
+	want := map[string]string{
+		"MESSAGE":       "bar: foo",
+		"PRIORITY":      "4",
+		"ERRGO_DETAILS": errgo.Details(err),
+		"CODE_FILE":     "/src/b.go",
+		"CODE_LINE":     "20",
+	}
+	if got := journald.Fields(err); !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected fields\ngot  %v\nwant %v", got, want)
+	}
+
+	fields := journald.Fields(errgo.Newf("plain"))
+	if fields["PRIORITY"] != "3" || fields["CODE_FILE"] == "" && sourceLocations {
+		t.Fatalf("unexpected fields %v", fields)
+	}
+	fields = journald.Fields(&errgo.Err{Message_: "nowhere"})
+	if _, ok := fields["CODE_FILE"]; ok {
+		t.Fatalf("unexpected fields %v", fields)
+	}
+}
+
+func TestPriority(t *testing.T) {
+	tests := []struct {
+		severity errgo.Severity
+		expect   int
+	}{
+		{errgo.SeverityDebug, journald.PriorityDebug},
+		{errgo.SeverityInfo, journald.PriorityInfo},
+		{errgo.SeverityWarning, journald.PriorityWarning},
+		{errgo.SeverityError, journald.PriorityErr},
+		{errgo.SeverityCritical, journald.PriorityCrit},
+		{0, journald.PriorityErr},
+	}
+	for _, test := range tests {
+		if got := journald.Priority(test.severity); got != test.expect {
+			t.Errorf("%v: got %d want %d", test.severity, got, test.expect)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+// The jsonerr package annotates errors returned by
+// the encoding/json package with information about
+// where in the JSON document the error occurred.
+package jsonerr
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/juju/errgo"
+)
+
+// Unmarshal is like json.Unmarshal except that any
+// error is annotated as described in Note.
+func Unmarshal(data []byte, v interface{}) error {
+	if err := json.Unmarshal(data, v); err != nil {
+		return note(err, data, v, 2)
+	}
+	return nil
+}
+
+// Note annotates an error returned when decoding the
+// JSON document held in data into v with the position in
+// the document and the type of v. If data is nil, only
+// the byte offset of the error is reported. The location
+// of the annotation is the caller of Note.
+//
+// The cause of the returned error is err, so callers
+// can still type-assert it to *json.SyntaxError or
+// *json.UnmarshalTypeError.
+//
+// If err is nil, Note returns nil.
+func Note(err error, data []byte, v interface{}) error {
+	if err == nil {
+		return nil
+	}
+	return note(err, data, v, 2)
+}
+
+func note(err error, data []byte, v interface{}, callDepth int) error {
+	var msg string
+	switch jerr := err.(type) {
+	case *json.UnmarshalTypeError:
+		field := jerr.Field
+		if field == "" {
+			field = "(top level)"
+		}
+		msg = fmt.Sprintf("cannot decode field %s of %T at %s", field, v, position(data, jerr.Offset))
+	case *json.SyntaxError:
+		msg = fmt.Sprintf("cannot decode %T at %s", v, position(data, jerr.Offset))
+	default:
+		msg = fmt.Sprintf("cannot decode %T", v)
+	}
+	newErr := &errgo.Err{
+		Message_:    msg,
+		Underlying_: err,
+		Cause_:      err,
+	}
+	newErr.SetLocation(callDepth)
+	return errgo.Created(newErr)
+}
+
+// position returns a description of the position within data
+// of an error reported after reading offset bytes. The line and column
+// are those of the last byte read.
+func position(data []byte, offset int64) string {
+	if data == nil || offset < 0 || offset > int64(len(data)) {
+		return fmt.Sprintf("offset %d", offset)
+	}
+	if offset > 0 {
+		offset--
+	}
+	before := data[:offset]
+	line := bytes.Count(before, []byte("\n")) + 1
+	column := len(before) - bytes.LastIndex(before, []byte("\n"))
+	return fmt.Sprintf("line %d, column %d", line, column)
+}
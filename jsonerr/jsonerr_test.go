@@ -0,0 +1,87 @@
+package jsonerr_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"runtime"
+	"testing"
+
+	"github.com/juju/errgo"
+	"github.com/juju/errgo/jsonerr"
+)
+
+type config struct {
+	Spec struct {
+		Port int `json:"port"`
+	} `json:"spec"`
+}
+
+// sourceLocations reports whether errors record the source
+// locations of their callers, which they do not in minimal
+// builds or on runtimes with limited support for finding
+// callers (see errgo.RestrictedLocationLabel).
+var sourceLocations = func() bool {
+	loc, ok := errgo.InnermostLocation(errgo.New(""))
+	return ok && loc.File != errgo.RestrictedLocationLabel
+}()
+
+var unmarshalTests = []struct {
+	about string
+	data  string
+	msg   string
+}{{
+	about: "type error",
+	data:  "{\n  \"spec\": {\n    \"port\": \"80\"\n  }\n}",
+	msg:   `cannot decode field spec.port of *jsonerr_test.config at line 3, column 16: json: cannot unmarshal string into Go struct field config.spec.port of type int`,
+}, {
+	about: "syntax error",
+	data:  "{\n  \"spec\": }",
+	msg:   `cannot decode *jsonerr_test.config at line 2, column 11: invalid character '}' looking for beginning of value`,
+}, {
+	about: "top level type error",
+	data:  `[]`,
+	msg:   `cannot decode field (top level) of *jsonerr_test.config at line 1, column 1: json: cannot unmarshal array into Go value of type jsonerr_test.config`,
+}}
+
+func TestUnmarshal(t *testing.T) {
+	for i, test := range unmarshalTests {
+		var c config
+		err := jsonerr.Unmarshal([]byte(test.data), &c)
+		_, _, line, _ := runtime.Caller(0)
+		if err == nil {
+			t.Errorf("test %d (%s): no error", i, test.about)
+			continue
+		}
+		if msg := err.Error(); msg != test.msg {
+			t.Errorf("test %d (%s): got %q want %q", i, test.about, msg, test.msg)
+		}
+		if cause := errgo.Cause(err); cause != err.(errgo.Wrapper).Underlying() {
+			t.Errorf("test %d (%s): unexpected cause %#v", i, test.about, cause)
+		}
+		if loc := err.(errgo.Locationer).Location(); loc.Line != line-1 && sourceLocations {
+			t.Errorf("test %d (%s): unexpected location %v", i, test.about, loc)
+		}
+	}
+	var c config
+	if err := jsonerr.Unmarshal([]byte(`{"spec": {"port": 80}}`), &c); err != nil || c.Spec.Port != 80 {
+		t.Fatalf("unexpected result %v, %#v", c, err)
+	}
+}
+
+func TestNote(t *testing.T) {
+	if err := jsonerr.Note(nil, nil, nil); err != nil {
+		t.Fatalf("unexpected error %#v", err)
+	}
+	var c config
+	jerr := json.Unmarshal([]byte(`{"spec": 1}`), &c)
+	err := jsonerr.Note(jerr, nil, &c)
+	want := "cannot decode field spec of *jsonerr_test.config at offset 10: " + jerr.Error()
+	if err.Error() != want {
+		t.Fatalf("got %q want %q", err.Error(), want)
+	}
+	other := fmt.Errorf("other")
+	err = jsonerr.Note(other, nil, &c)
+	if err.Error() != "cannot decode *jsonerr_test.config: other" || errgo.Cause(err) != other {
+		t.Fatalf("unexpected error %#v", err)
+	}
+}
@@ -0,0 +1,150 @@
+// The jujuerr package converts between errgo errors and the
+// errors of the github.com/juju/errors package, so that code
+// using either package can interoperate during a migration.
+//
+// Importing this package also registers classifications
+// (see errgo.Classify) for the juju/errors error types
+// errors.NotFound, errors.AlreadyExists, errors.Timeout
+// and errors.Forbidden.
+package jujuerr
+
+import (
+	"github.com/juju/errors"
+
+	"github.com/juju/errgo"
+)
+
+// kinds maps errgo error kinds to juju/errors error types.
+var kinds = map[errgo.Kind]errors.ConstError{
+	errgo.KindNotFound:   errors.NotFound,
+	errgo.KindExists:     errors.AlreadyExists,
+	errgo.KindTimeout:    errors.Timeout,
+	errgo.KindPermission: errors.Forbidden,
+}
+
+func init() {
+	for kind, errType := range kinds {
+		errType := errType
+		errgo.RegisterKind(kind, func(err error) bool {
+			return errors.Is(err, errType)
+		})
+	}
+}
+
+// jujuLocationer is implemented by juju/errors errors
+// that record their location.
+type jujuLocationer interface {
+	Location() (string, int)
+}
+
+// jujuErr holds an error converted from errgo. The
+// location is held separately because juju/errors
+// provides no way to set it.
+type jujuErr struct {
+	errors.Err
+	loc     errgo.Location
+	errType errors.ConstError
+}
+
+// Location implements errors.Locationer by returning
+// the file name and line number of the original error.
+func (e *jujuErr) Location() (string, int) {
+	return e.loc.File, e.loc.Line
+}
+
+// Is reports whether target is the juju/errors error type
+// corresponding to the kind of the original error (see
+// errgo.Classify), so that errors.Is can be used to check
+// for errors such as errors.NotFound.
+func (e *jujuErr) Is(target error) bool {
+	return e.errType != "" && target == e.errType
+}
+
+// ToJuju converts the error chain of err to an equivalent chain
+// of juju/errors errors. Each error in the chain that implements
+// errgo.Wrapper is converted, preserving its message, cause and
+// location; the first error that does not is used unchanged as
+// the end of the converted chain. Errors wrapped by a
+// MultiWrapper are not converted.
+//
+// If err is classified by errgo.Classify as a kind that corresponds
+// to a juju/errors error type, the returned error satisfies
+// errors.Is for that type; for example errgo.KindNotFound
+// corresponds to errors.NotFound.
+//
+// Note that the message of the returned error follows the rules
+// of juju/errors, which show the cause of an error in place of
+// its underlying error when they differ.
+func ToJuju(err error) error {
+	newErr := toJuju(err)
+	if newErr, ok := newErr.(*jujuErr); ok {
+		newErr.errType = kinds[errgo.Classify(err)]
+	}
+	return newErr
+}
+
+func toJuju(err error) error {
+	w, ok := err.(errgo.Wrapper)
+	if !ok {
+		return err
+	}
+	var underlying error
+	if w.Underlying() != nil {
+		underlying = toJuju(w.Underlying())
+	}
+	var cause error
+	if c, ok := err.(errgo.Causer); ok {
+		cause = c.Cause()
+	}
+	newErr := &jujuErr{
+		Err: *errors.Wrapf(underlying, cause, "%s", w.Message()).(*errors.Err),
+	}
+	if l, ok := err.(errgo.Locationer); ok {
+		newErr.loc = l.Location()
+	}
+	return newErr
+}
+
+// FromJuju converts the error chain of err, which may contain
+// juju/errors errors, to an equivalent chain of errgo errors.
+// Each error in the chain that implements errgo.Wrapper and
+// records a location in the manner of juju/errors is converted,
+// preserving its message, cause and location; the first error that
+// does not is used unchanged as the end of the converted chain.
+//
+// The location of an error created by juju/errors holds the
+// name of the function that created it rather than its file
+// name. Errors converted by ToJuju are restored with
+// their original locations.
+func FromJuju(err error) error {
+	newErr := fromJuju(err)
+	if newErr == err {
+		return err
+	}
+	return errgo.Created(newErr)
+}
+
+func fromJuju(err error) error {
+	w, ok := err.(interface {
+		errgo.Wrapper
+		jujuLocationer
+	})
+	if !ok {
+		return err
+	}
+	var underlying error
+	if w.Underlying() != nil {
+		underlying = fromJuju(w.Underlying())
+	}
+	var cause error
+	if c, ok := err.(errgo.Causer); ok {
+		cause = c.Cause()
+	}
+	file, line := w.Location()
+	return &errgo.Err{
+		Message_:    w.Message(),
+		Underlying_: underlying,
+		Cause_:      cause,
+		Location_:   errgo.Location{File: file, Line: line},
+	}
+}
@@ -0,0 +1,83 @@
+package jujuerr_test
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/juju/errors"
+
+	"github.com/juju/errgo"
+	"github.com/juju/errgo/jujuerr"
+)
+
+var errSentinel = errgo.New("sentinel")
+
+func TestRoundTrip(t *testing.T) {
+	err0 := errgo.WithCausef(nil, errSentinel, "foo")
+	err1 := errgo.NoteMask(err0, "bar", errgo.Any)
+	err2 := errgo.Mask(err1, errgo.Any)
+
+	jerr := jujuerr.ToJuju(err2)
+	if _, ok := jerr.(errgo.Locationer); ok {
+		t.Fatalf("converted error implements errgo.Locationer")
+	}
+	if errors.Cause(jerr) != errSentinel {
+		t.Fatalf("unexpected juju cause %#v", errors.Cause(jerr))
+	}
+	file, line := jerr.(errors.Locationer).Location()
+	if loc := err2.(errgo.Locationer).Location(); file != loc.File || line != loc.Line {
+		t.Fatalf("unexpected location %s:%d want %v", file, line, loc)
+	}
+
+	err := jujuerr.FromJuju(jerr)
+	if d := errgo.ChainDifference(err, err2); d != "" {
+		t.Fatalf("chains differ: %s", d)
+	}
+	if got, want := errgo.Details(err), errgo.Details(err2); got != want {
+		t.Fatalf("unexpected details; got %q want %q", got, want)
+	}
+	if err.Error() != err2.Error() {
+		t.Fatalf("unexpected message %q", err.Error())
+	}
+	if errgo.Cause(err) != errSentinel {
+		t.Fatalf("unexpected cause %#v", errgo.Cause(err))
+	}
+}
+
+func TestFromJuju(t *testing.T) {
+	err0 := errors.New("foo")
+	err1 := errors.Annotate(err0, "bar")
+
+	err := jujuerr.FromJuju(err1)
+	if err.Error() != "bar: foo" {
+		t.Fatalf("unexpected message %q", err.Error())
+	}
+	details := errgo.Details(err)
+	if !strings.HasPrefix(details, "[{github.com/juju/errgo/jujuerr_test.TestFromJuju:") {
+		t.Fatalf("unexpected details %q", details)
+	}
+	if err := jujuerr.FromJuju(os.ErrClosed); err != os.ErrClosed {
+		t.Fatalf("unexpected conversion of foreign error %#v", err)
+	}
+	if err := jujuerr.FromJuju(nil); err != nil {
+		t.Fatalf("unexpected conversion of nil error %#v", err)
+	}
+}
+
+func TestKinds(t *testing.T) {
+	_, openErr := os.Open("/non-existent-file")
+	jerr := jujuerr.ToJuju(errgo.Notef(errgo.Mask(openErr), "cannot read config"))
+	if !errors.Is(jerr, errors.NotFound) {
+		t.Fatalf("converted error is not errors.NotFound")
+	}
+	if errors.Is(jerr, errors.Timeout) {
+		t.Fatalf("converted error is errors.Timeout")
+	}
+	if kind := errgo.Classify(errgo.Mask(errors.NotFoundf("user"))); kind != errgo.KindNotFound {
+		t.Fatalf("unexpected kind %q", kind)
+	}
+	if kind := errgo.Classify(jujuerr.FromJuju(errors.Annotate(errors.Forbiddenf("x"), "y"))); kind != errgo.KindPermission {
+		t.Fatalf("unexpected kind %q", kind)
+	}
+}
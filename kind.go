@@ -0,0 +1,55 @@
+package errgo
+
+// Kinded holds an error along with a domain-specific kind, such
+// as a value of an enumerated type, that classifies it.
+//
+// It may be used directly or embedded in custom error types.
+type Kinded[T comparable] struct {
+	Err
+
+	// Kind_ holds the kind of the error as returned by
+	// the Kind method.
+	Kind_ T
+}
+
+// Kind returns the kind of the error.
+func (e *Kinded[T]) Kind() T {
+	return e.Kind_
+}
+
+// WithKindf returns a new error of the given kind that wraps the
+// given (possibly nil) underlying error and adds the given
+// formatted message context. The returned error is its own
+// cause (see Cause).
+func WithKindf[T comparable](underlying error, kind T, f string, a ...interface{}) error {
+	err := &Kinded[T]{
+		Err: Err{
+			Underlying_: underlying,
+		},
+		Kind_: kind,
+	}
+	err.setMessagef(f, a)
+	err.SetLocation(1)
+	return Created(err)
+}
+
+// KindOf returns the kind of the first error of kind type T found
+// in the error chain of err, looking at err and each of its
+// underlying errors in turn, and finally at the cause of err.
+// Any error with a Kind method returning T is considered.
+// It returns false if no such error is found.
+//
+// Because the underlying errors are examined, the kind is
+// found even if the cause of err has been masked.
+func KindOf[T comparable](err error) (T, bool) {
+	for e := err; e != nil; e = underlying(e) {
+		if k, ok := e.(interface{ Kind() T }); ok {
+			return k.Kind(), true
+		}
+	}
+	if k, ok := Cause(err).(interface{ Kind() T }); ok {
+		return k.Kind(), true
+	}
+	var zero T
+	return zero, false
+}
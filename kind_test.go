@@ -0,0 +1,55 @@
+package errgo_test
+
+import (
+	"testing"
+
+	"github.com/juju/errgo"
+)
+
+type testKind int
+
+const (
+	kindNone testKind = iota
+	kindConflict
+	kindQuota
+)
+
+func TestKindOf(t *testing.T) {
+	conflict := errgo.WithKindf(nil, kindConflict, "conflict %d", 1)
+	checkErr(t, conflict, nil, "conflict 1", "[{kind_test.go: conflict 1}]", conflict)
+
+	tests := []struct {
+		err    error
+		kind   testKind
+		expect bool
+	}{{
+		err:    conflict,
+		kind:   kindConflict,
+		expect: true,
+	}, {
+		err:    errgo.Notef(errgo.Mask(conflict), "bar"),
+		kind:   kindConflict,
+		expect: true,
+	}, {
+		err:    errgo.WithCausef(nil, errgo.WithKindf(nil, kindQuota, "quota"), "bar"),
+		kind:   kindQuota,
+		expect: true,
+	}, {
+		err:    errgo.WithKindf(conflict, kindQuota, "quota"),
+		kind:   kindQuota,
+		expect: true,
+	}, {
+		err: someErr,
+	}, {
+		err: nil,
+	}}
+	for i, test := range tests {
+		kind, ok := errgo.KindOf[testKind](test.err)
+		if kind != test.kind || ok != test.expect {
+			t.Errorf("test %d: got (%v, %v) want (%v, %v)", i, kind, ok, test.kind, test.expect)
+		}
+	}
+	if kind, ok := errgo.KindOf[string](conflict); ok {
+		t.Errorf("unexpected string kind %q", kind)
+	}
+}
@@ -0,0 +1,23 @@
+package errgo
+
+// ProfileLabelser can be implemented by any error type that
+// records profiler labels, such as the errors returned by
+// the WithLabels function of the pproferr package.
+type ProfileLabelser interface {
+	ProfileLabels() map[string]string
+}
+
+// ProfileLabels returns the profiler labels recorded by the
+// outermost error implementing ProfileLabelser in the chain
+// of err. It returns nil if there are none. The returned
+// map should not be modified.
+func ProfileLabels(err error) map[string]string {
+	found := Find(err, func(err error) bool {
+		err1, ok := err.(ProfileLabelser)
+		return ok && len(err1.ProfileLabels()) > 0
+	})
+	if found == nil {
+		return nil
+	}
+	return found.(ProfileLabelser).ProfileLabels()
+}
@@ -0,0 +1,91 @@
+package errgo
+
+// messageKeyErr holds an error along with the key
+// and arguments of its translatable message.
+type messageKeyErr struct {
+	Err
+	key  string
+	args []interface{}
+}
+
+// Translator returns the message for the given key and arguments
+// in the given locale, such as "fr-CA", and whether there is one.
+type Translator func(locale, key string, args []interface{}) (string, bool)
+
+// translator holds the function set by SetTranslator.
+var translator Translator
+
+// SetTranslator sets the function used by ErrorIn to translate
+// error messages. SetTranslator should be called before ErrorIn
+// is used, usually during program initialization.
+func SetTranslator(t Translator) {
+	translator = t
+}
+
+// WithMessageKey returns an error that wraps err and associates it
+// with a key identifying a translatable message describing it, along
+// with any arguments to the message, as used by ErrorIn. For
+// example:
+//
+//	return errgo.WithMessageKey(err, "user.not_found", name)
+//
+// The returned error has the same message and cause as err, and
+// holds a copy of args, so the caller may reuse its slice.
+// If err is nil, WithMessageKey returns nil.
+func WithMessageKey(err error, key string, args ...interface{}) error {
+	if err == nil {
+		return nil
+	}
+	newErr := &messageKeyErr{
+		Err: Err{
+			Underlying_: err,
+			Cause_:      Cause(err),
+		},
+		key:  key,
+		args: append([]interface{}(nil), args...),
+	}
+	newErr.SetLocation(1)
+	return Created(newErr)
+}
+
+// MessageKey returns the message key and arguments associated
+// with err by the outermost call to WithMessageKey in its chain,
+// or the empty string and nil if there are none.
+func MessageKey(err error) (key string, args []interface{}) {
+	found := Find(err, func(err error) bool {
+		_, ok := err.(*messageKeyErr)
+		return ok
+	})
+	if found == nil {
+		return "", nil
+	}
+	kerr := found.(*messageKeyErr)
+	return kerr.key, kerr.args
+}
+
+// ErrorIn returns the message of err in the given locale, for
+// showing to users. It returns the translation (see SetTranslator)
+// of the outermost message key in the chain of err (see
+// WithMessageKey) that has one in the locale, or err.Error()
+// if there is none. If err is nil, ErrorIn returns the
+// empty string.
+func ErrorIn(err error, locale string) string {
+	if err == nil {
+		return ""
+	}
+	if t := translator; t != nil {
+		var msg string
+		found := Find(err, func(err error) bool {
+			kerr, ok := err.(*messageKeyErr)
+			if !ok {
+				return false
+			}
+			msg, ok = t(locale, kerr.key, kerr.args)
+			return ok
+		})
+		if found != nil {
+			return msg
+		}
+	}
+	return err.Error()
+}
@@ -0,0 +1,84 @@
+package errgo_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/juju/errgo"
+)
+
+func translateFrench(locale, key string, args []interface{}) (string, bool) {
+	if locale != "fr" {
+		return "", false
+	}
+	switch key {
+	case "user.not_found":
+		return fmt.Sprintf("utilisateur %q introuvable", args...), true
+	case "request.failed":
+		return "la requête a échoué", true
+	}
+	return "", false
+}
+
+func TestErrorIn(t *testing.T) {
+	defer errgo.SetTranslator(nil)
+	inner := errgo.WithMessageKey(errgo.Notef(errNotFound, "no user %q", "bob"), "user.not_found", "bob")
+	tests := []struct {
+		about  string
+		err    error
+		locale string
+		expect string
+	}{{
+		about: "nil error",
+	}, {
+		about:  "translated",
+		err:    errgo.Notef(inner, "cannot get profile"),
+		locale: "fr",
+		expect: `utilisateur "bob" introuvable`,
+	}, {
+		about:  "outermost key",
+		err:    errgo.WithMessageKey(inner, "request.failed"),
+		locale: "fr",
+		expect: "la requête a échoué",
+	}, {
+		about:  "untranslated outer key",
+		err:    errgo.WithMessageKey(inner, "profile.failed"),
+		locale: "fr",
+		expect: `utilisateur "bob" introuvable`,
+	}, {
+		about:  "unknown locale",
+		err:    errgo.Notef(inner, "cannot get profile"),
+		locale: "de",
+		expect: `cannot get profile: no user "bob": not found`,
+	}, {
+		about:  "no key",
+		err:    errgo.Notef(errNotFound, "cannot get profile"),
+		locale: "fr",
+		expect: "cannot get profile: not found",
+	}}
+	errgo.SetTranslator(translateFrench)
+	for _, test := range tests {
+		if got := errgo.ErrorIn(test.err, test.locale); got != test.expect {
+			t.Errorf("%s: got %q want %q", test.about, got, test.expect)
+		}
+	}
+
+	errgo.SetTranslator(nil)
+	if got := errgo.ErrorIn(inner, "fr"); got != `no user "bob": not found` {
+		t.Errorf("unexpected message without translator %q", got)
+	}
+	key, args := errgo.MessageKey(errgo.Notef(inner, "cannot get profile"))
+	if key != "user.not_found" || len(args) != 1 || args[0] != "bob" {
+		t.Errorf("unexpected message key %q %v", key, args)
+	}
+	if key, args := errgo.MessageKey(errNotFound); key != "" || args != nil {
+		t.Errorf("unexpected message key %q %v", key, args)
+	}
+
+	args = []interface{}{"alice"}
+	err := errgo.WithMessageKey(errNotFound, "user.not_found", args...)
+	args[0] = "bob"
+	if _, got := errgo.MessageKey(err); got[0] != "alice" {
+		t.Errorf("message key arguments shared with caller: %v", got)
+	}
+}
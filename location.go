@@ -0,0 +1,372 @@
+package errgo
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	runtimedebug "runtime/debug"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// LocationFormat specifies how the file name
+// of a location is shown.
+type LocationFormat int
+
+const (
+	// LocationFull shows the file name as it was recorded,
+	// usually an absolute path, with any path mapping
+	// applied (see RegisterPathMapping), for example
+	// "/home/user/src/server/server.go:42".
+	LocationFull LocationFormat = iota
+
+	// LocationBase shows only the base name
+	// of the file, for example "server.go:42".
+	LocationBase
+
+	// LocationModule shows the file name relative to
+	// the root of the module holding the file, for example
+	// "server/server.go:42". The modules are those listed in
+	// the build information of the program, whose files
+	// are found by their module paths when the program was
+	// built with -trimpath and in the module cache otherwise,
+	// and the directories registered with RegisterPathMapping,
+	// which are taken to be module roots. Without -trimpath,
+	// the files of the main module are found below the
+	// directory holding its go.mod file, so they are only
+	// found when the source code is present where the program
+	// runs; otherwise its directory should be registered with
+	// RegisterPathMapping. Other file names are shown as for
+	// LocationFull.
+	LocationModule
+
+	// LocationEditor shows the location in a form that terminals
+	// and editors can open directly: the file name as it was
+	// recorded followed by the line and column numbers, for
+	// example "/home/user/src/server/server.go:42:1", or a URL
+	// made as arranged by SetEditorURL.
+	LocationEditor
+)
+
+// editorURL holds the pattern used to
+// show locations with LocationEditor.
+var editorURL string
+
+// SetEditorURL sets the pattern used to show locations with
+// LocationEditor. Any occurrences of "{file}" and "{line}" in
+// pattern are replaced by the file and line of each location,
+// for example:
+//
+//	errgo.SetEditorURL("vscode://file/{file}:{line}:1")
+//
+// If pattern is empty, as it is by default, locations are shown
+// as file names with line and column numbers. SetEditorURL should
+// be called before any errors are formatted, usually during
+// program initialization.
+func SetEditorURL(pattern string) {
+	editorURL = pattern
+}
+
+// pathMappings holds the mappings registered with
+// RegisterPathMapping. It holds a map[string]string that
+// is replaced rather than changed, so that locations can
+// be shown without locking. Changes are made with
+// pathMappingsMu held.
+var (
+	pathMappings   atomic.Value
+	pathMappingsMu sync.Mutex
+)
+
+// RegisterPathMapping arranges for file names starting with the given
+// path, such as the module path recorded in place of the directory
+// of the module's source code when a program is built with
+// -trimpath (see TrimmedBuild), to be shown with that path replaced
+// by dir, so that locations can be opened from a local checkout.
+// For example:
+//
+//	errgo.RegisterPathMapping("example.com/server", "/home/user/src/server")
+//
+// causes "example.com/server/conn/conn.go:42" to be shown as
+// "/home/user/src/server/conn/conn.go:42". The mapping is applied
+// when locations are shown, except with LocationBase, so the
+// recorded locations are unchanged. When several mappings apply,
+// the one with the longest path is used. With LocationModule,
+// file names in dir are shown relative to it.
+//
+// If RegisterPathMapping is called twice for the same path
+// or if either argument is empty, it panics.
+func RegisterPathMapping(path, dir string) {
+	if path == "" || dir == "" {
+		panic("errgo: RegisterPathMapping called with empty path or directory")
+	}
+	pathMappingsMu.Lock()
+	defer pathMappingsMu.Unlock()
+	old, _ := pathMappings.Load().(map[string]string)
+	if _, ok := old[path]; ok {
+		panic("errgo: RegisterPathMapping called twice for path " + path)
+	}
+	dirs := make(map[string]string, len(old)+1)
+	for p, d := range old {
+		dirs[p] = d
+	}
+	dirs[path] = dir
+	pathMappings.Store(dirs)
+}
+
+// mapPath returns file with the longest path registered
+// with RegisterPathMapping that it starts with replaced
+// by the corresponding directory.
+func mapPath(file string) string {
+	dirs, _ := pathMappings.Load().(map[string]string)
+	if len(dirs) == 0 {
+		return file
+	}
+	for prefix := file; ; {
+		i := strings.LastIndex(prefix, "/")
+		if i < 0 {
+			return file
+		}
+		prefix = prefix[:i]
+		if dir, ok := dirs[prefix]; ok {
+			return dir + file[len(prefix):]
+		}
+	}
+}
+
+// TrimmedBuild reports whether the running program was built
+// with -trimpath, in which case the file names of locations
+// hold module paths rather than directories (see
+// RegisterPathMapping).
+func TrimmedBuild() bool {
+	info, ok := runtimedebug.ReadBuildInfo()
+	if !ok {
+		return false
+	}
+	for _, setting := range info.Settings {
+		if setting.Key == "-trimpath" {
+			return setting.Value == "true"
+		}
+	}
+	return false
+}
+
+// Formatted returns the location in filename.go:99
+// format, with the file name shown according to f.
+// With LocationEditor, the column number or URL
+// is included as described there.
+func (loc Location) Formatted(f LocationFormat) string {
+	if f == LocationEditor {
+		loc.File = loc.file(LocationFull)
+		if editorURL != "" {
+			return expandLocation(editorURL, loc)
+		}
+		return loc.File + ":" + strconv.Itoa(loc.Line) + ":1"
+	}
+	return loc.file(f) + ":" + strconv.Itoa(loc.Line)
+}
+
+// expandLocation returns pattern with any occurrences of
+// "{file}" and "{line}" replaced by the file and line of loc.
+func expandLocation(pattern string, loc Location) string {
+	return strings.NewReplacer(
+		"{file}", loc.File,
+		"{line}", strconv.Itoa(loc.Line),
+	).Replace(pattern)
+}
+
+// file returns the file name of
+// loc shown according to f.
+func (loc Location) file(f LocationFormat) string {
+	if f == LocationBase {
+		return path.Base(loc.File)
+	}
+	file := mapPath(loc.File)
+	if f == LocationModule {
+		if rel, ok := moduleRelative(file); ok {
+			return rel
+		}
+	}
+	return file
+}
+
+// moduleRelative returns file relative to the root of the
+// module holding it, as described for LocationModule, and
+// whether the module was found.
+func moduleRelative(file string) (string, bool) {
+	// Use the longest directory, in case
+	// one module is nested within another.
+	dirs, _ := pathMappings.Load().(map[string]string)
+	root := ""
+	for _, dir := range dirs {
+		dir = strings.TrimSuffix(dir, "/") + "/"
+		if len(dir) > len(root) && strings.HasPrefix(file, dir) {
+			root = dir
+		}
+	}
+	if root != "" {
+		return file[len(root):], true
+	}
+	for _, prefix := range buildModules().prefixes {
+		if strings.HasPrefix(file, prefix) {
+			return file[len(prefix):], true
+		}
+		if !strings.Contains(prefix, "@") {
+			continue
+		}
+		// Files in the module cache are found below
+		// directories named for the module path, with
+		// upper-case letters escaped, and version.
+		cached := "/" + escapeModulePath(prefix)
+		if i := strings.Index(file, cached); i >= 0 {
+			return file[i+len(cached):], true
+		}
+	}
+	if root := mainModuleRoot(file); root != "" {
+		return file[len(root):], true
+	}
+	return "", false
+}
+
+var mainModuleDir struct {
+	mu sync.Mutex
+	// root holds the root directory of the main
+	// module followed by a slash, once found.
+	root string
+	// outside holds the directories known
+	// not to be within the main module.
+	outside map[string]bool
+}
+
+// mainModuleRoot returns the root directory of the main module
+// followed by a slash if file is within it, or the empty string
+// otherwise. Without -trimpath, the file names of the main module
+// hold its directory rather than its path, so the directory is
+// found by looking for the go.mod file of the main module in the
+// directories holding file. Directories that have been searched
+// are remembered, so each is searched only once.
+func mainModuleRoot(file string) string {
+	mainPath := buildModules().main
+	if mainPath == "" || !filepath.IsAbs(filepath.FromSlash(file)) {
+		return ""
+	}
+	m := &mainModuleDir
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.root == "" {
+		if m.outside == nil {
+			m.outside = make(map[string]bool)
+		}
+		m.root = findModuleRoot(file, mainPath, m.outside)
+	}
+	if m.root != "" && strings.HasPrefix(file, m.root) {
+		return m.root
+	}
+	return ""
+}
+
+// findModuleRoot returns the directory holding the go.mod file of
+// the module with the given path, followed by a slash, if it is one
+// of the directories holding file, or the empty string otherwise.
+// The directories found not to be within the module are added to
+// outside, and are not searched.
+func findModuleRoot(file, modPath string, outside map[string]bool) string {
+	var searched []string
+	for dir := path.Dir(file); !outside[dir]; dir = path.Dir(dir) {
+		searched = append(searched, dir)
+		if data, err := os.ReadFile(path.Join(dir, "go.mod")); err == nil {
+			if goModPath(data) == modPath {
+				return strings.TrimSuffix(dir, "/") + "/"
+			}
+			// The file is within another module.
+			break
+		}
+		if path.Dir(dir) == dir {
+			break
+		}
+	}
+	for _, dir := range searched {
+		outside[dir] = true
+	}
+	return ""
+}
+
+// goModPath returns the module path declared
+// by the given go.mod file contents.
+func goModPath(data []byte) string {
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) >= 2 && fields[0] == "module" {
+			return strings.Trim(fields[1], "\"`")
+		}
+	}
+	return ""
+}
+
+// escapeModulePath returns the given module path with each
+// upper-case letter replaced by an exclamation mark followed
+// by the letter in lower case, as in the module cache.
+func escapeModulePath(p string) string {
+	var b strings.Builder
+	for _, r := range p {
+		if 'A' <= r && r <= 'Z' {
+			b.WriteByte('!')
+			r += 'a' - 'A'
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// modules holds the modules listed in the
+// build information of the program.
+type modules struct {
+	// main holds the path of the main module.
+	main string
+
+	// prefixes holds the prefixes of the file names of
+	// the modules, longest first: the path of the main
+	// module followed by a slash, and the path and version
+	// of each dependency, as in "example.com/dep@v1.2.3/".
+	prefixes []string
+}
+
+var buildModulesOnce struct {
+	sync.Once
+	modules
+}
+
+// buildModules returns the modules listed in the build
+// information of the program. They are read once, as the
+// build information does not change.
+func buildModules() modules {
+	buildModulesOnce.Do(func() {
+		info, ok := runtimedebug.ReadBuildInfo()
+		if !ok {
+			return
+		}
+		var prefixes []string
+		if info.Main.Path != "" {
+			prefixes = append(prefixes, info.Main.Path+"/")
+		}
+		for _, dep := range info.Deps {
+			if dep.Replace != nil {
+				dep = dep.Replace
+			}
+			if dep.Version != "" {
+				prefixes = append(prefixes, dep.Path+"@"+dep.Version+"/")
+			} else if path.IsAbs(dep.Path) {
+				// A dependency replaced by a local directory.
+				prefixes = append(prefixes, strings.TrimSuffix(dep.Path, "/")+"/")
+			}
+		}
+		sort.Slice(prefixes, func(i, j int) bool {
+			return len(prefixes[i]) > len(prefixes[j])
+		})
+		buildModulesOnce.main = info.Main.Path
+		buildModulesOnce.prefixes = prefixes
+	})
+	return buildModulesOnce.modules
+}
@@ -0,0 +1,150 @@
+package errgo_test
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/juju/errgo"
+)
+
+func TestLocationFormatted(t *testing.T) {
+	skipIfNoSourceLocations(t)
+	err := errgo.New("foo")
+	loc := err.(errgo.Locationer).Location()
+	line := ":" + strconv.Itoa(loc.Line)
+	tests := []struct {
+		format errgo.LocationFormat
+		expect string
+	}{{
+		format: errgo.LocationFull,
+		expect: loc.String(),
+	}, {
+		format: errgo.LocationBase,
+		expect: "location_test.go" + line,
+	}, {
+		format: errgo.LocationModule,
+		expect: "location_test.go" + line,
+	}}
+	for i, test := range tests {
+		if got := loc.Formatted(test.format); got != test.expect {
+			t.Errorf("test %d: got %q want %q", i, got, test.expect)
+		}
+		details := errgo.FormatDetails(err, errgo.DetailsOptions{LocationFormat: test.format})
+		if want := "[{" + test.expect + ": foo}]"; details != want {
+			t.Errorf("test %d: got details %q want %q", i, details, want)
+		}
+	}
+
+	// The module path of the test binary is
+	// found in its build information.
+	trimmed := errgo.Location{File: "github.com/juju/errgo/sub/errors.go", Line: 10}
+	if got := trimmed.Formatted(errgo.LocationModule); got != "sub/errors.go:10" {
+		t.Errorf("unexpected module location for trimmed path %q", got)
+	}
+	// The test binary is built without -trimpath, so
+	// files of the main module are found below the
+	// directory holding its go.mod file.
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	untrimmed := errgo.Location{File: filepath.ToSlash(wd) + "/sub/errors.go", Line: 10}
+	if got := untrimmed.Formatted(errgo.LocationModule); got != "sub/errors.go:10" {
+		t.Errorf("unexpected module location for untrimmed path %q", got)
+	}
+	unknown := errgo.Location{File: "/elsewhere/errors.go", Line: 10}
+	if got := unknown.Formatted(errgo.LocationModule); got != "/elsewhere/errors.go:10" {
+		t.Errorf("unexpected module location for unknown path %q", got)
+	}
+	if got := trimmed.Formatted(errgo.LocationBase); got != "errors.go:10" {
+		t.Errorf("unexpected base location for trimmed path %q", got)
+	}
+}
+
+func TestEditorLocation(t *testing.T) {
+	skipIfNoSourceLocations(t)
+	defer errgo.SetEditorURL("")
+	err := errgo.New("foo")
+	loc := err.(errgo.Locationer).Location()
+	line := strconv.Itoa(loc.Line)
+	opts := errgo.DetailsOptions{LocationFormat: errgo.LocationEditor}
+
+	want := loc.File + ":" + line + ":1"
+	if got := loc.Formatted(errgo.LocationEditor); got != want {
+		t.Errorf("got %q want %q", got, want)
+	}
+	if got := errgo.FormatDetails(err, opts); got != "[{"+want+": foo}]" {
+		t.Errorf("unexpected details %q", got)
+	}
+
+	errgo.SetEditorURL("vscode://file/{file}:{line}:1")
+	want = "vscode://file/" + loc.File + ":" + line + ":1"
+	if got := loc.Formatted(errgo.LocationEditor); got != want {
+		t.Errorf("got %q want %q", got, want)
+	}
+	if got := errgo.FormatDetails(err, opts); got != "[{"+want+": foo}]" {
+		t.Errorf("unexpected details %q", got)
+	}
+}
+
+func init() {
+	errgo.RegisterPathMapping("example.com/mapped", "/src/mapped")
+	errgo.RegisterPathMapping("example.com/mapped/sub", "/src/sub")
+}
+
+func TestRegisterPathMapping(t *testing.T) {
+	tests := []struct {
+		file   string
+		format errgo.LocationFormat
+		expect string
+	}{{
+		file:   "example.com/mapped/server.go",
+		format: errgo.LocationFull,
+		expect: "/src/mapped/server.go:3",
+	}, {
+		file:   "example.com/mapped/sub/conn.go",
+		format: errgo.LocationFull,
+		expect: "/src/sub/conn.go:3",
+	}, {
+		file:   "example.com/mappedother/server.go",
+		format: errgo.LocationFull,
+		expect: "example.com/mappedother/server.go:3",
+	}, {
+		file:   "example.com/mapped/server.go",
+		format: errgo.LocationEditor,
+		expect: "/src/mapped/server.go:3:1",
+	}, {
+		file:   "example.com/mapped/server.go",
+		format: errgo.LocationBase,
+		expect: "server.go:3",
+	}, {
+		file:   "example.com/mapped/sub/conn.go",
+		format: errgo.LocationModule,
+		expect: "conn.go:3",
+	}}
+	for i, test := range tests {
+		loc := errgo.Location{File: test.file, Line: 3}
+		if got := loc.Formatted(test.format); got != test.expect {
+			t.Errorf("test %d: got %q want %q", i, got, test.expect)
+		}
+	}
+	if got := (errgo.Location{File: "example.com/mapped/server.go", Line: 3}).String(); got != "/src/mapped/server.go:3" {
+		t.Errorf("unexpected string %q", got)
+	}
+	if errgo.TrimmedBuild() {
+		t.Errorf("test binary unexpectedly reported as built with -trimpath")
+	}
+
+	for _, args := range [][2]string{{"example.com/mapped", "/elsewhere"}, {"", "/src"}, {"example.com/x", ""}} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("RegisterPathMapping(%q, %q) did not panic", args[0], args[1])
+				}
+			}()
+			errgo.RegisterPathMapping(args[0], args[1])
+		}()
+	}
+}
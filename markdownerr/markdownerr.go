@@ -0,0 +1,88 @@
+// The markdownerr package renders errgo errors as Markdown
+// documents, suitable for pasting into bug reports.
+package markdownerr
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/juju/errgo"
+)
+
+// ToMarkdown returns a Markdown document describing err. The
+// document holds the error message, a summary of its cause (see
+// errgo.Cause), a table with a row for each error in the chain in
+// the order they are visited by errgo.Find, and the full details
+// of the error (see errgo.Details), for example:
+//
+//	### Error
+//
+//	```
+//	cannot get user: not found
+//	```
+//
+//	**Cause:** not found (`*errors.errorString`)
+//
+//	| # | Location | Message |
+//	| --- | --- | --- |
+//	| 0 | `user.go:20` | cannot get user |
+//	| 1 | | not found |
+//
+//	### Details
+//
+//	```
+//	[{user.go:20: cannot get user} {not found}]
+//	```
+//
+// If err is nil, ToMarkdown returns the empty string.
+func ToMarkdown(err error) string {
+	if err == nil {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("### Error\n\n")
+	writeFenced(&b, err.Error())
+	if cause := errgo.Cause(err); cause != nil {
+		fmt.Fprintf(&b, "\n**Cause:** %s (`%T`)\n", cell(cause.Error()), cause)
+	}
+	b.WriteString("\n| # | Location | Message |\n| --- | --- | --- |\n")
+	for i, link := range errgo.Links(err) {
+		loc := ""
+		if link, ok := link.(errgo.Locationer); ok && link.Location().IsSet() {
+			loc = "`" + link.Location().String() + "`"
+		}
+		b.WriteString("| " + strconv.Itoa(i) + " | " + loc + " | " + cell(errgo.Message(link)) + " |\n")
+	}
+	b.WriteString("\n### Details\n\n")
+	writeFenced(&b, errgo.Details(err))
+	return b.String()
+}
+
+// writeFenced writes s to b as a fenced code block,
+// using a fence longer than any run of backquotes in s.
+func writeFenced(b *strings.Builder, s string) {
+	n, run := 0, 0
+	for _, c := range s {
+		if c != '`' {
+			run = 0
+			continue
+		}
+		if run++; run > n {
+			n = run
+		}
+	}
+	fence := strings.Repeat("`", max(n+1, 3))
+	b.WriteString(fence + "\n" + strings.TrimSuffix(s, "\n") + "\n" + fence + "\n")
+}
+
+// cell returns s escaped so that it can
+// be included in a Markdown table cell.
+func cell(s string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		"|", `\|`,
+		"\r\n", "<br>",
+		"\n", "<br>",
+	).Replace(s)
+}
@@ -0,0 +1,50 @@
+package markdownerr_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/juju/errgo"
+	"github.com/juju/errgo/markdownerr"
+)
+
+var errNotFound = errors.New("not found")
+
+func TestToMarkdown(t *testing.T) {
+	if got := markdownerr.ToMarkdown(nil); got != "" {
+		t.Fatalf("unexpected Markdown for nil error %q", got)
+	}
+	err0 := &errgo.Err{
+		Message_:  "a | b",
+		Location_: errgo.Location{File: "a.go", Line: 10},
+	}
+	err1 := &errgo.Err{
+		Message_:    "use ```x```",
+		Underlying_: err0,
+		Cause_:      errNotFound,
+		Location_:   errgo.Location{File: "b.go", Line: 20},
+	}
+	err2 := errgo.NoteMask(err1, "first\nsecond", errgo.Any)
+	got := markdownerr.ToMarkdown(err2)
+	if !strings.HasPrefix(got, "### Error\n\n````\nfirst\nsecond: use ```x```: a | b\n````\n") {
+		t.Fatalf("unexpected Markdown; got\n%s", got)
+	}
+
+	loc2 := ""
+	if loc, ok := errgo.OutermostLocation(err2); ok && loc.File != "b.go" {
+		loc2 = "`" + loc.String() + "`"
+	}
+	want := "### Error\n\n" +
+		"````\nfirst\nsecond: use ```x```: a | b\n````\n\n" +
+		"**Cause:** not found (`*errors.errorString`)\n\n" +
+		"| # | Location | Message |\n| --- | --- | --- |\n" +
+		"| 0 | " + loc2 + " | first<br>second |\n" +
+		"| 1 | `b.go:20` | use ```x``` |\n" +
+		"| 2 | `a.go:10` | a \\| b |\n\n" +
+		"### Details\n\n" +
+		"````\n" + errgo.Details(err2) + "\n````\n"
+	if got != want {
+		t.Fatalf("unexpected Markdown; got\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,370 @@
+package errgo
+
+import (
+	"encoding/binary"
+	"reflect"
+	"time"
+)
+
+// binaryVersion holds the version of the
+// encoding produced by MarshalErrorBinary.
+const binaryVersion = 1
+
+// Flags describing each link in the binary encoding.
+const (
+	linkLocation = 1 << iota
+	linkTime
+	linkBranches
+)
+
+// Kinds of cause in the binary encoding.
+const (
+	causeNone = iota
+	causeLink
+	causeMessage
+)
+
+// MarshalErrorText returns the message of err on a single
+// line, with control characters escaped as for SafeError.
+func MarshalErrorText(err error) []byte {
+	return []byte(SafeError(err))
+}
+
+// MarshalErrorBinary returns the error chain of err encoded in
+// a compact form that can be decoded with UnmarshalErrorBinary.
+// The message, location, stack frames, function, identifier and
+// creation time of each error in the chain (see Details) are
+// encoded, along with the errors wrapped by each error (see
+// MultiWrapper) and the message of its cause (see Causer). Causes
+// that are themselves in the chain are encoded as references to
+// them. Other information, such as the types of the errors, is
+// not encoded.
+func MarshalErrorBinary(err error) []byte {
+	return marshalChain([]byte{binaryVersion}, err)
+}
+
+// UnmarshalErrorBinary returns the error chain encoded in data
+// by MarshalErrorBinary. Each error in the chain is decoded as an
+// *Err, or as a *MultiErr if it wrapped several errors, and each
+// cause that is not in the chain is decoded as an *Err holding
+// its message.
+func UnmarshalErrorBinary(data []byte) (error, error) {
+	return unmarshalBinary(data)
+}
+
+// Marshaler wraps an error so that it can be passed to
+// encoders that use encoding.TextMarshaler and
+// encoding.BinaryMarshaler, such as those for YAML, TOML or
+// custom wire formats, without changing how the error itself
+// is encoded. For example:
+//
+//	yaml.Marshal(map[string]errgo.Marshaler{"error": {err}})
+//
+// The methods are not defined on Err, as they would be
+// promoted to every type that embeds it, replacing the
+// encoding of its fields with its message.
+type Marshaler struct {
+	Err error
+}
+
+// MarshalText implements encoding.TextMarshaler
+// by calling MarshalErrorText.
+func (m Marshaler) MarshalText() ([]byte, error) {
+	return MarshalErrorText(m.Err), nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler
+// by calling MarshalErrorBinary.
+func (m Marshaler) MarshalBinary() ([]byte, error) {
+	return MarshalErrorBinary(m.Err), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler
+// by setting m.Err to the result of UnmarshalErrorBinary.
+func (m *Marshaler) UnmarshalBinary(data []byte) error {
+	err, rerr := UnmarshalErrorBinary(data)
+	if rerr != nil {
+		return rerr
+	}
+	m.Err = err
+	return nil
+}
+
+// marshalChain appends the encoding of
+// the chain of err to buf.
+func marshalChain(buf []byte, err error) []byte {
+	var chain []error
+	for ; err != nil; err = underlying(err) {
+		chain = append(chain, err)
+	}
+	buf = binary.AppendUvarint(buf, uint64(len(chain)))
+	for i, link := range chain {
+		buf = marshalLink(buf, link, chain[i+1:])
+	}
+	return buf
+}
+
+// marshalLink appends the encoding of link to buf.
+// The rest of the chain below link is held in rest.
+func marshalLink(buf []byte, link error, rest []error) []byte {
+	var flags byte
+	loc, hasLoc := location(link)
+	if hasLoc {
+		flags |= linkLocation
+	}
+	t := errorTime(link)
+	if !t.IsZero() {
+		flags |= linkTime
+	}
+	errs := branches(link)
+	if len(errs) > 0 {
+		flags |= linkBranches
+	}
+	buf = append(buf, flags)
+	buf = appendString(buf, message(link))
+	if hasLoc {
+		buf = appendLocation(buf, loc)
+		var frames []Location
+		if link, ok := link.(Framer); ok {
+			frames = link.Frames()
+		}
+		buf = binary.AppendUvarint(buf, uint64(len(frames)))
+		for _, frame := range frames {
+			buf = appendLocation(buf, frame)
+		}
+		var function string
+		if link, ok := link.(Functioner); ok {
+			function = link.Function()
+		}
+		buf = appendString(buf, function)
+	}
+	var id string
+	if link, ok := link.(Identifier); ok {
+		id = link.ID()
+	}
+	buf = appendString(buf, id)
+	if !t.IsZero() {
+		buf = binary.AppendVarint(buf, t.UnixNano())
+	}
+	buf = marshalCause(buf, directCause(link), rest)
+	if len(errs) > 0 {
+		buf = binary.AppendUvarint(buf, uint64(len(errs)))
+		for _, branch := range errs {
+			buf = marshalChain(buf, branch)
+		}
+	}
+	return buf
+}
+
+// marshalCause appends the encoding of cause to buf,
+// as a reference to its position in rest if it is
+// there.
+func marshalCause(buf []byte, cause error, rest []error) []byte {
+	if cause == nil {
+		return append(buf, causeNone)
+	}
+	if reflect.TypeOf(cause).Comparable() {
+		for i, link := range rest {
+			if link == cause {
+				buf = append(buf, causeLink)
+				return binary.AppendUvarint(buf, uint64(i))
+			}
+		}
+	}
+	buf = append(buf, causeMessage)
+	return appendString(buf, cause.Error())
+}
+
+func appendString(buf []byte, s string) []byte {
+	buf = binary.AppendUvarint(buf, uint64(len(s)))
+	return append(buf, s...)
+}
+
+func appendLocation(buf []byte, loc Location) []byte {
+	buf = appendString(buf, loc.File)
+	return binary.AppendUvarint(buf, uint64(loc.Line))
+}
+
+// unmarshalBinary decodes the error chain encoded in data.
+func unmarshalBinary(data []byte) (error, error) {
+	d := &decoder{data: data}
+	if version := d.byte(); d.err == nil && version != binaryVersion {
+		return nil, Newf("cannot decode error: unknown encoding version %d", version)
+	}
+	err := d.chain()
+	if d.err == nil && len(d.data) > 0 {
+		d.err = New("unexpected trailing data")
+	}
+	if d.err != nil {
+		return nil, Notef(d.err, "cannot decode error")
+	}
+	if err == nil {
+		return nil, New("cannot decode error: empty chain")
+	}
+	return err, nil
+}
+
+// decoder decodes the encoding produced by
+// marshalChain, recording the first problem
+// found in err.
+type decoder struct {
+	data []byte
+	err  error
+}
+
+// linkRecord holds a link decoded by decoder.link
+// before the chain holding it is assembled.
+type linkRecord struct {
+	err      Err
+	causeRef int
+	branches []error
+}
+
+func (d *decoder) chain() error {
+	n := d.uvarint()
+	if d.err != nil || n > uint64(len(d.data)) {
+		d.fail()
+		return nil
+	}
+	records := make([]linkRecord, n)
+	for i := range records {
+		records[i] = d.link()
+		if d.err != nil {
+			return nil
+		}
+	}
+	var rest error
+	links := make([]error, len(records))
+	for i := len(records) - 1; i >= 0; i-- {
+		r := &records[i]
+		r.err.Underlying_ = rest
+		if r.causeRef >= 0 {
+			j := i + 1 + r.causeRef
+			if j >= len(links) {
+				d.fail()
+				return nil
+			}
+			r.err.Cause_ = links[j]
+		}
+		if r.branches != nil {
+			rest = &MultiErr{
+				Err:               r.err,
+				UnderlyingErrors_: r.branches,
+			}
+		} else {
+			rest = &r.err
+		}
+		links[i] = rest
+	}
+	return rest
+}
+
+func (d *decoder) link() linkRecord {
+	r := linkRecord{causeRef: -1}
+	flags := d.byte()
+	r.err.Message_ = d.string()
+	if flags&linkLocation != 0 {
+		r.err.Location_ = d.location()
+		if n := d.uvarint(); n > 0 && n <= uint64(len(d.data)) {
+			frames := make([]Location, n)
+			for i := range frames {
+				frames[i] = d.location()
+			}
+			r.err.Extra_ = &Extra{Frames: frames}
+		} else if n > 0 {
+			d.fail()
+		}
+		r.err.Function_ = d.string()
+	}
+	r.err.ID_ = d.string()
+	if flags&linkTime != 0 {
+		r.err.Time_ = time.Unix(0, d.varint())
+	}
+	switch d.byte() {
+	case causeNone:
+	case causeLink:
+		if n := d.uvarint(); n < 1<<31 {
+			r.causeRef = int(n)
+		} else {
+			d.fail()
+		}
+	case causeMessage:
+		r.err.Cause_ = &Err{Message_: d.string()}
+	default:
+		d.fail()
+	}
+	if flags&linkBranches != 0 {
+		n := d.uvarint()
+		if n > uint64(len(d.data)) {
+			d.fail()
+		}
+		for i := uint64(0); i < n && d.err == nil; i++ {
+			if branch := d.chain(); branch != nil {
+				r.branches = append(r.branches, branch)
+			}
+		}
+	}
+	return r
+}
+
+func (d *decoder) location() Location {
+	return Location{
+		File: d.string(),
+		Line: int(d.uvarint()),
+	}
+}
+
+func (d *decoder) string() string {
+	n := d.uvarint()
+	if d.err != nil || n > uint64(len(d.data)) {
+		d.fail()
+		return ""
+	}
+	s := string(d.data[:n])
+	d.data = d.data[n:]
+	return s
+}
+
+func (d *decoder) byte() byte {
+	if d.err != nil || len(d.data) == 0 {
+		d.fail()
+		return 0
+	}
+	b := d.data[0]
+	d.data = d.data[1:]
+	return b
+}
+
+func (d *decoder) uvarint() uint64 {
+	if d.err != nil {
+		return 0
+	}
+	x, n := binary.Uvarint(d.data)
+	if n <= 0 {
+		d.fail()
+		return 0
+	}
+	d.data = d.data[n:]
+	return x
+}
+
+func (d *decoder) varint() int64 {
+	if d.err != nil {
+		return 0
+	}
+	x, n := binary.Varint(d.data)
+	if n <= 0 {
+		d.fail()
+		return 0
+	}
+	d.data = d.data[n:]
+	return x
+}
+
+// fail records that the data is malformed,
+// unless a problem has already been found.
+func (d *decoder) fail() {
+	if d.err == nil {
+		d.err = New("malformed data")
+	}
+}
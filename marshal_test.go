@@ -0,0 +1,133 @@
+package errgo_test
+
+import (
+	"encoding"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/juju/errgo"
+)
+
+var (
+	_ encoding.TextMarshaler     = errgo.Marshaler{}
+	_ encoding.BinaryMarshaler   = errgo.Marshaler{}
+	_ encoding.BinaryUnmarshaler = (*errgo.Marshaler)(nil)
+)
+
+func TestMarshalText(t *testing.T) {
+	err := errgo.Notef(errgo.New("two\nlines"), "foo")
+	data, jerr := json.Marshal(map[string]errgo.Marshaler{"error": {err}})
+	if jerr != nil {
+		t.Fatal(jerr)
+	}
+	if got, want := string(data), `{"error":"foo: two\\nlines"}`; got != want {
+		t.Fatalf("got %s want %s", got, want)
+	}
+	var v errgo.Validation
+	v.Addf("name", "is required")
+	text := errgo.MarshalErrorText(v.Err().(*errgo.Validation).Fields()[0])
+	if got := string(text); got != "name: is required" {
+		t.Fatalf("unexpected field error text %q", got)
+	}
+}
+
+type embeddingErr struct {
+	errgo.Err
+	Code_ int
+}
+
+func (e *embeddingErr) Error() string {
+	return "embedding"
+}
+
+func TestMarshalEmbeddingTypes(t *testing.T) {
+	// Types that embed Err are encoded as structs,
+	// with their own fields intact.
+	err0 := &embeddingErr{
+		Err:   errgo.Err{Message_: "inner"},
+		Code_: 42,
+	}
+	data, jerr := json.Marshal(err0)
+	if jerr != nil {
+		t.Fatal(jerr)
+	}
+	var fields map[string]interface{}
+	if jerr := json.Unmarshal(data, &fields); jerr != nil {
+		t.Fatalf("error not encoded as an object: %s", data)
+	}
+	if fields["Code_"] != 42.0 || fields["Message_"] != "inner" {
+		t.Fatalf("unexpected encoding %s", data)
+	}
+
+	err1 := errgo.WithKindf(nil, "kind", "kinded")
+	data, jerr = json.Marshal(err1)
+	if jerr != nil {
+		t.Fatal(jerr)
+	}
+	fields = nil
+	if jerr := json.Unmarshal(data, &fields); jerr != nil || fields["Kind_"] != "kind" {
+		t.Fatalf("unexpected encoding %s", data)
+	}
+}
+
+func TestMarshalBinary(t *testing.T) {
+	defer errgo.PatchNow(func() time.Time {
+		return time.Unix(1500000000, 0)
+	})()
+	errgo.AssignIDs, errgo.RecordTimes = true, true
+	err0 := errgo.New("inner")
+	err1 := errgo.Mask(err0, errgo.Any)
+	err2 := errgo.Combine(errgo.WithCausef(nil, errNotFound, "one"), errgo.New("two"))
+	err := errgo.WithCausef(errgo.Notef(err2, "combined"), err1, "outer")
+	errgo.AssignIDs, errgo.RecordTimes = false, false
+
+	data, merr := errgo.Marshaler{err}.MarshalBinary()
+	if merr != nil {
+		t.Fatal(merr)
+	}
+	var m errgo.Marshaler
+	if uerr := m.UnmarshalBinary(data); uerr != nil {
+		t.Fatal(uerr)
+	}
+	got := m.Err.(*errgo.Err)
+	if got.Error() != err.Error() {
+		t.Fatalf("got message %q want %q", got.Error(), err.Error())
+	}
+	if d := errgo.Details(got); d != errgo.Details(err) {
+		t.Fatalf("got details %s want %s", d, errgo.Details(err))
+	}
+	if cause := errgo.Cause(got); cause.Error() != "inner" {
+		t.Fatalf("unexpected cause %#v", cause)
+	}
+	if errgo.ID(got) != errgo.ID(err) || !got.Time().Equal(err.(errgo.Timestamper).Time()) || got.Time().IsZero() && !errgo.Minimal {
+		t.Fatalf("metadata not decoded")
+	}
+
+	got1, uerr := errgo.UnmarshalErrorBinary(errgo.MarshalErrorBinary(err1))
+	if uerr != nil {
+		t.Fatal(uerr)
+	}
+	got = got1.(*errgo.Err)
+	if cause := errgo.Cause(got); cause != got.Underlying() {
+		t.Fatalf("cause in chain not decoded as reference: %#v", cause)
+	}
+
+	data = errgo.MarshalErrorBinary(err2)
+	multi, uerr := errgo.UnmarshalErrorBinary(data)
+	if uerr != nil {
+		t.Fatal(uerr)
+	}
+	if _, ok := multi.(*errgo.MultiErr); !ok {
+		t.Fatalf("multiple errors decoded as %T", multi)
+	}
+	if d := errgo.Details(multi); d != errgo.Details(err2) {
+		t.Fatalf("got details %s want %s", d, errgo.Details(err2))
+	}
+
+	for _, bad := range [][]byte{nil, {2}, {1}, {1, 1}, append(data[:len(data):len(data)], 0), data[:len(data)-1]} {
+		if _, uerr := errgo.UnmarshalErrorBinary(bad); uerr == nil {
+			t.Errorf("no error decoding %q", bad)
+		}
+	}
+}
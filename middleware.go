@@ -0,0 +1,71 @@
+package errgo
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Factory is a function that is called on every error created by
+// the constructors in this package (New, Mask, Notef and so on).
+// It returns the error that the constructor should return, which
+// will usually be err itself or an error wrapping it.
+type Factory func(err error) error
+
+// factory holds the Factory made by Use, so that errors can
+// be created without locking. Changes are made with
+// factoryMu held.
+var (
+	factory   atomic.Value
+	factoryMu sync.Mutex
+)
+
+// Use adds a middleware function to the chain of functions called
+// when an error is created. The middleware is passed the current
+// factory and should return a factory that calls it. Middleware
+// added later runs first. For example, to log every error
+// as it is created:
+//
+//	errgo.Use(func(next errgo.Factory) errgo.Factory {
+//		return func(err error) error {
+//			log.Print(errgo.Details(err))
+//			return next(err)
+//		}
+//	})
+//
+// Use is intended to be called during program initialization.
+// Middleware is not run when the package is built with the
+// errgo_minimal build tag.
+func Use(middleware func(next Factory) Factory) {
+	factoryMu.Lock()
+	defer factoryMu.Unlock()
+	next, _ := factory.Load().(Factory)
+	if next == nil {
+		next = func(err error) error {
+			return err
+		}
+	}
+	factory.Store(middleware(next))
+}
+
+// Created passes a newly created error through the middleware
+// added with Use and returns the result. It returns err unchanged
+// if no middleware has been added. It is called by all the
+// constructors in this package and should be called by
+// constructors of custom error types so that their errors
+// are treated consistently.
+//
+// Created marks err as complete if it embeds Err, so that
+// any later call to its SetLocation method panics.
+func Created(err error) error {
+	if err, ok := err.(interface{ freeze() }); ok {
+		err.freeze()
+	}
+	if minimal {
+		return err
+	}
+	f, _ := factory.Load().(Factory)
+	if f == nil || err == nil {
+		return err
+	}
+	return f(err)
+}
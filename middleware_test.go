@@ -0,0 +1,124 @@
+package errgo_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/juju/errgo"
+)
+
+func TestUseOrder(t *testing.T) {
+	skipIfMinimal(t)
+	defer errgo.ResetFactory()
+	var calls []string
+	for _, name := range []string{"a", "b"} {
+		name := name
+		errgo.Use(func(next errgo.Factory) errgo.Factory {
+			return func(err error) error {
+				calls = append(calls, name)
+				return next(err)
+			}
+		})
+	}
+	err := errgo.New("foo")
+	if err.Error() != "foo" {
+		t.Fatalf("unexpected error %q", err)
+	}
+	if want := []string{"b", "a"}; !reflect.DeepEqual(calls, want) {
+		t.Fatalf("got calls %q want %q", calls, want)
+	}
+}
+
+func TestUseAppliesToConstructors(t *testing.T) {
+	skipIfMinimal(t)
+	defer errgo.ResetFactory()
+	var created []error
+	errgo.Use(func(next errgo.Factory) errgo.Factory {
+		return func(err error) error {
+			created = append(created, err)
+			return next(err)
+		}
+	})
+	errs := []error{
+		errgo.New("a"),
+		errgo.Newf("b"),
+		errgo.NoteMask(someErr, "c"),
+		errgo.Mask(someErr),
+		errgo.Notef(someErr, "d"),
+		errgo.MaskFunc()(someErr),
+		errgo.WithCausef(nil, someErr, "e"),
+		errgo.WithKindf(someErr, 1, "f"),
+		errgo.Combine(someErr, someErr),
+		errgo.WithExitCode(someErr, 2),
+		errgo.WithHTTPStatus(someErr, 404),
+	}
+	if !reflect.DeepEqual(created, errs) {
+		t.Fatalf("got %d created errors, want %d", len(created), len(errs))
+	}
+	created = nil
+	if err := errgo.Mask(nil); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(created) != 0 {
+		t.Fatalf("factory called for nil error")
+	}
+}
+
+func TestUseAppliesToAnnotations(t *testing.T) {
+	skipIfMinimal(t)
+	defer errgo.ResetFactory()
+	var created []string
+	errgo.Use(func(next errgo.Factory) errgo.Factory {
+		return func(err error) error {
+			if err, ok := err.(*errgo.Err); ok {
+				created = append(created, err.Message())
+			}
+			return next(err)
+		}
+	})
+
+	var g errgo.Group
+	g.Go("store", func() error {
+		panic("oops")
+	})
+	g.Wait()
+
+	errgo.Retry(context.Background(), errgo.RetryPolicy{Attempts: 2}, func() error {
+		return someErr
+	})
+
+	err := someErr
+	errgo.CombineClose(&err, failCloser{}, "closing")
+
+	want := []string{
+		"panic: oops", "store",
+		"attempt 1/2", "attempt 2/2",
+		"close failed", "closing",
+	}
+	if !reflect.DeepEqual(created, want) {
+		t.Fatalf("got created errors %q want %q", created, want)
+	}
+}
+
+type taggedErr struct {
+	error
+}
+
+func TestUseReplacesError(t *testing.T) {
+	skipIfMinimal(t)
+	defer errgo.ResetFactory()
+	errgo.Use(func(next errgo.Factory) errgo.Factory {
+		return func(err error) error {
+			return next(&taggedErr{err})
+		}
+	})
+	err := errgo.Notef(someErr, "foo")
+	terr, ok := err.(*taggedErr)
+	if !ok {
+		t.Fatalf("unexpected error type %T", err)
+	}
+	if terr.Error() != "foo: "+someErr.Error() {
+		t.Fatalf("unexpected message %q", terr.Error())
+	}
+}
@@ -0,0 +1,12 @@
+//go:build errgo_minimal
+
+package errgo
+
+// minimal reports whether the package is built with the
+// errgo_minimal build tag, for latency sensitive deployments.
+// In that mode errors record no locations, stack frames,
+// identifiers (see AssignIDs) or creation times (see
+// RecordTimes), and errors are not passed through middleware
+// registered with Use, leaving only their messages, causes
+// and underlying errors.
+const minimal = true
@@ -0,0 +1,38 @@
+//go:build errgo_minimal
+
+package errgo_test
+
+import (
+	"testing"
+
+	"github.com/juju/errgo"
+)
+
+func TestMinimal(t *testing.T) {
+	errgo.AssignIDs = true
+	errgo.RecordTimes = true
+	defer func() {
+		errgo.AssignIDs = false
+		errgo.RecordTimes = false
+	}()
+	errgo.Use(func(next errgo.Factory) errgo.Factory {
+		return func(err error) error {
+			t.Errorf("middleware called for %v", err)
+			return next(err)
+		}
+	})
+	err0 := errgo.WithCausef(nil, errNotFound, "foo")
+	err := errgo.Notef(err0, "bar")
+	if msg := err.Error(); msg != "bar: foo" {
+		t.Fatalf("unexpected message %q", msg)
+	}
+	if cause := errgo.Cause(errgo.Mask(err0, errgo.Any)); cause != errNotFound {
+		t.Fatalf("unexpected cause %#v", cause)
+	}
+	if errgo.HasLocation(err) || errgo.ID(err) != "" {
+		t.Fatalf("unexpected metadata in %#v", err)
+	}
+	if got := errgo.Details(err); got != "[{bar} {foo}]" {
+		t.Fatalf("unexpected details %q", got)
+	}
+}
@@ -0,0 +1,253 @@
+package errgo
+
+import (
+	"io"
+	"strings"
+)
+
+// MultiWrapper is the type of an error that wraps several
+// other errors, for example when an operation has been attempted
+// in several places at once and more than one of them failed.
+type MultiWrapper interface {
+	// Message returns the top level error message,
+	// not including the messages from the underlying
+	// errors.
+	Message() string
+
+	// UnderlyingErrors returns the underlying errors.
+	UnderlyingErrors() []error
+}
+
+// MultiErr holds a description of an error that wraps several
+// underlying errors, along with information about where the error
+// was created.
+//
+// It may be embedded in custom error types to add extra
+// information that this errors package can understand.
+type MultiErr struct {
+	Err
+
+	// UnderlyingErrors_ holds the underlying errors.
+	UnderlyingErrors_ []error
+}
+
+// UnderlyingErrors implements MultiWrapper.
+func (e *MultiErr) UnderlyingErrors() []error {
+	return e.UnderlyingErrors_
+}
+
+// Error implements error.Error. The messages of the
+// underlying errors are separated by semicolons and joined
+// to the message of e as for Err.Error.
+func (e *MultiErr) Error() string {
+	if len(e.UnderlyingErrors_) == 0 {
+		return e.Err.Error()
+	}
+	msgs := make([]string, len(e.UnderlyingErrors_))
+	for i, err := range e.UnderlyingErrors_ {
+		msgs[i] = err.Error()
+	}
+	msg := strings.Join(msgs, "; ")
+	if e.Message_ == "" {
+		return msg
+	}
+	return joinMessage(e.Message_, msg)
+}
+
+// GoString returns the details of the receiving error
+// message, so that printing an error with %#v will
+// produce useful information.
+func (e *MultiErr) GoString() string {
+	return Details(e)
+}
+
+// Combinef returns an error that wraps all the non-nil errors
+// in errs, with the given formatted message added as context.
+// If there are no non-nil errors, it returns nil.
+//
+// The returned error has no cause, but see Causes
+// for a way to find the causes of all the underlying
+// errors.
+func Combinef(errs []error, f string, a ...interface{}) error {
+	err := combine(errs, f, a)
+	if err == nil {
+		return nil
+	}
+	err.SetLocation(1)
+	return Created(err)
+}
+
+// Combine is like Combinef but adds no message.
+func Combine(errs ...error) error {
+	err := combine(errs, "", nil)
+	if err == nil {
+		return nil
+	}
+	err.SetLocation(1)
+	return Created(err)
+}
+
+// combine returns a MultiErr wrapping the non-nil errors in
+// errs, with its message formatted from f and a if f is not
+// empty, or nil if there are no such errors.
+func combine(errs []error, f string, a []interface{}) *MultiErr {
+	var nonNil []error
+	for _, err := range errs {
+		if err != nil {
+			nonNil = append(nonNil, err)
+		}
+	}
+	if len(nonNil) == 0 {
+		return nil
+	}
+	err := &MultiErr{
+		UnderlyingErrors_: nonNil,
+	}
+	if f != "" {
+		err.setMessagef(f, a)
+	}
+	return err
+}
+
+// MaskAll returns the result of masking each non-nil error in errs
+// with the given pass functions, as Mask does, recording the
+// location of the caller of MaskAll. Nil errors are omitted; if
+// there are none, MaskAll returns nil. To wrap the results in a
+// single error, pass them to Combine:
+//
+//	return errgo.Combine(errgo.MaskAll(errs, errgo.Any)...)
+func MaskAll(errs []error, pass ...func(error) bool) []error {
+	var masked []error
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+		newErr := noteMask(err, "", pass...)
+		newErr.SetLocation(1)
+		masked = append(masked, Created(newErr))
+	}
+	return masked
+}
+
+// NotefAll is like MaskAll but adds the given formatted
+// message as context to each error, as Notef does.
+func NotefAll(errs []error, f string, a ...interface{}) []error {
+	var noted []error
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+		newErr := noteMask(err, "")
+		newErr.setMessagef(f, a)
+		newErr.SetLocation(1)
+		noted = append(noted, Created(newErr))
+	}
+	return noted
+}
+
+// First returns the first non-nil error in errs, annotated with its
+// position, for example "error 3 of 5", and the location of the
+// caller of First. The cause of the returned error is the cause
+// of that error. If all the errors are nil, First returns nil.
+//
+// It is intended for use at the end of a sequence of
+// cleanup operations, for example:
+//
+//	return errgo.First(f.Sync(), f.Close(), os.Rename(tmp, path))
+func First(errs ...error) error {
+	for i, err := range errs {
+		if err == nil {
+			continue
+		}
+		newErr := &Err{
+			Underlying_: err,
+			Cause_:      Cause(err),
+		}
+		newErr.setMessagef("error %d of %d", []interface{}{i + 1, len(errs)})
+		newErr.SetLocation(1)
+		return Created(newErr)
+	}
+	return nil
+}
+
+// CombineClose closes closer and, if that fails, records the
+// error in *errp after adding the given formatted message as
+// context. If *errp is nil, the annotated close error is stored
+// there; otherwise *errp is replaced by an error that wraps both
+// errors (see MultiErr), retaining the cause of the original error.
+// The location of the new error is the caller of CombineClose.
+//
+// It is intended to be deferred in functions that return
+// an error, for example:
+//
+//	func writeConfig(path string) (err error) {
+//		f, err := os.Create(path)
+//		if err != nil {
+//			return errgo.Mask(err)
+//		}
+//		defer errgo.CombineClose(&err, f, "closing %s", path)
+//		...
+//	}
+func CombineClose(errp *error, closer io.Closer, f string, a ...interface{}) {
+	closeErr := closer.Close()
+	if closeErr == nil {
+		return
+	}
+	newErr := &Err{
+		Underlying_: closeErr,
+	}
+	newErr.setMessagef(f, a)
+	newErr.SetLocation(1)
+	closeErr = Created(newErr)
+	if *errp == nil {
+		*errp = closeErr
+		return
+	}
+	err := combine([]error{*errp, closeErr}, "", nil)
+	err.Cause_ = Cause(*errp)
+	err.SetLocation(1)
+	*errp = Created(err)
+}
+
+// Causes returns the causes of the given error. If the cause of err
+// (see Cause) implements MultiWrapper, or is a multi-error container
+// from the github.com/hashicorp/go-multierror or go.uber.org/multierr
+// packages, or wraps several errors as errors.Join does,
+// and has no cause of its own, Causes returns the causes
+// of all its underlying errors; otherwise it returns the cause of err.
+//
+// Causes returns nil if err is nil.
+func Causes(err error) []error {
+	if err == nil {
+		return nil
+	}
+	cause := Cause(err)
+	errs := branches(cause)
+	if errs == nil || directCause(cause) != nil {
+		return []error{cause}
+	}
+	var causes []error
+	for _, err := range errs {
+		causes = append(causes, Causes(err)...)
+	}
+	return causes
+}
+
+// matchCause returns whether any of the given functions
+// returns true when called with the cause of an error
+// or, when the cause wraps several errors, any of
+// their causes (see Causes).
+func matchCause(cause error, pass ...func(error) bool) bool {
+	if match(cause, pass...) {
+		return true
+	}
+	if branches(cause) == nil {
+		return false
+	}
+	for _, c := range Causes(cause) {
+		if match(c, pass...) {
+			return true
+		}
+	}
+	return false
+}
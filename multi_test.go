@@ -0,0 +1,238 @@
+package errgo_test
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	multierror "github.com/hashicorp/go-multierror"
+	"go.uber.org/multierr"
+
+	"github.com/juju/errgo"
+	"github.com/juju/errgo/errgotest"
+)
+
+var _ errgo.MultiWrapper = (*errgo.MultiErr)(nil)
+
+func TestCombinef(t *testing.T) {
+	errs := []error{
+		errgo.New("one"),
+		nil,
+		errgo.Notef(errNotFound, "two"),
+	}
+	err := errgo.Combinef(errs, "%d of %d failed", 2, 3)
+	checkErr(t, err, nil, "2 of 3 failed: one; two: not found",
+		"[{multi_test.go: 2 of 3 failed [{multi_test.go: one}] [{multi_test.go: two} {not found}]}]", err)
+	if got := fmt.Sprintf("%#v", err); got != errgo.Details(err) {
+		t.Fatalf("unexpected GoString result %q", got)
+	}
+
+	err = errgo.Combine(errs...)
+	checkErr(t, err, nil, "one; two: not found",
+		"[{multi_test.go: [{multi_test.go: one}] [{multi_test.go: two} {not found}]}]", err)
+
+	err = errgo.Mask(err)
+	checkErr(t, err, err.(errgo.Wrapper).Underlying(), "one; two: not found",
+		"[{multi_test.go: } {multi_test.go: [{multi_test.go: one}] [{multi_test.go: two} {not found}]}]", err)
+
+	if err := errgo.Combinef([]error{nil}, "foo"); err != nil {
+		t.Fatalf("expected nil error, got %#v", err)
+	}
+	if err := errgo.Combine(); err != nil {
+		t.Fatalf("expected nil error, got %#v", err)
+	}
+}
+
+func TestMaskAll(t *testing.T) {
+	err0 := errgo.New("one")
+	errs := []error{nil, err0, nil, errNotFound}
+
+	masked := errgo.MaskAll(errs, errgo.Any)
+	if len(masked) != 2 {
+		t.Fatalf("unexpected errors %#v", masked)
+	}
+	checkErr(t, masked[0], err0, "one", "[{multi_test.go: } {multi_test.go: one}]", err0)
+	checkErr(t, masked[1], errNotFound, "not found", "[{multi_test.go: } {not found}]", errNotFound)
+
+	noted := errgo.NotefAll(errs, "task %d", 1)
+	if len(noted) != 2 {
+		t.Fatalf("unexpected errors %#v", noted)
+	}
+	checkErr(t, noted[0], err0, "task 1: one", "[{multi_test.go: task 1} {multi_test.go: one}]", noted[0])
+	checkErr(t, noted[1], errNotFound, "task 1: not found", "[{multi_test.go: task 1} {not found}]", noted[1])
+
+	if got := errgo.MaskAll([]error{nil, nil}); got != nil {
+		t.Fatalf("unexpected errors %#v", got)
+	}
+	if got := errgo.NotefAll(nil, "foo"); got != nil {
+		t.Fatalf("unexpected errors %#v", got)
+	}
+}
+
+func TestFirst(t *testing.T) {
+	err0 := errgo.WithCausef(nil, errNotFound, "two")
+	err := errgo.First(nil, err0, errgo.New("three"))
+	checkErr(t, err, err0, "error 2 of 3: two", "[{multi_test.go: error 2 of 3} {multi_test.go: two}]", errNotFound)
+	f, args := err.(*errgo.Err).FormatArgs()
+	if f != "error %d of %d" || !reflect.DeepEqual(args, []interface{}{2, 3}) {
+		t.Fatalf("unexpected format args %q %v", f, args)
+	}
+
+	if err := errgo.First(nil, nil); err != nil {
+		t.Fatalf("expected nil error, got %#v", err)
+	}
+	if err := errgo.First(); err != nil {
+		t.Fatalf("expected nil error, got %#v", err)
+	}
+}
+
+func TestCauses(t *testing.T) {
+	err0 := errgo.WithCausef(nil, errNotFound, "zero")
+	err1 := errgo.New("one")
+	multi := errgo.Combine(err0, err1, errgo.Combine(someErr))
+	tests := []struct {
+		about  string
+		err    error
+		expect []error
+	}{{
+		about: "nil error",
+	}, {
+		about:  "plain error",
+		err:    err0,
+		expect: []error{errNotFound},
+	}, {
+		about:  "multiple error",
+		err:    multi,
+		expect: []error{errNotFound, err1, someErr},
+	}, {
+		about:  "masked multiple error",
+		err:    errgo.Mask(multi),
+		expect: nil,
+	}, {
+		about:  "multiple error passed through Mask",
+		err:    errgo.Mask(multi, errgo.Is(errNotFound)),
+		expect: []error{errNotFound, err1, someErr},
+	}, {
+		about:  "multiple error with explicit cause",
+		err:    errgo.WithCausef(multi, err1, "foo"),
+		expect: []error{err1},
+	}}
+	for i, test := range tests {
+		causes := errgo.Causes(test.err)
+		if test.expect == nil && test.err != nil {
+			test.expect = []error{test.err}
+		}
+		if !reflect.DeepEqual(causes, test.expect) {
+			t.Errorf("test %d (%s): got %v want %v", i, test.about, causes, test.expect)
+		}
+	}
+}
+
+func TestMultiErrChains(t *testing.T) {
+	inner := errgo.New("inner")
+	err := errgo.Notef(errgo.Combine(errgo.New("one"), errgo.Mask(inner)), "foo")
+	if found := errgo.Find(err, errgo.Is(inner)); found != inner {
+		t.Fatalf("Find did not search branches; got %#v", found)
+	}
+
+	expect := errgo.Notef(errgo.Combine(errgo.New("one"), errgo.Mask(errgo.New("inner"))), "foo")
+	if d := errgo.ChainDifference(err, expect); d != "" {
+		t.Fatalf("unexpected difference %s", d)
+	}
+	other := errgo.Notef(errgo.Combine(errgo.New("one"), errgo.New("two")), "foo")
+	want := `link 1: underlying error 1: link 0: message "" != "two"`
+	if d := errgo.ChainDifference(err, other); d != want {
+		t.Fatalf("got difference %q want %q", d, want)
+	}
+	other = errgo.Notef(errgo.Combine(errgo.New("one")), "foo")
+	want = `link 1: 2 underlying errors != 1`
+	if d := errgo.ChainDifference(err, other); d != want {
+		t.Fatalf("got difference %q want %q", d, want)
+	}
+
+	clone := errgo.Clone(err)
+	if d := errgo.ChainDifference(err, clone); d != "" {
+		t.Fatalf("unexpected difference in clone %s", d)
+	}
+	multi := clone.(errgo.Wrapper).Underlying().(*errgo.MultiErr)
+	multi.UnderlyingErrors_[0].(*errgo.Err).Message_ = "changed"
+	if msg := err.Error(); msg != "foo: one; inner" {
+		t.Fatalf("original was changed: %q", msg)
+	}
+}
+
+type closerFunc func() error
+
+func (f closerFunc) Close() error {
+	return f()
+}
+
+func TestCombineClose(t *testing.T) {
+	closeErr := errgo.New("close failure")
+	failClose := closerFunc(func() error {
+		return closeErr
+	})
+	okClose := closerFunc(func() error {
+		return nil
+	})
+
+	var err error
+	errgo.CombineClose(&err, okClose, "closing %s", "foo")
+	if err != nil {
+		t.Fatalf("unexpected error %#v", err)
+	}
+
+	errgo.CombineClose(&err, failClose, "closing %s", "foo")
+	checkErr(t, err, closeErr, "closing foo: close failure",
+		"[{multi_test.go: closing foo} {multi_test.go: close failure}]", err)
+
+	err = errgo.WithCausef(nil, errNotFound, "bar")
+	errgo.CombineClose(&err, okClose, "closing %s", "foo")
+	errgo.CombineClose(&err, failClose, "closing %s", "foo")
+	checkErr(t, err, nil, "bar; closing foo: close failure",
+		"[{multi_test.go: [{multi_test.go: bar}] [{multi_test.go: closing foo} {multi_test.go: close failure}]}]", errNotFound)
+}
+
+func TestForeignMultiErrors(t *testing.T) {
+	one := errgo.New("one")
+	two := errgo.WithCausef(nil, errNotFound, "two")
+	tests := []struct {
+		about string
+		err   error
+	}{{
+		about: "go-multierror",
+		err:   multierror.Append(one, two),
+	}, {
+		about: "multierr",
+		err:   multierr.Combine(one, two),
+	}, {
+		about: "errors.Join",
+		err:   errors.Join(one, two),
+	}}
+	for _, test := range tests {
+		err := errgo.Notef(test.err, "bar")
+		want := "[{multi_test.go: bar} {[{multi_test.go: one}] [{multi_test.go: two}]}]"
+		if got := errgotest.NormalizeStack(errgo.Details(err), nil); got != want && sourceLocations {
+			t.Errorf("%s: unexpected details; got %q want %q", test.about, got, want)
+		}
+		if found := errgo.Find(err, errgo.Is(two)); found != two {
+			t.Errorf("%s: Find returned %#v", test.about, found)
+		}
+		causes := errgo.Causes(errgo.Mask(test.err, errgo.Is(errNotFound)))
+		if want := []error{one, errNotFound}; !reflect.DeepEqual(causes, want) {
+			t.Errorf("%s: unexpected causes %v", test.about, causes)
+		}
+	}
+}
+
+func TestWrappedErrors(t *testing.T) {
+	one := errgo.New("one")
+	two := errgo.New("two")
+	err := fmt.Errorf("ctx: %w, %w", one, two)
+	skipIfNoSourceLocations(t)
+	want := "[{ctx: one, two [{multi_test.go: one}] [{multi_test.go: two}]}]"
+	if got := errgotest.NormalizeStack(errgo.Details(err), nil); got != want {
+		t.Fatalf("unexpected details; got %q want %q", got, want)
+	}
+}
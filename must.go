@@ -0,0 +1,31 @@
+package errgo
+
+// Must returns v if err is nil. Otherwise it panics with an error
+// that wraps err, preserving its cause, and records the location of
+// the call to Must. It is intended for use in initialization code
+// that cannot continue if an error occurs, for example:
+//
+//	var tmpl = errgo.Must(template.ParseFiles("page.html"))
+func Must[T any](v T, err error) T {
+	if err != nil {
+		newErr := noteMask(err, "", Any)
+		newErr.SetLocation(1)
+		panic(Created(newErr))
+	}
+	return v
+}
+
+// MustOK returns v if ok is true. Otherwise it panics with
+// an error recording the location of the call to MustOK.
+// It is intended for use with functions that report success
+// with a boolean, for example:
+//
+//	home := errgo.MustOK(os.LookupEnv("HOME"))
+func MustOK[T any](v T, ok bool) T {
+	if !ok {
+		err := &Err{Message_: "unexpected failure"}
+		err.SetLocation(1)
+		panic(Created(err))
+	}
+	return v
+}
@@ -0,0 +1,37 @@
+package errgo_test
+
+import (
+	"testing"
+
+	"github.com/juju/errgo"
+)
+
+func TestMust(t *testing.T) {
+	if got := errgo.Must(42, nil); got != 42 {
+		t.Errorf("unexpected value %d", got)
+	}
+	err0 := errgo.WithCausef(nil, errNotFound, "foo")
+	err := mustPanic(func() {
+		errgo.Must(0, err0)
+	})
+	checkErr(t, err, err0, "foo", "[{must_test.go: } {must_test.go: foo}]", errNotFound)
+}
+
+func TestMustOK(t *testing.T) {
+	if got := errgo.MustOK("x", true); got != "x" {
+		t.Errorf("unexpected value %q", got)
+	}
+	err := mustPanic(func() {
+		errgo.MustOK("", false)
+	})
+	checkErr(t, err, nil, "unexpected failure", "[{must_test.go: unexpected failure}]", err)
+}
+
+// mustPanic calls f and returns the error it panics with.
+func mustPanic(f func()) (err error) {
+	defer func() {
+		err, _ = recover().(error)
+	}()
+	f()
+	return nil
+}
@@ -0,0 +1,39 @@
+package errgo
+
+// The functions in this file are variants of the usual
+// constructors that do not record a source location, for use in
+// hot paths where the cost of finding the caller is too high.
+// The errors they return are otherwise the same, and are shown
+// in Details as entries without a location.
+
+// MaskNoLocation is like Mask but does not record
+// the location of its caller.
+func MaskNoLocation(underlying error, pass ...func(error) bool) error {
+	if underlying == nil {
+		return nil
+	}
+	err := noteMask(underlying, "", pass...)
+	err.setMetadata()
+	return Created(err)
+}
+
+// NotefNoLocation is like Notef but does not record
+// the location of its caller.
+func NotefNoLocation(underlying error, f string, a ...interface{}) error {
+	err := noteMask(underlying, "")
+	err.setMessagef(f, a)
+	err.setMetadata()
+	return Created(err)
+}
+
+// WithCausefNoLocation is like WithCausef but does
+// not record the location of its caller.
+func WithCausefNoLocation(underlying, cause error, f string, a ...interface{}) error {
+	err := &Err{
+		Underlying_: underlying,
+		Cause_:      cause,
+	}
+	err.setMessagef(f, a)
+	err.setMetadata()
+	return Created(err)
+}
@@ -0,0 +1,33 @@
+package errgo_test
+
+import (
+	"testing"
+
+	"github.com/juju/errgo"
+)
+
+func TestNoLocation(t *testing.T) {
+	err0 := errgo.WithCausef(nil, someErr, "foo")
+
+	err := errgo.MaskNoLocation(err0, errgo.Any)
+	checkErr(t, err, err0, "foo", "[{} {noloc_test.go: foo}]", someErr)
+
+	err = errgo.NotefNoLocation(err0, "bar %d", 1)
+	checkErr(t, err, err0, "bar 1: foo", "[{bar 1} {noloc_test.go: foo}]", err)
+
+	err = errgo.WithCausefNoLocation(err0, errNotFound, "bar")
+	checkErr(t, err, err0, "bar: foo", "[{bar} {noloc_test.go: foo}]", errNotFound)
+
+	if err := errgo.MaskNoLocation(nil); err != nil {
+		t.Fatalf("expected nil got %#v", err)
+	}
+
+	skipIfMinimal(t)
+	errgo.AssignIDs = true
+	defer func() {
+		errgo.AssignIDs = false
+	}()
+	if errgo.ID(errgo.MaskNoLocation(err0)) == "" {
+		t.Fatalf("no identifier assigned")
+	}
+}
@@ -0,0 +1,27 @@
+package errgo
+
+// Functioner is implemented by errors that record the fully
+// qualified name of the function where they were created,
+// for example "example.com/store.(*DB).Get".
+type Functioner interface {
+	Function() string
+}
+
+// Origin returns the import path of the package and the name of
+// the function, such as "(*DB).Get", where the innermost error in
+// the chain of err that records its function (see Functioner) was
+// created. Errors created by this package record the function
+// along with their location. Origin returns empty strings if
+// no error in the chain records its function.
+func Origin(err error) (pkg, function string) {
+	var name string
+	for ; err != nil; err = next(err) {
+		if err, ok := err.(Functioner); ok && err.Function() != "" {
+			name = err.Function()
+		}
+	}
+	if name == "" {
+		return "", ""
+	}
+	return splitFuncName(name)
+}
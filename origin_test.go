@@ -0,0 +1,63 @@
+package errgo_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/juju/errgo"
+	"github.com/juju/errgo/internal/errhelper.v1"
+)
+
+func originHelper() error {
+	return errgo.New("foo")
+}
+
+type originT struct{}
+
+func (*originT) method() error {
+	return errgo.Notef(fmt.Errorf("plain"), "bar")
+}
+
+func TestOrigin(t *testing.T) {
+	tests := []struct {
+		about          string
+		err            error
+		expectPkg      string
+		expectFunction string
+	}{{
+		about: "nil error",
+	}, {
+		about: "foreign error",
+		err:   fmt.Errorf("plain"),
+	}, {
+		about:          "function",
+		err:            originHelper(),
+		expectPkg:      "github.com/juju/errgo_test",
+		expectFunction: "originHelper",
+	}, {
+		about:          "innermost function",
+		err:            errgo.Notef(errgo.Mask(originHelper()), "baz"),
+		expectPkg:      "github.com/juju/errgo_test",
+		expectFunction: "originHelper",
+	}, {
+		about:          "method wrapping foreign error",
+		err:            fmt.Errorf("wrapped: %w", (&originT{}).method()),
+		expectPkg:      "github.com/juju/errgo_test",
+		expectFunction: "(*originT).method",
+	}, {
+		about:          "dotted package path",
+		err:            errhelper.New("foo"),
+		expectPkg:      "github.com/juju/errgo/internal/errhelper.v1",
+		expectFunction: "New",
+	}}
+	for _, test := range tests {
+		if !sourceLocations {
+			// Errors record no functions.
+			test.expectPkg, test.expectFunction = "", ""
+		}
+		pkg, function := errgo.Origin(test.err)
+		if pkg != test.expectPkg || function != test.expectFunction {
+			t.Errorf("%s: got %q %q", test.about, pkg, function)
+		}
+	}
+}
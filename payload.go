@@ -0,0 +1,95 @@
+package errgo
+
+import "fmt"
+
+// TracePayload holds a vendor-neutral description of an error in
+// the form used by error tracking services such as Sentry, Rollbar
+// and Bugsnag, so that adapters for those services need only
+// convert it to their own format.
+type TracePayload struct {
+	// Class holds the class of the error: the type of
+	// its cause (see Cause), for example "*fs.PathError".
+	Class string
+
+	// Message holds the error message.
+	Message string
+
+	// Frames holds the stack frames of the error,
+	// innermost first.
+	Frames []TraceFrame
+}
+
+// TraceFrame holds a stack frame of a TracePayload.
+type TraceFrame struct {
+	// File holds the source file name.
+	File string
+
+	// Line holds the line number.
+	Line int
+
+	// Method holds the fully qualified name of the function
+	// holding the frame, or the empty string if it is not
+	// known.
+	Method string
+}
+
+// ToTracePayload returns a trace payload describing err, which
+// must not be nil. The frames are made from the locations of the
+// errors in the chain (see Depth), innermost first, each followed
+// by any stack frames it recorded (see SetFrameDepth), omitting
+// repeated locations. Errors with a stack registered by
+// RegisterStack contribute that stack. The function holding each
+// frame is included when it was recorded (see Functioner and
+// FrameFunctioner).
+func ToTracePayload(err error) *TracePayload {
+	p := &TracePayload{
+		Class:   fmt.Sprintf("%T", Cause(err)),
+		Message: err.Error(),
+	}
+	var chain []error
+	for e := err; e != nil; e = next(e) {
+		chain = append(chain, e)
+	}
+	seen := make(map[Location]bool)
+	add := func(loc Location, method string) {
+		if !seen[loc] {
+			seen[loc] = true
+			p.Frames = append(p.Frames, TraceFrame{
+				File:   loc.File,
+				Line:   loc.Line,
+				Method: method,
+			})
+		}
+	}
+	addFrames := func(frames []Location, funcs []string) {
+		for i, frame := range frames {
+			var method string
+			if i < len(funcs) {
+				method = funcs[i]
+			}
+			add(frame, method)
+		}
+	}
+	for i := len(chain) - 1; i >= 0; i-- {
+		loc, ok := location(chain[i])
+		if !ok {
+			if locs, funcs := stackLocations(chain[i]); len(locs) > 0 {
+				addFrames(locs, funcs)
+			}
+			continue
+		}
+		var method string
+		if link, ok := chain[i].(Functioner); ok {
+			method = link.Function()
+		}
+		add(loc, method)
+		if link, ok := chain[i].(Framer); ok {
+			var funcs []string
+			if link, ok := link.(FrameFunctioner); ok {
+				funcs = link.FrameFunctions()
+			}
+			addFrames(link.Frames(), funcs)
+		}
+	}
+	return p
+}
@@ -0,0 +1,44 @@
+package errgo_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/juju/errgo"
+)
+
+func payloadInner() error {
+	return errgo.WithCausef(nil, errNotFound, "no rows")
+}
+
+func TestToTracePayload(t *testing.T) {
+	skipIfNoSourceLocations(t)
+	err := errgo.Notef(payloadInner(), "cannot get user")
+	got := errgo.ToTracePayload(err)
+	outer := err.(errgo.Locationer).Location()
+	inner := err.(errgo.Wrapper).Underlying().(errgo.Locationer).Location()
+	want := &errgo.TracePayload{
+		Class:   "*errgo.Err",
+		Message: "cannot get user: no rows",
+		Frames: []errgo.TraceFrame{{
+			File:   inner.File,
+			Line:   inner.Line,
+			Method: "github.com/juju/errgo_test.payloadInner",
+		}, {
+			File:   outer.File,
+			Line:   outer.Line,
+			Method: "github.com/juju/errgo_test.TestToTracePayload",
+		}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected payload\ngot  %#v\nwant %#v", got, want)
+	}
+
+	got = errgo.ToTracePayload(errgo.Mask(errNotFound, errgo.Any))
+	if got.Class != "*errors.errorString" || got.Message != "not found" || len(got.Frames) != 1 {
+		t.Fatalf("unexpected payload %#v", got)
+	}
+	if got := errgo.ToTracePayload(errNotFound); len(got.Frames) != 0 {
+		t.Fatalf("unexpected frames %#v", got.Frames)
+	}
+}
@@ -0,0 +1,34 @@
+// The pkgerr package arranges for the stacks recorded by the
+// errors of the github.com/pkg/errors package to be shown by
+// errgo.Details and included by errgo.ToTracePayload, so that
+// mixed code bases do not lose them once errgo wraps them.
+// It is used for its side effects:
+//
+//	import _ "github.com/juju/errgo/pkgerr"
+package pkgerr
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/juju/errgo"
+)
+
+// stackTracer is implemented by errors created by
+// the github.com/pkg/errors package.
+type stackTracer interface {
+	error
+	StackTrace() errors.StackTrace
+}
+
+func init() {
+	errgo.RegisterStack(func(err stackTracer) []uintptr {
+		stack := err.StackTrace()
+		pcs := make([]uintptr, len(stack))
+		for i, frame := range stack {
+			// A pkg/errors Frame holds the program
+			// counter as returned by runtime.Callers.
+			pcs[i] = uintptr(frame)
+		}
+		return pcs
+	})
+}
@@ -0,0 +1,56 @@
+package pkgerr_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+
+	"github.com/juju/errgo"
+	_ "github.com/juju/errgo/pkgerr"
+)
+
+// sourceLocations reports whether errors record the source
+// locations of their callers, which they do not in minimal
+// builds or on runtimes with limited support for finding
+// callers (see errgo.RestrictedLocationLabel).
+var sourceLocations = func() bool {
+	loc, ok := errgo.InnermostLocation(errgo.New(""))
+	return ok && loc.File != errgo.RestrictedLocationLabel
+}()
+
+func TestDetails(t *testing.T) {
+	err0 := errors.New("foo")
+	err1 := &errgo.Err{
+		Message_:    "bar",
+		Underlying_: err0,
+		Location_:   errgo.Location{File: "a.go", Line: 10},
+	}
+	details := errgo.Details(err1)
+	if !strings.HasPrefix(details, "[{a.go:10: bar} {") || !strings.Contains(details, "pkgerr_test.go:") {
+		t.Fatalf("details do not include the pkg/errors location: %q", details)
+	}
+	if !strings.Contains(details, "testing.go:") || !strings.HasSuffix(details, "): foo}]") {
+		t.Fatalf("details do not include caller frames: %q", details)
+	}
+}
+
+func TestToTracePayload(t *testing.T) {
+	err0 := errors.New("foo")
+	err := &errgo.Err{
+		Message_:    "bar",
+		Underlying_: err0,
+	}
+	frames := errgo.ToTracePayload(err).Frames
+	if len(frames) < 2 {
+		t.Fatalf("unexpected frames %#v", frames)
+	}
+	if frames[0].Method != "github.com/juju/errgo/pkgerr_test.TestToTracePayload" || !strings.HasSuffix(frames[0].File, "/pkgerr_test.go") {
+		t.Fatalf("unexpected frame %#v", frames[0])
+	}
+	for _, frame := range frames[1:] {
+		if frame.Method == "" {
+			t.Fatalf("frame without method %#v", frame)
+		}
+	}
+}
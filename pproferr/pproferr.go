@@ -0,0 +1,58 @@
+// The pproferr package records the profiler labels set by
+// the runtime/pprof package in errgo errors, so that errors
+// can be matched against CPU profiles and logs taken while
+// the labels were set.
+package pproferr
+
+import (
+	"context"
+	"runtime/pprof"
+
+	"github.com/juju/errgo"
+)
+
+// labelsErr holds an error along with the profiler
+// labels that were set when it was recorded.
+type labelsErr struct {
+	errgo.Err
+	labels map[string]string
+}
+
+// ProfileLabels implements errgo.ProfileLabelser.
+func (e *labelsErr) ProfileLabels() map[string]string {
+	return e.labels
+}
+
+// WithLabels returns an error that wraps err and records the
+// profiler labels of ctx (see pprof.WithLabels and pprof.Do), such
+// as a request identifier set by an HTTP handler. The returned
+// error has the same message and cause as err. The labels can be
+// retrieved with errgo.ProfileLabels.
+//
+// The labels are read from ctx because the runtime does not
+// provide a way to read the labels of the current goroutine.
+//
+// If err is nil or ctx holds no labels,
+// WithLabels returns err unchanged.
+func WithLabels(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+	labels := make(map[string]string)
+	pprof.ForLabels(ctx, func(key, value string) bool {
+		labels[key] = value
+		return true
+	})
+	if len(labels) == 0 {
+		return err
+	}
+	newErr := &labelsErr{
+		Err: errgo.Err{
+			Underlying_: err,
+			Cause_:      errgo.Cause(err),
+		},
+		labels: labels,
+	}
+	newErr.SetLocation(1)
+	return errgo.Created(newErr)
+}
@@ -0,0 +1,45 @@
+package pproferr_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"runtime/pprof"
+	"strings"
+	"testing"
+
+	"github.com/juju/errgo"
+	"github.com/juju/errgo/pproferr"
+)
+
+var errNotFound = errors.New("not found")
+
+func TestWithLabels(t *testing.T) {
+	ctx := pprof.WithLabels(context.Background(), pprof.Labels("request_id", "r42", "handler", "users"))
+	err0 := errgo.WithCausef(nil, errNotFound, "foo")
+	err := pproferr.WithLabels(ctx, err0)
+	if err.Error() != "foo" || errgo.Cause(err) != errNotFound || errgo.Underlying(err) != err0 {
+		t.Fatalf("unexpected error %#v", err)
+	}
+	if loc, ok := err.(errgo.Locationer); ok && loc.Location().IsSet() && loc.Location().File != errgo.RestrictedLocationLabel && !strings.HasSuffix(loc.Location().File, "/pproferr_test.go") {
+		t.Fatalf("unexpected location %v", loc.Location())
+	}
+
+	want := map[string]string{
+		"request_id": "r42",
+		"handler":    "users",
+	}
+	if got := errgo.ProfileLabels(errgo.Notef(err, "bar")); !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected labels %v", got)
+	}
+
+	if got := pproferr.WithLabels(context.Background(), err0); got != err0 {
+		t.Fatalf("error without labels was wrapped: %#v", got)
+	}
+	if got := pproferr.WithLabels(ctx, nil); got != nil {
+		t.Fatalf("nil error was wrapped: %#v", got)
+	}
+	if got := errgo.ProfileLabels(err0); got != nil {
+		t.Fatalf("unexpected labels %v", got)
+	}
+}
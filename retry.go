@@ -0,0 +1,166 @@
+package errgo
+
+import (
+	"context"
+	"time"
+)
+
+// retryableErr holds an error along with an indication of
+// whether the operation that failed may be retried.
+type retryableErr struct {
+	Err
+	retryable bool
+}
+
+// WithRetryable returns an error that wraps err and records
+// whether the operation that failed may usefully be retried,
+// as reported by IsRetryable and honored by Retry.
+// The returned error has the same message and cause as err.
+// If err is nil, WithRetryable returns nil.
+func WithRetryable(err error, retryable bool) error {
+	if err == nil {
+		return nil
+	}
+	newErr := &retryableErr{
+		Err: Err{
+			Underlying_: err,
+			Cause_:      Cause(err),
+		},
+		retryable: retryable,
+	}
+	newErr.SetLocation(1)
+	return Created(newErr)
+}
+
+// IsRetryable reports whether err has been marked as retryable
+// by the outermost call to WithRetryable in its chain, or else
+// by the kind of err in the taxonomy (see SetTaxonomy). It
+// returns false if err has not been marked.
+func IsRetryable(err error) bool {
+	retryable, _ := retryability(err)
+	return retryable
+}
+
+// retryability returns whether err has been marked as retryable
+// and whether it has been marked at all, either explicitly or
+// by the taxonomy.
+func retryability(err error) (retryable, ok bool) {
+	found := Find(err, func(err error) bool {
+		_, ok := err.(*retryableErr)
+		return ok
+	})
+	if found != nil {
+		return found.(*retryableErr).retryable, true
+	}
+	if info, _ := kindInfo(err); info.Retryable != nil {
+		return *info.Retryable, true
+	}
+	return false, false
+}
+
+// RetryPolicy controls the behavior of Retry.
+type RetryPolicy struct {
+	// Attempts holds the maximum number of times to
+	// call the function. Values less than one are
+	// treated as one.
+	Attempts int
+
+	// Delay holds the time to wait before the second attempt.
+	// The time is doubled before each subsequent attempt.
+	Delay time.Duration
+
+	// MaxDelay holds the maximum time to wait between
+	// attempts. If it is zero, there is no maximum.
+	MaxDelay time.Duration
+
+	// Retryable reports whether an attempt that failed with the
+	// given error should be retried. If it is nil, all errors are
+	// retried except those marked as not retryable (see
+	// IsRetryable) and those that are fatal (see IsFatal).
+	Retryable func(error) bool
+}
+
+// Retry calls f until it succeeds, as many times as allowed by the
+// given policy, and returns nil as soon as f returns nil. Retry stops
+// early if f returns an error that should not be retried or if ctx
+// is done while waiting between attempts.
+//
+// If f never succeeds, the returned error wraps the errors returned
+// by each attempt (see MultiErr), each annotated with its attempt
+// number, for example:
+//
+//	3 of 3 attempts failed: attempt 1/3: timeout; attempt 2/3: timeout; attempt 3/3: refused
+//
+// Its cause is the cause of the error returned by the last attempt.
+// If ctx was done, its error is included last. The location of
+// the returned error and its annotations is the caller of Retry.
+func Retry(ctx context.Context, policy RetryPolicy, f func() error) error {
+	var site Err
+	site.setLocation(1)
+	attempts := policy.Attempts
+	if attempts < 1 {
+		attempts = 1
+	}
+	retryable := policy.Retryable
+	if retryable == nil {
+		retryable = func(err error) bool {
+			if retryable, ok := retryability(err); ok {
+				return retryable
+			}
+			return !IsFatal(err)
+		}
+	}
+	var errs []error
+	var last error
+	failed := 0
+	delay := policy.Delay
+	for i := 1; i <= attempts; i++ {
+		if i > 1 {
+			if err := sleep(ctx, delay); err != nil {
+				newErr := &Err{
+					Message_:    "waiting to retry",
+					Underlying_: err,
+				}
+				newErr.setSite(&site)
+				errs = append(errs, Created(newErr))
+				break
+			}
+			delay *= 2
+			if policy.MaxDelay > 0 && delay > policy.MaxDelay {
+				delay = policy.MaxDelay
+			}
+		}
+		last = f()
+		if last == nil {
+			return nil
+		}
+		failed++
+		newErr := &Err{
+			Underlying_: last,
+			Cause_:      Cause(last),
+		}
+		newErr.setMessagef("attempt %d/%d", []interface{}{i, attempts})
+		newErr.setSite(&site)
+		errs = append(errs, Created(newErr))
+		if !retryable(last) {
+			break
+		}
+	}
+	err := combine(errs, "%d of %d attempts failed", []interface{}{failed, attempts})
+	err.Cause_ = Cause(last)
+	err.SetLocation(1)
+	return Created(err)
+}
+
+// sleep waits for the given duration, returning
+// early with the context's error if ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
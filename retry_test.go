@@ -0,0 +1,122 @@
+package errgo_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/juju/errgo"
+)
+
+func TestRetry(t *testing.T) {
+	policy := errgo.RetryPolicy{
+		Attempts: 3,
+		Delay:    time.Millisecond,
+	}
+	n := 0
+	err := errgo.Retry(context.Background(), policy, func() error {
+		n++
+		if n < 2 {
+			return errgo.New("transient")
+		}
+		return nil
+	})
+	if err != nil || n != 2 {
+		t.Fatalf("unexpected result %v after %d attempts", err, n)
+	}
+
+	n = 0
+	err = errgo.Retry(context.Background(), policy, func() error {
+		n++
+		if n < 3 {
+			return errgo.New("transient")
+		}
+		return errgo.WithCausef(nil, errNotFound, "final")
+	})
+	if n != 3 {
+		t.Fatalf("unexpected attempt count %d", n)
+	}
+	want := "3 of 3 attempts failed: attempt 1/3: transient; attempt 2/3: transient; attempt 3/3: final"
+	if err.Error() != want {
+		t.Fatalf("unexpected message %q", err.Error())
+	}
+	if errgo.Cause(err) != errNotFound {
+		t.Fatalf("unexpected cause %#v", errgo.Cause(err))
+	}
+	// The error is located at the call to Retry.
+	if fn := err.(errgo.Functioner).Function(); fn != "github.com/juju/errgo_test.TestRetry" && sourceLocations {
+		t.Fatalf("unexpected function %q", fn)
+	}
+	if causes := errgo.Causes(errgo.Mask(err, errgo.Any)); len(causes) != 1 || causes[0] != errNotFound {
+		t.Fatalf("unexpected causes %v", causes)
+	}
+}
+
+func TestRetryNotRetryable(t *testing.T) {
+	n := 0
+	err := errgo.Retry(context.Background(), errgo.RetryPolicy{Attempts: 5}, func() error {
+		n++
+		return errgo.WithRetryable(errgo.New("fatal"), false)
+	})
+	if n != 1 {
+		t.Fatalf("unexpected attempt count %d", n)
+	}
+	if err.Error() != "1 of 5 attempts failed: attempt 1/5: fatal" {
+		t.Fatalf("unexpected message %q", err.Error())
+	}
+
+	n = 0
+	policy := errgo.RetryPolicy{
+		Attempts:  5,
+		Retryable: errgo.IsRetryable,
+	}
+	errgo.Retry(context.Background(), policy, func() error {
+		n++
+		if n == 1 {
+			return errgo.WithRetryable(errgo.New("busy"), true)
+		}
+		return errgo.New("unknown")
+	})
+	if n != 2 {
+		t.Fatalf("unexpected attempt count %d", n)
+	}
+}
+
+func TestRetryContextDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	n := 0
+	err := errgo.Retry(ctx, errgo.RetryPolicy{Attempts: 5, Delay: time.Hour}, func() error {
+		n++
+		cancel()
+		return errgo.WithCausef(nil, errNotFound, "foo")
+	})
+	if n != 1 {
+		t.Fatalf("unexpected attempt count %d", n)
+	}
+	want := "1 of 5 attempts failed: attempt 1/5: foo; waiting to retry: context canceled"
+	if err.Error() != want {
+		t.Fatalf("unexpected message %q", err.Error())
+	}
+	if errgo.Cause(err) != errNotFound {
+		t.Fatalf("unexpected cause %#v", errgo.Cause(err))
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	if errgo.IsRetryable(someErr) {
+		t.Fatalf("unmarked error is retryable")
+	}
+	err := errgo.WithRetryable(errgo.WithCausef(nil, errNotFound, "foo"), true)
+	if !errgo.IsRetryable(errgo.Notef(err, "bar")) {
+		t.Fatalf("marked error is not retryable")
+	}
+	if errgo.Cause(err) != errNotFound || err.Error() != "foo" {
+		t.Fatalf("unexpected error %#v", err)
+	}
+	if errgo.IsRetryable(errgo.WithRetryable(err, false)) {
+		t.Fatalf("error marked not retryable is retryable")
+	}
+	if errgo.WithRetryable(nil, true) != nil {
+		t.Fatalf("unexpected error for nil")
+	}
+}
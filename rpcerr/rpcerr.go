@@ -0,0 +1,237 @@
+// The rpcerr package converts between errgo errors and
+// the error representations used by the JSON-RPC 2.0 and
+// Twirp protocols, using a single table of mappings
+// for both protocols.
+package rpcerr
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/juju/errgo"
+)
+
+// Standard JSON-RPC 2.0 error codes.
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeInternalError  = -32603
+)
+
+// TwirpInternal is the Twirp error code used for
+// errors that have no mapping.
+const TwirpInternal = "internal"
+
+// Mapping describes how errors with a particular cause are
+// represented in RPC responses.
+type Mapping struct {
+	// Check reports whether an error cause (see errgo.Cause)
+	// belongs to this mapping. Functions such as errgo.Is
+	// and errgo.Checker may be used here.
+	Check func(error) bool
+
+	// Cause holds the error used as the cause of errors
+	// converted from an RPC response with this mapping's code.
+	// If it is nil, such errors have no cause.
+	Cause error
+
+	// JSONRPCCode holds the JSON-RPC error code. If it
+	// is zero, the mapping is not used for JSON-RPC.
+	JSONRPCCode int
+
+	// TwirpCode holds the Twirp error code, for example "not_found".
+	// If it is empty, the mapping is not used for Twirp.
+	TwirpCode string
+}
+
+// Mapper converts errors using a table of mappings. The first
+// matching mapping is used; errors that match no mapping
+// are converted to internal errors.
+type Mapper struct {
+	Mappings []Mapping
+}
+
+// JSONRPCError holds the error object of a JSON-RPC 2.0 response.
+type JSONRPCError struct {
+	Code    int        `json:"code"`
+	Message string     `json:"message"`
+	Data    *ErrorData `json:"data,omitempty"`
+}
+
+// Error implements error.Error.
+func (e *JSONRPCError) Error() string {
+	return fmt.Sprintf("%s (code %d)", e.Message, e.Code)
+}
+
+// ErrorData holds the additional data included
+// in JSON-RPC errors.
+type ErrorData struct {
+	// Details holds the details of the original error
+	// as returned by errgo.Details.
+	Details string `json:"details,omitempty"`
+
+	// Code holds the numeric code of the original
+	// error as returned by errgo.CodeOf, if any.
+	Code errgo.Code `json:"code,omitempty"`
+}
+
+// TwirpError holds the body of a Twirp error response.
+type TwirpError struct {
+	Code string            `json:"code"`
+	Msg  string            `json:"msg"`
+	Meta map[string]string `json:"meta,omitempty"`
+}
+
+// Error implements error.Error.
+func (e *TwirpError) Error() string {
+	return fmt.Sprintf("twirp error %s: %s", e.Code, e.Msg)
+}
+
+// Twirp meta keys used to hold the error details
+// and numeric code.
+const (
+	detailsKey = "details"
+	codeKey    = "code"
+)
+
+// RemoteError holds an error converted from an RPC response.
+type RemoteError struct {
+	errgo.Err
+
+	// RemoteDetails_ holds the details of the error
+	// as reported by the remote side.
+	RemoteDetails_ string
+
+	// Code_ holds the numeric code of the error
+	// as reported by the remote side, if any.
+	Code_ errgo.Code
+}
+
+// Code implements errgo.Coder.
+func (e *RemoteError) Code() errgo.Code {
+	return e.Code_
+}
+
+// RemoteDetails returns the details of the error as reported
+// by the remote side (see errgo.Details), if available.
+func (e *RemoteError) RemoteDetails() string {
+	return e.RemoteDetails_
+}
+
+// lookup returns the first mapping that matches the cause of err.
+func (m *Mapper) lookup(err error) (Mapping, bool) {
+	cause := errgo.Cause(err)
+	for _, mapping := range m.Mappings {
+		if mapping.Check != nil && mapping.Check(cause) {
+			return mapping, true
+		}
+	}
+	return Mapping{}, false
+}
+
+// ToJSONRPC returns the JSON-RPC representation of err.
+// The details and numeric code (see errgo.CodeOf)
+// of err are included in the data field.
+// It returns nil if err is nil.
+func (m *Mapper) ToJSONRPC(err error) *JSONRPCError {
+	if err == nil {
+		return nil
+	}
+	code := CodeInternalError
+	if mapping, ok := m.lookup(err); ok && mapping.JSONRPCCode != 0 {
+		code = mapping.JSONRPCCode
+	}
+	return &JSONRPCError{
+		Code:    code,
+		Message: err.Error(),
+		Data: &ErrorData{
+			Details: errgo.Details(err),
+			Code:    errgo.CodeOf(err),
+		},
+	}
+}
+
+// FromJSONRPC returns an error converted from the given
+// JSON-RPC error. The cause of the returned error is
+// the Cause field of the first mapping with a matching
+// code. The location of the error is the caller of FromJSONRPC.
+// It returns nil if e is nil.
+func (m *Mapper) FromJSONRPC(e *JSONRPCError) error {
+	if e == nil {
+		return nil
+	}
+	var cause error
+	for _, mapping := range m.Mappings {
+		if mapping.JSONRPCCode != 0 && mapping.JSONRPCCode == e.Code {
+			cause = mapping.Cause
+			break
+		}
+	}
+	var details string
+	var code errgo.Code
+	if e.Data != nil {
+		details, code = e.Data.Details, e.Data.Code
+	}
+	return newRemoteError(e.Message, cause, details, code)
+}
+
+// ToTwirp returns the Twirp representation of err.
+// The details of err are included in the meta
+// field under the key "details" and its numeric
+// code (see errgo.CodeOf), if any, under "code".
+// It returns nil if err is nil.
+func (m *Mapper) ToTwirp(err error) *TwirpError {
+	if err == nil {
+		return nil
+	}
+	code := TwirpInternal
+	if mapping, ok := m.lookup(err); ok && mapping.TwirpCode != "" {
+		code = mapping.TwirpCode
+	}
+	e := &TwirpError{
+		Code: code,
+		Msg:  err.Error(),
+		Meta: map[string]string{
+			detailsKey: errgo.Details(err),
+		},
+	}
+	if code := errgo.CodeOf(err); code != 0 {
+		e.Meta[codeKey] = strconv.Itoa(int(code))
+	}
+	return e
+}
+
+// FromTwirp returns an error converted from the given
+// Twirp error. The cause of the returned error is
+// the Cause field of the first mapping with a matching
+// code. The location of the error is the caller of FromTwirp.
+// It returns nil if e is nil.
+func (m *Mapper) FromTwirp(e *TwirpError) error {
+	if e == nil {
+		return nil
+	}
+	var cause error
+	for _, mapping := range m.Mappings {
+		if mapping.TwirpCode != "" && mapping.TwirpCode == e.Code {
+			cause = mapping.Cause
+			break
+		}
+	}
+	code, _ := strconv.Atoi(e.Meta[codeKey])
+	return newRemoteError(e.Msg, cause, e.Meta[detailsKey], errgo.Code(code))
+}
+
+func newRemoteError(msg string, cause error, details string, code errgo.Code) error {
+	err := &RemoteError{
+		Err: errgo.Err{
+			Message_: msg,
+			Cause_:   cause,
+		},
+		RemoteDetails_: details,
+		Code_:          code,
+	}
+	err.SetLocation(2)
+	return errgo.Created(err)
+}
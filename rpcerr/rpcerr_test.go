@@ -0,0 +1,124 @@
+package rpcerr_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/juju/errgo"
+	"github.com/juju/errgo/rpcerr"
+)
+
+var (
+	errNotFound     = errgo.New("not found")
+	errUnauthorized = errgo.New("unauthorized")
+)
+
+var mapper = &rpcerr.Mapper{
+	Mappings: []rpcerr.Mapping{{
+		Check:       errgo.Is(errNotFound),
+		Cause:       errNotFound,
+		JSONRPCCode: -32001,
+		TwirpCode:   "not_found",
+	}, {
+		Check:       errgo.Is(errUnauthorized),
+		Cause:       errUnauthorized,
+		JSONRPCCode: -32002,
+		TwirpCode:   "unauthenticated",
+	}},
+}
+
+func TestJSONRPC(t *testing.T) {
+	if e := mapper.ToJSONRPC(nil); e != nil {
+		t.Fatalf("unexpected error %#v", e)
+	}
+	err := errgo.NoteMask(errgo.Mask(errNotFound, errgo.Any), "no user", errgo.Any)
+	err = errgo.NoteMask(err, "cannot get user", errgo.Any)
+	e := mapper.ToJSONRPC(err)
+	if e.Code != -32001 || e.Message != "cannot get user: no user: not found" || e.Data.Details != errgo.Details(err) {
+		t.Fatalf("unexpected JSON-RPC error %#v", e)
+	}
+	data, jerr := json.Marshal(e)
+	if jerr != nil {
+		t.Fatal(jerr)
+	}
+	var e1 rpcerr.JSONRPCError
+	if jerr := json.Unmarshal(data, &e1); jerr != nil {
+		t.Fatal(jerr)
+	}
+	err1 := mapper.FromJSONRPC(&e1)
+	if errgo.Cause(err1) != errNotFound {
+		t.Fatalf("unexpected cause %#v", errgo.Cause(err1))
+	}
+	if err1.Error() != e.Message || err1.(*rpcerr.RemoteError).RemoteDetails() != errgo.Details(err) {
+		t.Fatalf("unexpected error %#v", err1)
+	}
+
+	e = mapper.ToJSONRPC(errgo.Notef(errNotFound, "masked"))
+	if e.Code != rpcerr.CodeInternalError {
+		t.Fatalf("unexpected code %d", e.Code)
+	}
+	err1 = mapper.FromJSONRPC(e)
+	if errgo.Cause(err1) != err1 {
+		t.Fatalf("unexpected cause %#v", errgo.Cause(err1))
+	}
+
+	// A mapping without a JSON-RPC code is ignored.
+	twirpOnly := &rpcerr.Mapper{
+		Mappings: []rpcerr.Mapping{{
+			Check:     errgo.Is(errNotFound),
+			Cause:     errNotFound,
+			TwirpCode: "not_found",
+		}},
+	}
+	e = twirpOnly.ToJSONRPC(errgo.Mask(errNotFound, errgo.Any))
+	if e.Code != rpcerr.CodeInternalError {
+		t.Fatalf("unexpected code %d", e.Code)
+	}
+	if err1 := twirpOnly.FromJSONRPC(&rpcerr.JSONRPCError{Message: "foo"}); errgo.Cause(err1) != err1 {
+		t.Fatalf("unexpected cause %#v", errgo.Cause(err1))
+	}
+}
+
+func TestTwirp(t *testing.T) {
+	if e := mapper.ToTwirp(nil); e != nil {
+		t.Fatalf("unexpected error %#v", e)
+	}
+	err := errgo.Mask(errUnauthorized, errgo.Any)
+	e := mapper.ToTwirp(err)
+	if e.Code != "unauthenticated" || e.Msg != "unauthorized" || e.Meta["details"] != errgo.Details(err) {
+		t.Fatalf("unexpected Twirp error %#v", e)
+	}
+	err1 := mapper.FromTwirp(e)
+	if errgo.Cause(err1) != errUnauthorized {
+		t.Fatalf("unexpected cause %#v", errgo.Cause(err1))
+	}
+	if e := mapper.ToTwirp(errgo.New("other")); e.Code != rpcerr.TwirpInternal {
+		t.Fatalf("unexpected code %q", e.Code)
+	}
+}
+
+func TestNumericCode(t *testing.T) {
+	err := errgo.WithCode(errgo.New("quota exceeded"), 4001)
+	e := mapper.ToJSONRPC(err)
+	data, jerr := json.Marshal(e)
+	if jerr != nil {
+		t.Fatal(jerr)
+	}
+	var e1 rpcerr.JSONRPCError
+	if jerr := json.Unmarshal(data, &e1); jerr != nil {
+		t.Fatal(jerr)
+	}
+	if code := errgo.CodeOf(mapper.FromJSONRPC(&e1)); code != 4001 {
+		t.Fatalf("unexpected JSON-RPC code %d", code)
+	}
+	te := mapper.ToTwirp(err)
+	if te.Meta["code"] != "4001" {
+		t.Fatalf("unexpected Twirp meta %v", te.Meta)
+	}
+	if code := errgo.CodeOf(mapper.FromTwirp(te)); code != 4001 {
+		t.Fatalf("unexpected Twirp code %d", code)
+	}
+	if _, ok := mapper.ToTwirp(errgo.New("other")).Meta["code"]; ok {
+		t.Fatalf("unexpected code in Twirp meta")
+	}
+}
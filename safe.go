@@ -0,0 +1,209 @@
+package errgo
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// EscapeMessages controls whether single-line renderings of error
+// messages escape the separators within the message of each error in
+// a chain, so that the rendering can be split back into the
+// messages with SplitMessage even when an annotation itself holds
+// a separator. When it is true, backslashes are doubled and each
+// separator within a message is preceded by a backslash in the
+// output of SafeError (and so MarshalErrorText and the messages
+// rendered by the gelf and datadog packages), which uses
+// MessageSeparator, and of OneLine, which uses " <- ".
+// For example, an error annotated with "cannot parse: x" is
+// rendered by SafeError as
+//
+//	cannot parse\: x: unexpected EOF
+//
+// The output of Error is unaffected. EscapeMessages should be set
+// before any errors are formatted, usually during program
+// initialization.
+var EscapeMessages = false
+
+// SafeError returns the message of err (see error.Error) in a
+// form that is safe to include in line-oriented output such as log
+// files: newlines, tabs and other control characters are replaced
+// by Go-style escape sequences, so the result always fits on a
+// single line. Details is unaffected.
+//
+// If err is nil, SafeError returns the empty string.
+func SafeError(err error) string {
+	if err == nil {
+		return ""
+	}
+	if EscapeMessages {
+		return escapedMessage(err)
+	}
+	return sanitize(err.Error())
+}
+
+// escapedMessage returns the message of err as for Error,
+// with each message in the chain escaped as described
+// for EscapeMessages and control characters escaped as
+// for SafeError.
+func escapedMessage(err error) string {
+	var msgs []string
+	for e := err; e != nil; e = underlying(e) {
+		msg := message(e)
+		if errs := branches(e); len(errs) > 0 {
+			// The messages of the wrapped errors are
+			// kept together, as they are not a chain.
+			branchMsgs := make([]string, len(errs))
+			for i, branch := range errs {
+				branchMsgs[i] = branch.Error()
+			}
+			if msg != "" {
+				msgs = append(msgs, msg)
+			}
+			msgs = append(msgs, strings.Join(branchMsgs, "; "))
+			break
+		}
+		if msg == "" && underlying(e) == nil {
+			msg = e.Error()
+		}
+		if msg == "" || DeduplicateMessages && msg == nextMessage(underlying(e)) {
+			continue
+		}
+		msgs = append(msgs, msg)
+	}
+	if UnderlyingFirst {
+		for i, j := 0, len(msgs)-1; i < j; i, j = i+1, j-1 {
+			msgs[i], msgs[j] = msgs[j], msgs[i]
+		}
+	}
+	for i, msg := range msgs {
+		msgs[i] = escapeMessage(msg, MessageSeparator)
+	}
+	return strings.Join(msgs, MessageSeparator)
+}
+
+// escapeMessage returns msg with backslashes doubled,
+// each occurrence of sep preceded by a backslash and
+// control characters escaped.
+func escapeMessage(msg, sep string) string {
+	msg = strings.ReplaceAll(msg, `\`, `\\`)
+	if sep != "" {
+		msg = strings.ReplaceAll(msg, sep, `\`+sep)
+	}
+	return sanitize(msg)
+}
+
+// SplitMessage splits s, an error message rendered with
+// EscapeMessages set, into the messages of the errors in its chain,
+// reversing the escaping of separators and control characters. The
+// separator sep should be the one used in the rendering, such as
+// MessageSeparator, or " <- " for OneLine once the locations
+// have been removed from its output; separators that hold
+// backslashes or control characters cannot be split reliably.
+// For example:
+//
+//	errgo.SplitMessage(`cannot parse\: x: unexpected EOF`, ": ")
+//
+// returns []string{"cannot parse: x", "unexpected EOF"}.
+func SplitMessage(s, sep string) []string {
+	var msgs []string
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		switch {
+		case sep != "" && strings.HasPrefix(s[i:], sep):
+			msgs = append(msgs, b.String())
+			b.Reset()
+			i += len(sep)
+		case s[i] == '\\' && i+1 < len(s):
+			i += 1 + unescape(&b, s[i+1:], sep)
+		default:
+			b.WriteByte(s[i])
+			i++
+		}
+	}
+	return append(msgs, b.String())
+}
+
+// unescape writes the character escaped at the start of s
+// (following a backslash) to b and returns the number of
+// bytes of s that were used.
+func unescape(b *strings.Builder, s, sep string) int {
+	switch {
+	case sep != "" && strings.HasPrefix(s, sep):
+		b.WriteString(sep)
+		return len(sep)
+	case s[0] == 'n':
+		b.WriteByte('\n')
+	case s[0] == 'r':
+		b.WriteByte('\r')
+	case s[0] == 't':
+		b.WriteByte('\t')
+	case s[0] == 'x' && len(s) >= 3:
+		if r, err := strconv.ParseUint(s[1:3], 16, 8); err == nil {
+			b.WriteRune(rune(r))
+			return 3
+		}
+		b.WriteByte(s[0])
+	default:
+		b.WriteByte(s[0])
+	}
+	return 1
+}
+
+// sanitize returns s with all control characters escaped.
+func sanitize(s string) string {
+	if strings.IndexFunc(s, unicode.IsControl) == -1 {
+		return s
+	}
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\r':
+			b.WriteString(`\r`)
+		case r == '\t':
+			b.WriteString(`\t`)
+		case unicode.IsControl(r):
+			fmt.Fprintf(&b, `\x%02x`, r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// OneLine returns a compact single-line description of the error
+// chain of err, suitable for log sinks that cannot handle the output
+// of Details. The non-empty messages of the errors in the chain
+// (see Wrapper) are listed outermost first, separated by " <- ",
+// followed by their source locations in the same order,
+// for example:
+//
+//	cannot get user <- query failed <- connection refused (user.go:20 → db.go:99)
+//
+// Control characters in messages are escaped as for SafeError,
+// and occurrences of " <- " within messages are escaped if
+// EscapeMessages is set. If err is nil, OneLine returns
+// the empty string.
+func OneLine(err error) string {
+	var msgs, locs []string
+	for ; err != nil; err = underlying(err) {
+		if msg := message(err); msg != "" {
+			if EscapeMessages {
+				msgs = append(msgs, escapeMessage(msg, " <- "))
+			} else {
+				msgs = append(msgs, sanitize(msg))
+			}
+		}
+		if err, ok := err.(Locationer); ok && err.Location().IsSet() {
+			locs = append(locs, err.Location().String())
+		}
+	}
+	s := strings.Join(msgs, " <- ")
+	if len(locs) > 0 {
+		s += " (" + sanitize(strings.Join(locs, " → ")) + ")"
+	}
+	return s
+}
@@ -0,0 +1,102 @@
+package errgo_test
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/juju/errgo"
+	"github.com/juju/errgo/errgotest"
+)
+
+func TestSafeError(t *testing.T) {
+	tests := []struct {
+		err    error
+		expect string
+	}{{
+		err:    nil,
+		expect: "",
+	}, {
+		err:    errgo.New("plain message"),
+		expect: "plain message",
+	}, {
+		err:    errgo.Notef(fmt.Errorf("line one\nline two"), "foo"),
+		expect: `foo: line one\nline two`,
+	}, {
+		err:    errgo.New("a\tb\r\x00c\u0085d é"),
+		expect: `a\tb\r\x00c\x85d é`,
+	}}
+	for i, test := range tests {
+		if got := errgo.SafeError(test.err); got != test.expect {
+			t.Errorf("test %d: got %q want %q", i, got, test.expect)
+		}
+	}
+}
+
+func TestOneLine(t *testing.T) {
+	err0 := fmt.Errorf("connection\nrefused")
+	err1 := errgo.Notef(err0, "query failed")
+	err2 := errgo.Mask(err1)
+	err3 := errgo.Notef(err2, "cannot get user")
+
+	tests := []struct {
+		err    error
+		expect string
+	}{{
+		err:    nil,
+		expect: "",
+	}, {
+		err:    err0,
+		expect: `connection\nrefused`,
+	}, {
+		err:    err3,
+		expect: `cannot get user <- query failed <- connection\nrefused (safe_test.go → safe_test.go → safe_test.go)`,
+	}}
+	for i, test := range tests {
+		if !sourceLocations && strings.Contains(test.expect, "safe_test.go") {
+			continue
+		}
+		if got := errgotest.NormalizeStack(errgo.OneLine(test.err), nil); got != test.expect {
+			t.Errorf("test %d: got %q want %q", i, got, test.expect)
+		}
+	}
+}
+
+func TestEscapeMessages(t *testing.T) {
+	defer func() {
+		errgo.EscapeMessages = false
+	}()
+	errgo.EscapeMessages = true
+	err := errgo.Notef(errgo.Newf(`bad path C:\tmp`), "cannot parse: line\n2")
+	err = errgo.Notef(errgo.Mask(err), "config <- %q", "x: y")
+
+	safe := errgo.SafeError(err)
+	if want := `config <- "x\: y": cannot parse\: line\n2: bad path C:\\tmp`; safe != want {
+		t.Fatalf("unexpected SafeError\ngot  %s\nwant %s", safe, want)
+	}
+	want := []string{`config <- "x: y"`, "cannot parse: line\n2", `bad path C:\tmp`}
+	if got := errgo.SplitMessage(safe, ": "); !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected split %q", got)
+	}
+
+	oneLine := errgo.OneLine(err)
+	msgs := oneLine
+	if i := strings.LastIndex(oneLine, " ("); i >= 0 {
+		// Strip the trailing locations.
+		msgs = oneLine[:i]
+	}
+	if got := errgo.SplitMessage(msgs, " <- "); !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected OneLine split %q from %q", got, oneLine)
+	}
+
+	// Foreign errors and errors wrapping several errors.
+	err = errgo.Notef(errgo.Combine(errors.New("a: b"), errors.New("c")), "d")
+	if got := errgo.SafeError(err); got != `d: a\: b; c` {
+		t.Fatalf("unexpected SafeError %q", got)
+	}
+	if got := errgo.SafeError(fmt.Errorf("e: %w", errNotFound)); got != `e\: not found` {
+		t.Fatalf("unexpected SafeError %q", got)
+	}
+}
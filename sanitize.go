@@ -0,0 +1,127 @@
+package errgo
+
+// SanitizePolicy holds the policy used by Sanitize.
+type SanitizePolicy struct {
+	// DropLocations causes the source locations
+	// recorded by errors to be removed.
+	DropLocations bool
+
+	// DropMessages causes the messages of errors to be
+	// removed, except for those of errors reported as
+	// public by Public.
+	DropMessages bool
+
+	// Public reports whether the message of the given error
+	// in the chain may be kept when DropMessages is set.
+	// If it is nil, no messages are kept.
+	Public func(err error) bool
+
+	// Message holds the message of the sanitized error
+	// when none of the messages in the chain are kept.
+	// If it is empty, "internal error" is used.
+	Message string
+}
+
+// Sanitize returns a copy of the error chain of err that is safe
+// to return to clients, for use at the boundary of a service. Each
+// error in the chain (see Details) is replaced by an *Err holding
+// its message and location as allowed by policy, and errors that
+// are left without a message are omitted unless they record a
+// location and are not the last in the chain. Errors wrapped by an
+// error (see MultiWrapper) are sanitized in turn. If no errors are
+// kept, the chain is replaced by a single error holding
+// policy.Message. The returned error is its own cause, so
+// no internal errors can be reached from it.
+//
+// The kind (see Classify), code (see CodeOf) and HTTP status
+// (see HTTPStatus) of err are kept, so that the returned
+// error can be handled in the same way as err.
+//
+// If err is nil, Sanitize returns nil.
+func Sanitize(err error, policy SanitizePolicy) error {
+	if err == nil {
+		return nil
+	}
+	newErr := sanitizeChain(err, policy)
+	if newErr == nil {
+		msg := policy.Message
+		if msg == "" {
+			msg = "internal error"
+		}
+		newErr = &Err{Message_: msg}
+	}
+	if status := HTTPStatus(err); status != statusInternalServerError {
+		newErr = &statusErr{
+			Err: Err{
+				Underlying_: newErr,
+				Cause_:      Cause(newErr),
+			},
+			status: status,
+		}
+	}
+	if code := CodeOf(err); code != 0 {
+		newErr = &codeErr{
+			Err: Err{
+				Underlying_: newErr,
+				Cause_:      Cause(newErr),
+			},
+			code: code,
+		}
+	}
+	if kind := Classify(err); kind != "" {
+		newErr = &Kinded[Kind]{
+			Err: Err{
+				Underlying_: newErr,
+				Cause_:      Cause(newErr),
+			},
+			Kind_: kind,
+		}
+	}
+	return newErr
+}
+
+// sanitizeChain returns the sanitized copy of the chain
+// of err made by Sanitize, without its metadata, or nil
+// if no errors in the chain are kept.
+func sanitizeChain(err error, policy SanitizePolicy) error {
+	if err == nil {
+		return nil
+	}
+	rest := sanitizeChain(underlying(err), policy)
+	msg := message(err)
+	if policy.DropMessages && (policy.Public == nil || !policy.Public(err)) {
+		msg = ""
+	}
+	var loc Location
+	var frames []Location
+	if err, ok := err.(Locationer); ok && !policy.DropLocations {
+		loc = err.Location()
+		if err, ok := err.(Framer); ok {
+			frames = err.Frames()
+		}
+	}
+	var errs []error
+	for _, branch := range branches(err) {
+		if branch := sanitizeChain(branch, policy); branch != nil {
+			errs = append(errs, branch)
+		}
+	}
+	newErr := Err{
+		Message_:    msg,
+		Underlying_: rest,
+		Location_:   loc,
+	}
+	if frames != nil {
+		newErr.Extra_ = &Extra{Frames: frames}
+	}
+	switch {
+	case len(errs) > 0:
+		return &MultiErr{
+			Err:               newErr,
+			UnderlyingErrors_: errs,
+		}
+	case msg == "" && (!loc.IsSet() || rest == nil):
+		return rest
+	}
+	return &newErr
+}
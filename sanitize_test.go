@@ -0,0 +1,89 @@
+package errgo_test
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/juju/errgo"
+	"github.com/juju/errgo/errgotest"
+)
+
+func TestSanitize(t *testing.T) {
+	if err := errgo.Sanitize(nil, errgo.SanitizePolicy{}); err != nil {
+		t.Fatalf("expected nil error, got %#v", err)
+	}
+	public := errgo.New("user not found")
+	internal := errgo.Notef(os.ErrNotExist, "open /etc/secret")
+	err := errgo.WithKindf(errgo.Combine(public, internal), errgo.KindNotFound, "lookup")
+	err = errgo.WithCode(errgo.WithHTTPStatus(errgo.Mask(err), http.StatusNotFound), 4999)
+	outer := errgo.Notef(err, "cannot get user")
+
+	tests := []struct {
+		about         string
+		policy        errgo.SanitizePolicy
+		expectMessage string
+		expectDetails string
+	}{{
+		about:         "keep everything",
+		expectMessage: outer.Error(),
+	}, {
+		about: "drop locations",
+		policy: errgo.SanitizePolicy{
+			DropLocations: true,
+		},
+		expectMessage: outer.Error(),
+		expectDetails: "[{} {} {} {cannot get user} {lookup} {[{user not found}] [{open /etc/secret} {file does not exist}]}]",
+	}, {
+		about: "drop messages except public ones",
+		policy: errgo.SanitizePolicy{
+			DropMessages: true,
+			Public:       errgo.Is(public),
+		},
+		expectMessage: "user not found",
+		expectDetails: "[{} {} {} {sanitize_test.go: } {sanitize_test.go: } {sanitize_test.go: } {sanitize_test.go: } {sanitize_test.go: } {sanitize_test.go: [{sanitize_test.go: user not found}]}]",
+	}, {
+		about: "drop everything",
+		policy: errgo.SanitizePolicy{
+			DropLocations: true,
+			DropMessages:  true,
+			Message:       "something went wrong",
+		},
+		expectMessage: "something went wrong",
+		expectDetails: "[{} {} {} {something went wrong}]",
+	}, {
+		about: "default message",
+		policy: errgo.SanitizePolicy{
+			DropMessages: true,
+		},
+		expectMessage: "internal error",
+	}}
+	for i, test := range tests {
+		got := errgo.Sanitize(outer, test.policy)
+		if msg := got.Error(); msg != test.expectMessage {
+			t.Errorf("test %d (%s): got message %q want %q", i, test.about, msg, test.expectMessage)
+		}
+		if test.expectDetails != "" && sourceLocations {
+			if details := errgotest.NormalizeStack(errgo.Details(got), nil); details != test.expectDetails {
+				t.Errorf("test %d (%s): got details %q want %q", i, test.about, details, test.expectDetails)
+			}
+		}
+		if test.policy.DropLocations && strings.Contains(errgo.Details(got), "sanitize_test.go") {
+			t.Errorf("test %d (%s): locations not dropped: %s", i, test.about, errgo.Details(got))
+		}
+		if kind := errgo.Classify(got); kind != errgo.KindNotFound {
+			t.Errorf("test %d (%s): got kind %q", i, test.about, kind)
+		}
+		if code := errgo.CodeOf(got); code != 4999 {
+			t.Errorf("test %d (%s): got code %v", i, test.about, code)
+		}
+		if status := errgo.HTTPStatus(got); status != http.StatusNotFound {
+			t.Errorf("test %d (%s): got status %d", i, test.about, status)
+		}
+		if errors.Is(got, os.ErrNotExist) || errgo.Find(got, errgo.Is(internal)) != nil {
+			t.Errorf("test %d (%s): internal error reachable from %#v", i, test.about, got)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package errgo
+
+// Severity describes how serious an error is, for use
+// by logging and alerting systems.
+type Severity int
+
+// Severities in increasing order of seriousness. The zero
+// value means that no severity has been assigned.
+const (
+	SeverityDebug Severity = iota + 1
+	SeverityInfo
+	SeverityWarning
+	SeverityError
+	SeverityCritical
+)
+
+var severityNames = []string{
+	SeverityDebug:    "debug",
+	SeverityInfo:     "info",
+	SeverityWarning:  "warning",
+	SeverityError:    "error",
+	SeverityCritical: "critical",
+}
+
+// String returns the name of s, such as "warning".
+func (s Severity) String() string {
+	if s > 0 && int(s) < len(severityNames) {
+		return severityNames[s]
+	}
+	return "unknown"
+}
+
+// MarshalText implements encoding.TextMarshaler
+// by encoding the name of s.
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+// by decoding the name of a severity, such as "warning".
+func (s *Severity) UnmarshalText(text []byte) error {
+	for i, name := range severityNames {
+		if name != "" && name == string(text) {
+			*s = Severity(i)
+			return nil
+		}
+	}
+	return Newf("unknown severity %q", text)
+}
+
+// SyslogPriority returns the syslog priority level, from 0
+// (emergency) to 7 (debug), corresponding to s. Unknown
+// severities are treated as errors.
+func (s Severity) SyslogPriority() int {
+	switch s {
+	case SeverityDebug:
+		return 7
+	case SeverityInfo:
+		return 6
+	case SeverityWarning:
+		return 4
+	case SeverityCritical:
+		return 2
+	}
+	return 3
+}
+
+// severityErr holds an error associated with a severity.
+type severityErr struct {
+	Err
+	severity Severity
+}
+
+// WithSeverity returns an error that wraps err and associates it
+// with the given severity, as returned by SeverityOf. The returned
+// error has the same message and cause as err. If err is nil,
+// WithSeverity returns nil.
+func WithSeverity(err error, severity Severity) error {
+	if err == nil {
+		return nil
+	}
+	newErr := &severityErr{
+		Err: Err{
+			Underlying_: err,
+			Cause_:      Cause(err),
+		},
+		severity: severity,
+	}
+	newErr.SetLocation(1)
+	return Created(newErr)
+}
+
+// SeverityOf returns the severity associated with err by the
+// outermost call to WithSeverity in its chain, or else the severity
+// of the kind of err in the taxonomy (see SetTaxonomy), or
+// SeverityError if there is none. It returns zero if err is nil.
+func SeverityOf(err error) Severity {
+	if err == nil {
+		return 0
+	}
+	found := Find(err, func(err error) bool {
+		_, ok := err.(*severityErr)
+		return ok
+	})
+	if found != nil {
+		return found.(*severityErr).severity
+	}
+	if info, _ := kindInfo(err); info.Severity != 0 {
+		return info.Severity
+	}
+	return SeverityError
+}
@@ -0,0 +1,47 @@
+package errgo_test
+
+import (
+	"testing"
+
+	"github.com/juju/errgo"
+)
+
+func TestSeverity(t *testing.T) {
+	err0 := errgo.WithCausef(nil, errNotFound, "foo")
+	err := errgo.WithSeverity(err0, errgo.SeverityWarning)
+	checkErr(t, err, err0, "foo", "[{severity_test.go: } {severity_test.go: foo}]", errNotFound)
+
+	tests := []struct {
+		about  string
+		err    error
+		expect errgo.Severity
+	}{{
+		about: "nil error",
+	}, {
+		about:  "default severity",
+		err:    err0,
+		expect: errgo.SeverityError,
+	}, {
+		about:  "wrapped severity",
+		err:    errgo.Notef(err, "bar"),
+		expect: errgo.SeverityWarning,
+	}, {
+		about:  "outermost severity",
+		err:    errgo.WithSeverity(err, errgo.SeverityCritical),
+		expect: errgo.SeverityCritical,
+	}}
+	for _, test := range tests {
+		if got := errgo.SeverityOf(test.err); got != test.expect {
+			t.Errorf("%s: got %v want %v", test.about, got, test.expect)
+		}
+	}
+	if s := errgo.SeverityWarning.String(); s != "warning" {
+		t.Errorf("unexpected name %q", s)
+	}
+	if s := errgo.Severity(0).String(); s != "unknown" {
+		t.Errorf("unexpected name %q", s)
+	}
+	if errgo.WithSeverity(nil, errgo.SeverityInfo) != nil {
+		t.Errorf("expected nil error")
+	}
+}
@@ -0,0 +1,50 @@
+package errgo_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/juju/errgo"
+)
+
+// These errors are shared by tests in several files.
+var (
+	errNotFound = fmt.Errorf("not found")
+	someErr     = errgo.New("some error")
+)
+
+// failCloser is an io.Closer whose Close method always fails.
+type failCloser struct{}
+
+func (failCloser) Close() error {
+	return errgo.New("close failed")
+}
+
+// skipIfMinimal skips the rest of the test when the package is
+// built with the errgo_minimal tag, in which errors record no
+// locations, identifiers or other metadata beyond their
+// messages and causes.
+func skipIfMinimal(t *testing.T) {
+	t.Helper()
+	if errgo.Minimal {
+		t.Skip("errors record no metadata in minimal builds")
+	}
+}
+
+// sourceLocations reports whether errors record the source
+// locations of their callers, which they do not in minimal
+// builds or on runtimes with limited support for finding
+// callers (see RestrictedLocationLabel).
+var sourceLocations = func() bool {
+	loc, ok := errgo.InnermostLocation(errgo.New(""))
+	return ok && loc.File != errgo.RestrictedLocationLabel
+}()
+
+// skipIfNoSourceLocations skips the rest of the test when
+// errors do not record source locations.
+func skipIfNoSourceLocations(t *testing.T) {
+	t.Helper()
+	if !sourceLocations {
+		t.Skip("errors record no source locations")
+	}
+}
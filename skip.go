@@ -0,0 +1,98 @@
+package errgo
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// skippedPkgs holds the packages registered with SkipPackage.
+// It holds a map[string]bool that is replaced rather than
+// changed, so that locations can be recorded without locking.
+// Changes are made with skippedPkgsMu held.
+var (
+	skippedPkgs   atomic.Value
+	skippedPkgsMu sync.Mutex
+)
+
+// SkipPackage arranges for functions in the package with the given
+// import path to be skipped when recording error locations, in the
+// same way as functions marked with testing.T.Helper are skipped
+// when reporting test failures. This allows helper packages that
+// create errors on behalf of their callers to record the caller's
+// location rather than their own. For example:
+//
+//	func init() {
+//		errgo.SkipPackage("example.com/liberrs")
+//	}
+//
+// A location is never skipped beyond the outermost frame of
+// the goroutine.
+func SkipPackage(pkgPath string) {
+	skippedPkgsMu.Lock()
+	defer skippedPkgsMu.Unlock()
+	old, _ := skippedPkgs.Load().(map[string]bool)
+	pkgs := make(map[string]bool, len(old)+1)
+	for pkg := range old {
+		pkgs[pkg] = true
+	}
+	pkgs[pkgPath] = true
+	skippedPkgs.Store(pkgs)
+}
+
+// RestrictedLocationLabel holds the file name recorded in place of
+// source locations when the package is built for a runtime with
+// limited support for finding callers, such as TinyGo, or with the
+// errgo_nocallers build tag. In that mode no stack frames are
+// examined, and each location holds RestrictedLocationLabel
+// along with a number that increases with each location
+// recorded, for example "errgo:17", so that errors can
+// still be told apart.
+//
+// It should be set before any errors are created, usually
+// during program initialization.
+var RestrictedLocationLabel = "errgo"
+
+// funcPackage returns the import path of the package holding
+// the function with the given fully qualified name,
+// for example "example.com/liberrs.(*T).Method".
+func funcPackage(name string) string {
+	pkg, _ := splitFuncName(name)
+	return pkg
+}
+
+// splitFuncName splits the given fully qualified function
+// name into the import path of its package and the name of
+// the function within it. The runtime escapes dots and some
+// other characters in the last element of the import path, as
+// in "gopkg.in/yaml%2ev3.Marshal", so the path is unescaped.
+func splitFuncName(name string) (pkg, function string) {
+	slash := strings.LastIndex(name, "/")
+	dot := strings.Index(name[slash+1:], ".")
+	if dot < 0 {
+		return unescapePath(name), ""
+	}
+	return unescapePath(name[:slash+1+dot]), name[slash+1+dot+1:]
+}
+
+// unescapePath returns the given import path with each
+// escape sequence of the form %xx replaced by the byte
+// it encodes.
+func unescapePath(path string) string {
+	if !strings.Contains(path, "%") {
+		return path
+	}
+	var b strings.Builder
+	for i := 0; i < len(path); i++ {
+		if path[i] == '%' && i+2 < len(path) {
+			if c, err := strconv.ParseUint(path[i+1:i+3], 16, 8); err == nil {
+				b.WriteByte(byte(c))
+				i += 2
+				continue
+			}
+		}
+		b.WriteByte(path[i])
+	}
+	return b.String()
+}
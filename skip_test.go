@@ -0,0 +1,70 @@
+package errgo_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/juju/errgo"
+	"github.com/juju/errgo/internal/errhelper.v1"
+)
+
+func TestFuncPackage(t *testing.T) {
+	tests := []struct {
+		name   string
+		expect string
+	}{{
+		name:   "main.main",
+		expect: "main",
+	}, {
+		name:   "example.com/liberrs.Wrap",
+		expect: "example.com/liberrs",
+	}, {
+		name:   "example.com/liberrs.(*T).Method.func1",
+		expect: "example.com/liberrs",
+	}, {
+		name:   "example.com/lib.v2/errs.Wrap",
+		expect: "example.com/lib.v2/errs",
+	}, {
+		name:   "example.com/liberrs%2ev1.Wrap",
+		expect: "example.com/liberrs.v1",
+	}, {
+		name:   "gopkg.in/yaml%2ev3.(*T).Method",
+		expect: "gopkg.in/yaml.v3",
+	}}
+	for i, test := range tests {
+		if got := errgo.FuncPackage(test.name); got != test.expect {
+			t.Errorf("test %d: got %q want %q", i, got, test.expect)
+		}
+	}
+}
+
+func newHelperErr() error {
+	return errgo.New("helper")
+}
+
+func TestSkipPackage(t *testing.T) {
+	skipIfNoSourceLocations(t)
+	defer errgo.ResetSkipPackages()
+	fn := newHelperErr().(errgo.Functioner).Function()
+	if want := "github.com/juju/errgo_test.newHelperErr"; fn != want {
+		t.Fatalf("unexpected function; got %q want %q", fn, want)
+	}
+
+	// Skipping this package attributes the error to the
+	// caller of the test function, in the testing package.
+	errgo.SkipPackage("github.com/juju/errgo_test")
+	loc := newHelperErr().(errgo.Locationer).Location()
+	if filepath.Base(loc.File) != "testing.go" {
+		t.Fatalf("unexpected location %v", loc)
+	}
+}
+
+func TestSkipDottedPackage(t *testing.T) {
+	skipIfNoSourceLocations(t)
+	defer errgo.ResetSkipPackages()
+	errgo.SkipPackage("github.com/juju/errgo/internal/errhelper.v1")
+	fn := errhelper.New("helper").(errgo.Functioner).Function()
+	if want := "github.com/juju/errgo_test.TestSkipDottedPackage"; fn != want {
+		t.Fatalf("unexpected function; got %q want %q", fn, want)
+	}
+}
@@ -0,0 +1,136 @@
+// The sqlerr package provides functions for classifying
+// errors returned by database/sql and common database
+// drivers, and for annotating them with the query that
+// caused them.
+//
+// The classification functions look through the whole
+// error chain (see errgo.Find), so they work even when the
+// cause of an error has been masked. They may also be used
+// as "pass" arguments to errgo.Mask and friends.
+//
+// Importing this package also registers classifications
+// (see errgo.Classify) of sql.ErrNoRows as errgo.KindNotFound
+// and of unique constraint violations as errgo.KindExists.
+package sqlerr
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/juju/errgo"
+)
+
+// SQLSTATE codes as defined by the SQL standard.
+const (
+	codeUniqueViolation      = "23505"
+	codeSerializationFailure = "40001"
+)
+
+// MySQL server error numbers.
+const (
+	mysqlDuplicateEntry = 1062
+	mysqlDeadlock       = 1213
+)
+
+func init() {
+	errgo.RegisterKind(errgo.KindNotFound, IsNoRows)
+	errgo.RegisterKind(errgo.KindExists, IsUniqueViolation)
+}
+
+// IsNoRows reports whether err or any error in its chain
+// is sql.ErrNoRows.
+func IsNoRows(err error) bool {
+	return errgo.Find(err, errgo.Is(sql.ErrNoRows)) != nil
+}
+
+// IsUniqueViolation reports whether err or any error in its
+// chain reports a violation of a unique constraint.
+func IsUniqueViolation(err error) bool {
+	return errgo.Find(err, func(err error) bool {
+		return sqlState(err) == codeUniqueViolation ||
+			mysqlNumber(err) == mysqlDuplicateEntry ||
+			strings.HasPrefix(err.Error(), "UNIQUE constraint failed")
+	}) != nil
+}
+
+// IsSerializationFailure reports whether err or any error in its
+// chain reports that a transaction could not be serialized
+// with others, in which case it may succeed if retried.
+func IsSerializationFailure(err error) bool {
+	return errgo.Find(err, func(err error) bool {
+		return sqlState(err) == codeSerializationFailure ||
+			mysqlNumber(err) == mysqlDeadlock
+	}) != nil
+}
+
+// sqlState returns the SQLSTATE code of the given error, as
+// provided by the PostgreSQL drivers, or the empty string
+// if there is none.
+func sqlState(err error) string {
+	if err, ok := err.(interface {
+		SQLState() string
+	}); ok {
+		return err.SQLState()
+	}
+	return ""
+}
+
+// mysqlNumber returns the MySQL server error number of the given
+// error, or zero if there is none. MySQL driver errors are formatted
+// as "Error 1062: ..." or "Error 1062 (23000): ...".
+func mysqlNumber(err error) int {
+	var n int
+	if _, serr := fmt.Sscanf(err.Error(), "Error %d", &n); serr != nil {
+		return 0
+	}
+	return n
+}
+
+// QueryError holds an error that occurred when running
+// a database query.
+type QueryError struct {
+	errgo.Err
+
+	// Query_ holds the name of the query.
+	Query_ string
+}
+
+// Query returns the name of the query.
+func (e *QueryError) Query() string {
+	return e.Query_
+}
+
+// Mask is like errgo.Mask, but also records the
+// name of the query that caused the error, which
+// is added to the error message and can be retrieved
+// with Query.
+func Mask(err error, query string, pass ...func(error) bool) error {
+	if err == nil {
+		return nil
+	}
+	newErr := &QueryError{
+		Err: errgo.Err{
+			Message_:    "query " + query,
+			Underlying_: err,
+			Cause_:      errgo.PassedCause(err, pass...),
+		},
+		Query_: query,
+	}
+	newErr.SetLocation(1)
+	return errgo.Created(newErr)
+}
+
+// Query returns the name of the query recorded by the
+// outermost QueryError in the chain of err, or
+// the empty string if there is none.
+func Query(err error) string {
+	found := errgo.Find(err, func(err error) bool {
+		_, ok := err.(*QueryError)
+		return ok
+	})
+	if found == nil {
+		return ""
+	}
+	return found.(*QueryError).Query_
+}
@@ -0,0 +1,127 @@
+package sqlerr_test
+
+import (
+	"database/sql"
+	"fmt"
+	"testing"
+
+	"github.com/juju/errgo"
+	"github.com/juju/errgo/sqlerr"
+)
+
+// sourceLocations reports whether errors record the source
+// locations of their callers, which they do not in minimal
+// builds or on runtimes with limited support for finding
+// callers (see errgo.RestrictedLocationLabel).
+var sourceLocations = func() bool {
+	loc, ok := errgo.InnermostLocation(errgo.New(""))
+	return ok && loc.File != errgo.RestrictedLocationLabel
+}()
+
+// pgError mimics the errors returned by PostgreSQL drivers.
+type pgError struct {
+	code string
+}
+
+func (e *pgError) Error() string {
+	return "pq: error " + e.code
+}
+
+func (e *pgError) SQLState() string {
+	return e.code
+}
+
+var classifyTests = []struct {
+	about                string
+	err                  error
+	noRows               bool
+	uniqueViolation      bool
+	serializationFailure bool
+}{{
+	about:  "no rows",
+	err:    sql.ErrNoRows,
+	noRows: true,
+}, {
+	about:  "masked no rows",
+	err:    errgo.Notef(errgo.Mask(sql.ErrNoRows), "cannot get user"),
+	noRows: true,
+}, {
+	about:           "postgres unique violation",
+	err:             errgo.Mask(&pgError{"23505"}),
+	uniqueViolation: true,
+}, {
+	about:           "mysql duplicate entry",
+	err:             errgo.Mask(fmt.Errorf("Error 1062 (23000): Duplicate entry 'a' for key 'name'")),
+	uniqueViolation: true,
+}, {
+	about:           "sqlite unique violation",
+	err:             fmt.Errorf("UNIQUE constraint failed: users.name"),
+	uniqueViolation: true,
+}, {
+	about:                "postgres serialization failure",
+	err:                  errgo.Mask(&pgError{"40001"}),
+	serializationFailure: true,
+}, {
+	about:                "mysql deadlock",
+	err:                  fmt.Errorf("Error 1213: Deadlock found when trying to get lock"),
+	serializationFailure: true,
+}, {
+	about: "other error",
+	err:   errgo.New("Error: something"),
+}, {
+	about: "nil error",
+}}
+
+func TestClassify(t *testing.T) {
+	for i, test := range classifyTests {
+		if got := sqlerr.IsNoRows(test.err); got != test.noRows {
+			t.Errorf("test %d (%s): IsNoRows returned %v", i, test.about, got)
+		}
+		if got := sqlerr.IsUniqueViolation(test.err); got != test.uniqueViolation {
+			t.Errorf("test %d (%s): IsUniqueViolation returned %v", i, test.about, got)
+		}
+		if got := sqlerr.IsSerializationFailure(test.err); got != test.serializationFailure {
+			t.Errorf("test %d (%s): IsSerializationFailure returned %v", i, test.about, got)
+		}
+		var kind errgo.Kind
+		switch {
+		case test.noRows:
+			kind = errgo.KindNotFound
+		case test.uniqueViolation:
+			kind = errgo.KindExists
+		}
+		if got := errgo.Classify(test.err); got != kind {
+			t.Errorf("test %d (%s): Classify returned %q want %q", i, test.about, got, kind)
+		}
+	}
+}
+
+func TestMask(t *testing.T) {
+	if err := sqlerr.Mask(nil, "getUser"); err != nil {
+		t.Fatalf("unexpected error %#v", err)
+	}
+	err := sqlerr.Mask(sql.ErrNoRows, "getUser", sqlerr.IsNoRows)
+	if err.Error() != "query getUser: "+sql.ErrNoRows.Error() {
+		t.Fatalf("unexpected message %q", err.Error())
+	}
+	if cause := errgo.Cause(err); cause != sql.ErrNoRows {
+		t.Fatalf("unexpected cause %#v", cause)
+	}
+	if !hasLocation(err) && sourceLocations {
+		t.Fatalf("no location recorded")
+	}
+	err = errgo.Notef(sqlerr.Mask(&pgError{"23505"}, "insertUser"), "cannot add user")
+	if cause := errgo.Cause(err); cause != err {
+		t.Fatalf("cause was not masked: %#v", cause)
+	}
+	if q := sqlerr.Query(err); q != "insertUser" {
+		t.Fatalf("unexpected query %q", q)
+	}
+	if q := sqlerr.Query(sql.ErrNoRows); q != "" {
+		t.Fatalf("unexpected query %q", q)
+	}
+}
+
+func hasLocation(err error) bool {
+	return err.(errgo.Locationer).Location().IsSet()
+}
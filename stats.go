@@ -0,0 +1,84 @@
+package errgo
+
+import "sync"
+
+// Stats counts errors by their kind, by the package
+// where they originated and by their fingerprint.
+//
+// The zero value is ready to use. The counts may be
+// published as an expvar variable with errstats.Publish.
+type Stats struct {
+	mu            sync.Mutex
+	total         int64
+	byKind        map[string]int64
+	byPackage     map[string]int64
+	byFingerprint map[string]int64
+}
+
+// StatsSnapshot holds the counts recorded by a Stats value
+// at some moment.
+type StatsSnapshot struct {
+	// Total holds the total number of errors recorded.
+	Total int64
+
+	// ByKind holds the number of errors of each
+	// kind, as returned by Classify. Errors that
+	// are not classified are not counted.
+	ByKind map[string]int64
+
+	// ByPackage holds the number of errors originating
+	// in each package, as returned by Origin. Errors
+	// that do not record their function are not counted.
+	ByPackage map[string]int64
+
+	// ByFingerprint holds the number of errors with each
+	// fingerprint (see Fingerprint).
+	ByFingerprint map[string]int64
+}
+
+// Record records an occurrence of err. It does nothing
+// if err is nil.
+func (s *Stats) Record(err error) {
+	if err == nil {
+		return
+	}
+	kind := Classify(err)
+	pkg, _ := Origin(err)
+	fingerprint := Fingerprint(err)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.byKind == nil {
+		s.byKind = make(map[string]int64)
+		s.byPackage = make(map[string]int64)
+		s.byFingerprint = make(map[string]int64)
+	}
+	s.total++
+	if kind != "" {
+		s.byKind[string(kind)]++
+	}
+	if pkg != "" {
+		s.byPackage[pkg]++
+	}
+	s.byFingerprint[fingerprint]++
+}
+
+// Snapshot returns the current counts.
+func (s *Stats) Snapshot() StatsSnapshot {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return StatsSnapshot{
+		Total:         s.total,
+		ByKind:        copyCounts(s.byKind),
+		ByPackage:     copyCounts(s.byPackage),
+		ByFingerprint: copyCounts(s.byFingerprint),
+	}
+}
+
+func copyCounts(m map[string]int64) map[string]int64 {
+	m1 := make(map[string]int64, len(m))
+	for key, n := range m {
+		m1[key] = n
+	}
+	return m1
+}
@@ -0,0 +1,49 @@
+package errgo_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/juju/errgo"
+)
+
+func newFingerprintErr() error {
+	return errgo.Notef(errgo.New("foo"), "bar")
+}
+
+func TestFingerprint(t *testing.T) {
+	if f := errgo.Fingerprint(nil); f != "" {
+		t.Fatalf("unexpected fingerprint %q", f)
+	}
+	f0, f1 := errgo.Fingerprint(newFingerprintErr()), errgo.Fingerprint(newFingerprintErr())
+	if len(f0) != 16 || f0 != f1 {
+		t.Fatalf("fingerprints differ: %q, %q", f0, f1)
+	}
+	if f := errgo.Fingerprint(errgo.Mask(newFingerprintErr())); f == f0 {
+		t.Fatalf("fingerprint did not change when location was added")
+	}
+	if errgo.Fingerprint(errNotFound) == errgo.Fingerprint(someErr) {
+		t.Fatalf("fingerprints of different errors are equal")
+	}
+}
+
+func TestStats(t *testing.T) {
+	var s errgo.Stats
+	s.Record(nil)
+	s.Record(newFingerprintErr())
+	s.Record(newFingerprintErr())
+	s.Record(errgo.Mask(os.ErrNotExist, errgo.Any))
+	snap := s.Snapshot()
+	if snap.Total != 3 {
+		t.Fatalf("unexpected total %d", snap.Total)
+	}
+	if snap.ByKind[string(errgo.KindNotFound)] != 1 || len(snap.ByKind) != 1 {
+		t.Fatalf("unexpected kind counts %v", snap.ByKind)
+	}
+	if n := snap.ByPackage["github.com/juju/errgo_test"]; n != 3 && sourceLocations {
+		t.Fatalf("unexpected package counts %v", snap.ByPackage)
+	}
+	if n := snap.ByFingerprint[errgo.Fingerprint(newFingerprintErr())]; n != 2 || len(snap.ByFingerprint) != 2 {
+		t.Fatalf("unexpected fingerprint counts %v", snap.ByFingerprint)
+	}
+}
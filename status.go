@@ -0,0 +1,51 @@
+package errgo
+
+// statusInternalServerError holds the value of
+// http.StatusInternalServerError. It is declared here so
+// that HTTP statuses are available in minimal builds,
+// which do not include net/http (see minimal.go).
+const statusInternalServerError = 500
+
+// statusErr holds an error associated with an HTTP status code.
+type statusErr struct {
+	Err
+	status int
+}
+
+// WithHTTPStatus returns an error that wraps err and associates it
+// with the given HTTP status code (see HTTPStatus).
+// The returned error has the same message and cause as err.
+// If err is nil, WithHTTPStatus returns nil.
+func WithHTTPStatus(err error, status int) error {
+	if err == nil {
+		return nil
+	}
+	newErr := &statusErr{
+		Err: Err{
+			Underlying_: err,
+			Cause_:      Cause(err),
+		},
+		status: status,
+	}
+	newErr.SetLocation(1)
+	return Created(newErr)
+}
+
+// HTTPStatus returns the HTTP status code associated with
+// err by the outermost call to WithHTTPStatus in its chain.
+// If there is none, it returns the status of the kind of err
+// in the taxonomy (see SetTaxonomy), or
+// http.StatusInternalServerError if there is none.
+func HTTPStatus(err error) int {
+	found := Find(err, func(err error) bool {
+		_, ok := err.(*statusErr)
+		return ok
+	})
+	if found != nil {
+		return found.(*statusErr).status
+	}
+	if info, _ := kindInfo(err); info.HTTPStatus != 0 {
+		return info.HTTPStatus
+	}
+	return statusInternalServerError
+}
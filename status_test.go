@@ -0,0 +1,20 @@
+package errgo_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/juju/errgo"
+)
+
+func TestHTTPStatus(t *testing.T) {
+	if status := errgo.HTTPStatus(someErr); status != http.StatusInternalServerError {
+		t.Fatalf("unexpected status %d", status)
+	}
+	if errgo.HTTPStatus(errgo.Mask(errgo.WithHTTPStatus(someErr, 404))) != 404 {
+		t.Fatalf("status not found through chain")
+	}
+	if err := errgo.WithHTTPStatus(nil, 404); err != nil {
+		t.Fatalf("unexpected error %#v", err)
+	}
+}
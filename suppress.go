@@ -0,0 +1,112 @@
+package errgo
+
+import (
+	"sync"
+	"time"
+)
+
+// maxSuppressedWindows holds the number of windows after which
+// a Suppressor forgets an error, even if identical errors
+// have been suppressed since it was last reported.
+const maxSuppressedWindows = 10
+
+// Suppressor decides whether errors should be reported, so that
+// a flood of identical errors, as produced during an outage, is
+// reported once in each period rather than every time.
+// Errors are considered identical when they have the
+// same fingerprint (see Fingerprint).
+//
+// A Suppressor is safe for concurrent use.
+type Suppressor struct {
+	window time.Duration
+
+	mu        sync.Mutex
+	seen      map[string]*suppression
+	lastSweep time.Time
+}
+
+// suppression holds what a Suppressor has
+// seen of errors with some fingerprint.
+type suppression struct {
+	// reported holds when an error was last reported.
+	reported time.Time
+
+	// count holds the number of errors suppressed
+	// since then.
+	count int
+}
+
+// NewSuppressor returns a Suppressor that allows an error to be
+// reported at most once in each period of the given length.
+func NewSuppressor(window time.Duration) *Suppressor {
+	return &Suppressor{
+		window: window,
+		seen:   make(map[string]*suppression),
+	}
+}
+
+// Check records an occurrence of err and reports whether it should
+// be reported, which is so unless an identical error was reported
+// within the suppressor's window. When it should be reported, Check
+// also returns the number of identical errors that were suppressed
+// since the last one was reported, so that it can be included
+// with the report, for example:
+//
+//	if ok, n := s.Check(err); ok {
+//		if n > 0 {
+//			log.Printf("%v (and %d more)", err, n)
+//		} else {
+//			log.Print(err)
+//		}
+//	}
+//
+// The count is forgotten if no identical error is checked for ten
+// windows after one was last reported, so that the memory used by
+// the suppressor stays bounded however many different errors it
+// sees; the next identical error is then reported as if it had
+// not been seen before.
+//
+// Check returns true and zero if err is nil.
+func (s *Suppressor) Check(err error) (report bool, suppressed int) {
+	if err == nil {
+		return true, 0
+	}
+	fingerprint := Fingerprint(err)
+	t := now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sweep(t)
+	e := s.seen[fingerprint]
+	if e == nil {
+		s.seen[fingerprint] = &suppression{reported: t}
+		return true, 0
+	}
+	if t.Sub(e.reported) < s.window {
+		e.count++
+		return false, 0
+	}
+	suppressed = e.count
+	e.reported, e.count = t, 0
+	return true, suppressed
+}
+
+// sweep forgets errors that were last reported more than two
+// windows before t and have not been suppressed since, and
+// errors last reported more than maxSuppressedWindows windows
+// before t, at most once in each window, so that the memory used
+// by the suppressor stays bounded. Errors with suppressed
+// occurrences are otherwise kept so that their count is returned
+// when they are next reported. It must be called with s.mu held.
+func (s *Suppressor) sweep(t time.Time) {
+	if t.Sub(s.lastSweep) < s.window {
+		return
+	}
+	s.lastSweep = t
+	for fingerprint, e := range s.seen {
+		age := t.Sub(e.reported)
+		if e.count == 0 && age >= 2*s.window || age >= maxSuppressedWindows*s.window {
+			delete(s.seen, fingerprint)
+		}
+	}
+}
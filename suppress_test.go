@@ -0,0 +1,110 @@
+package errgo_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/juju/errgo"
+)
+
+func suppressedErr() error {
+	return errgo.New("connection refused")
+}
+
+func TestSuppressor(t *testing.T) {
+	clock := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	defer errgo.PatchNow(func() time.Time {
+		return clock
+	})()
+	s := errgo.NewSuppressor(time.Minute)
+	other := errgo.New("other")
+	tests := []struct {
+		about      string
+		advance    time.Duration
+		err        error
+		report     bool
+		suppressed int
+	}{{
+		about:  "first occurrence",
+		err:    suppressedErr(),
+		report: true,
+	}, {
+		about:   "repeat within window",
+		advance: 10 * time.Second,
+		err:     suppressedErr(),
+	}, {
+		about:   "another repeat within window",
+		advance: 10 * time.Second,
+		err:     suppressedErr(),
+	}, {
+		about:  "different error",
+		err:    other,
+		report: true,
+	}, {
+		about:      "repeat after window",
+		advance:    time.Minute,
+		err:        suppressedErr(),
+		report:     true,
+		suppressed: 2,
+	}, {
+		about:   "repeat within new window",
+		advance: time.Second,
+		err:     suppressedErr(),
+	}, {
+		about:   "different error after two windows",
+		advance: 2 * time.Minute,
+		err:     other,
+		report:  true,
+	}, {
+		about:      "repeat after long gap keeps suppressed count",
+		err:        suppressedErr(),
+		report:     true,
+		suppressed: 1,
+	}, {
+		about:   "repeat after long gap without suppression",
+		advance: 3 * time.Minute,
+		err:     suppressedErr(),
+		report:  true,
+	}, {
+		about:   "repeat within window",
+		advance: time.Second,
+		err:     suppressedErr(),
+	}, {
+		about:   "repeat after ten windows forgets suppressed count",
+		advance: 10 * time.Minute,
+		err:     suppressedErr(),
+		report:  true,
+	}, {
+		about:  "nil error",
+		report: true,
+	}}
+	for i, test := range tests {
+		clock = clock.Add(test.advance)
+		report, suppressed := s.Check(test.err)
+		if report != test.report || suppressed != test.suppressed {
+			t.Errorf("test %d (%s): got %v, %d want %v, %d", i, test.about, report, suppressed, test.report, test.suppressed)
+		}
+	}
+}
+
+func TestSuppressorForgetsSuppressedErrors(t *testing.T) {
+	clock := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	defer errgo.PatchNow(func() time.Time {
+		return clock
+	})()
+	s := errgo.NewSuppressor(time.Minute)
+	for i := 0; i < 100; i++ {
+		err := fmt.Errorf("error %d", i)
+		s.Check(err)
+		s.Check(err)
+	}
+	if n := errgo.SuppressorLen(s); n != 100 {
+		t.Fatalf("unexpected number of errors %d", n)
+	}
+	clock = clock.Add(10 * time.Minute)
+	s.Check(suppressedErr())
+	if n := errgo.SuppressorLen(s); n != 1 {
+		t.Fatalf("suppressed errors not forgotten; %d remain", n)
+	}
+}
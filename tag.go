@@ -0,0 +1,67 @@
+package errgo
+
+// tagErr holds an error along with a set of tags.
+type tagErr struct {
+	Err
+	tags []string
+}
+
+// Tag returns an error that wraps err and tags it with the given
+// strings, such as "billing" or "user-visible", so that code
+// handling the error, for example to route alerts, can check for
+// them with HasTag regardless of how the error is subsequently
+// wrapped. The returned error has the same message and cause as
+// err. If err is nil, Tag returns nil.
+func Tag(err error, tags ...string) error {
+	if err == nil {
+		return nil
+	}
+	newErr := &tagErr{
+		Err: Err{
+			Underlying_: err,
+			Cause_:      Cause(err),
+		},
+		tags: append([]string(nil), tags...),
+	}
+	newErr.SetLocation(1)
+	return Created(newErr)
+}
+
+// HasTag reports whether any error in the chain of err (see Find)
+// has been tagged with the given tag by Tag.
+func HasTag(err error, tag string) bool {
+	return Find(err, func(err error) bool {
+		if err, ok := err.(*tagErr); ok {
+			for _, t := range err.tags {
+				if t == tag {
+					return true
+				}
+			}
+		}
+		return false
+	}) != nil
+}
+
+// Tags returns the tags added by Tag to the errors in the
+// chain of err (see Find), outermost first, without duplicates.
+func Tags(err error) []string {
+	return tags(err)
+}
+
+// tags returns the tags of all the errors in the chain of err
+// (see Find), outermost first, without duplicates.
+func tags(err error) []string {
+	var found []string
+	seen := make(map[string]bool)
+	for _, e := range links(err) {
+		if e, ok := e.(*tagErr); ok {
+			for _, t := range e.tags {
+				if !seen[t] {
+					seen[t] = true
+					found = append(found, t)
+				}
+			}
+		}
+	}
+	return found
+}
@@ -0,0 +1,59 @@
+package errgo_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/juju/errgo"
+)
+
+func TestTag(t *testing.T) {
+	err0 := errgo.WithCausef(nil, errNotFound, "foo")
+	err := errgo.Tag(err0, "billing", "user-visible")
+	checkErr(t, err, err0, "foo", "[{tag_test.go: } {tag_test.go: foo}]", errNotFound)
+
+	tests := []struct {
+		about  string
+		err    error
+		tag    string
+		expect bool
+	}{{
+		about: "nil error",
+		tag:   "billing",
+	}, {
+		about: "untagged error",
+		err:   err0,
+		tag:   "billing",
+	}, {
+		about:  "tagged error",
+		err:    err,
+		tag:    "user-visible",
+		expect: true,
+	}, {
+		about:  "wrapped tagged error",
+		err:    errgo.Tag(errgo.Notef(err, "bar"), "paging"),
+		tag:    "billing",
+		expect: true,
+	}, {
+		about:  "tagged branch",
+		err:    errgo.Combine(someErr, err),
+		tag:    "billing",
+		expect: true,
+	}, {
+		about: "other tag",
+		err:   err,
+		tag:   "paging",
+	}}
+	for _, test := range tests {
+		if got := errgo.HasTag(test.err, test.tag); got != test.expect {
+			t.Errorf("%s: got %v want %v", test.about, got, test.expect)
+		}
+	}
+	got := errgo.Tags(errgo.Tag(errgo.Notef(err, "bar"), "paging", "billing"))
+	if want := []string{"paging", "billing", "user-visible"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected tags %q", got)
+	}
+	if errgo.Tag(nil, "billing") != nil {
+		t.Errorf("expected nil error")
+	}
+}
@@ -0,0 +1,108 @@
+package errgo
+
+// KindInfo holds the properties associated with
+// a kind of error by a Taxonomy. Zero values mean
+// that the kind does not determine the property.
+type KindInfo struct {
+	// Code holds the numeric code of the kind (see CodeOf).
+	Code Code `json:"code,omitempty"`
+
+	// HTTPStatus holds the HTTP status of
+	// the kind (see HTTPStatus).
+	HTTPStatus int `json:"http_status,omitempty"`
+
+	// ExitCode holds the exit code of the kind (see ExitCode).
+	ExitCode int `json:"exit_code,omitempty"`
+
+	// Severity holds the severity of the kind (see SeverityOf).
+	// In JSON, it is held as the name of the severity,
+	// such as "warning".
+	Severity Severity `json:"severity,omitempty"`
+
+	// Retryable holds whether operations failing with the
+	// kind may be retried (see IsRetryable and Retry).
+	Retryable *bool `json:"retryable,omitempty"`
+
+	// DocURL holds the documentation URL
+	// of the kind (see DocURL).
+	DocURL string `json:"doc_url,omitempty"`
+}
+
+// Taxonomy holds the properties of
+// each kind of error (see Classify).
+type Taxonomy map[Kind]KindInfo
+
+// taxonomy holds the taxonomy set by SetTaxonomy.
+var taxonomy Taxonomy
+
+// SetTaxonomy sets the taxonomy used to find the properties of
+// errors that have not been given them explicitly. The properties
+// of the kind of an error (see Classify) are used by CodeOf,
+// HTTPStatus, ExitCode, SeverityOf, IsRetryable, Retry and DocURL,
+// and so by the renderers that use them, when no such property
+// has been associated with the error by WithCode, WithHTTPStatus
+// and so on. Classify in turn returns the kind whose code is the
+// code associated with an error by WithCode, when no other kind
+// is found. For example:
+//
+//	errgo.SetTaxonomy(errgo.Taxonomy{
+//		errgo.KindNotFound: {Code: 4004, HTTPStatus: http.StatusNotFound},
+//	})
+//
+// The taxonomy is copied. SetTaxonomy should be called before any
+// errors are created, usually during program initialization. It
+// panics if two kinds have the same non-zero code.
+func SetTaxonomy(t Taxonomy) {
+	if err := t.Check(); err != nil {
+		panic("errgo: SetTaxonomy called with " + err.Error())
+	}
+	newTaxonomy := make(Taxonomy, len(t))
+	for kind, info := range t {
+		newTaxonomy[kind] = info
+	}
+	taxonomy = newTaxonomy
+}
+
+// Check returns an error if two kinds in t have the same non-zero
+// code, as SetTaxonomy requires, so that a taxonomy read from
+// configuration can be checked before it is set.
+func (t Taxonomy) Check() error {
+	codeKinds := make(map[Code]Kind)
+	for kind, info := range t {
+		if info.Code == 0 {
+			continue
+		}
+		if other, ok := codeKinds[info.Code]; ok {
+			if other > kind {
+				other, kind = kind, other
+			}
+			return Newf("code %d for kinds %s and %s", info.Code, other, kind)
+		}
+		codeKinds[info.Code] = kind
+	}
+	return nil
+}
+
+// kindInfo returns the properties associated with the kind
+// of err by the taxonomy, and whether there are any.
+func kindInfo(err error) (KindInfo, bool) {
+	if len(taxonomy) == 0 || err == nil {
+		return KindInfo{}, false
+	}
+	info, ok := taxonomy[Classify(err)]
+	return info, ok
+}
+
+// codeKind returns the kind with the given
+// code in the taxonomy, or "" if there is none.
+func codeKind(code Code) Kind {
+	if code == 0 {
+		return ""
+	}
+	for kind, info := range taxonomy {
+		if info.Code == code {
+			return kind
+		}
+	}
+	return ""
+}
@@ -0,0 +1,34 @@
+// The taxonomy package loads the taxonomy consulted by errgo
+// (see errgo.SetTaxonomy) from its JSON encoding.
+package taxonomy
+
+import (
+	"encoding/json"
+
+	"github.com/juju/errgo"
+)
+
+// Load sets the taxonomy as for errgo.SetTaxonomy from its JSON
+// encoding, an object holding the properties of each kind, for
+// example:
+//
+//	{
+//		"not-found": {"code": 4004, "http_status": 404, "severity": "info"},
+//		"timeout": {"http_status": 504, "retryable": true}
+//	}
+//
+// It is convenient to use with a file embedded in the program.
+// Unlike errgo.SetTaxonomy, Load returns an error rather than
+// panicking if two kinds have the same code, and the taxonomy
+// is left unchanged.
+func Load(data []byte) error {
+	var t errgo.Taxonomy
+	if err := json.Unmarshal(data, &t); err != nil {
+		return errgo.Notef(err, "cannot load taxonomy")
+	}
+	if err := t.Check(); err != nil {
+		return errgo.Notef(err, "cannot load taxonomy")
+	}
+	errgo.SetTaxonomy(t)
+	return nil
+}
@@ -0,0 +1,66 @@
+package taxonomy_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/juju/errgo"
+	"github.com/juju/errgo/taxonomy"
+)
+
+const kindQuota errgo.Kind = "taxonomy-quota"
+
+func TestLoad(t *testing.T) {
+	defer errgo.SetTaxonomy(nil)
+	err := taxonomy.Load([]byte(`{
+		"taxonomy-quota": {
+			"code": 4998,
+			"http_status": 429,
+			"exit_code": 75,
+			"severity": "warning",
+			"retryable": true,
+			"doc_url": "https://example.com/quota"
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("cannot load taxonomy: %v", err)
+	}
+	err = errgo.WithKindf(nil, kindQuota, "too many requests")
+	if got := errgo.CodeOf(err); got != 4998 {
+		t.Errorf("unexpected code %v", got)
+	}
+	if got := errgo.HTTPStatus(err); got != http.StatusTooManyRequests {
+		t.Errorf("unexpected HTTP status %d", got)
+	}
+	if got := errgo.ExitCode(err); got != 75 {
+		t.Errorf("unexpected exit code %d", got)
+	}
+	if got := errgo.SeverityOf(err); got != errgo.SeverityWarning {
+		t.Errorf("unexpected severity %v", got)
+	}
+	if !errgo.IsRetryable(err) {
+		t.Errorf("not retryable")
+	}
+	if got := errgo.DocURL(err); got != "https://example.com/quota" {
+		t.Errorf("unexpected doc URL %q", got)
+	}
+}
+
+func TestLoadError(t *testing.T) {
+	defer errgo.SetTaxonomy(nil)
+	err := taxonomy.Load([]byte(`{"taxonomy-quota": {"severity": "dire"}}`))
+	if err == nil || err.Error() != `cannot load taxonomy: unknown severity "dire"` {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
+
+func TestLoadDuplicateCode(t *testing.T) {
+	defer errgo.SetTaxonomy(nil)
+	err := taxonomy.Load([]byte(`{"not-found": {"code": 4998}, "taxonomy-quota": {"code": 4998}}`))
+	if err == nil || err.Error() != "cannot load taxonomy: code 4998 for kinds not-found and taxonomy-quota" {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if got := errgo.CodeOf(errgo.WithKindf(nil, errgo.KindNotFound, "foo")); got != 0 {
+		t.Fatalf("taxonomy changed; got code %d", got)
+	}
+}
@@ -0,0 +1,105 @@
+package errgo_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/juju/errgo"
+)
+
+const kindTaxonomyQuota errgo.Kind = "taxonomy-quota"
+
+func TestTaxonomy(t *testing.T) {
+	defer errgo.SetTaxonomy(nil)
+	retryable := true
+	errgo.SetTaxonomy(errgo.Taxonomy{
+		kindTaxonomyQuota: {
+			Code:       4998,
+			HTTPStatus: http.StatusTooManyRequests,
+			ExitCode:   75,
+			Severity:   errgo.SeverityWarning,
+			Retryable:  &retryable,
+			DocURL:     "https://example.com/quota",
+		},
+	})
+
+	tests := []struct {
+		about string
+		err   error
+	}{{
+		about: "kind",
+		err:   errgo.WithKindf(nil, kindTaxonomyQuota, "too many requests"),
+	}, {
+		about: "code",
+		err:   errgo.Notef(errgo.WithCode(errNotFound, 4998), "too many requests"),
+	}}
+	for _, test := range tests {
+		if got := errgo.Classify(test.err); got != kindTaxonomyQuota {
+			t.Errorf("%s: unexpected kind %q", test.about, got)
+		}
+		if got := errgo.CodeOf(test.err); got != 4998 {
+			t.Errorf("%s: unexpected code %v", test.about, got)
+		}
+		if got := errgo.HTTPStatus(test.err); got != http.StatusTooManyRequests {
+			t.Errorf("%s: unexpected HTTP status %d", test.about, got)
+		}
+		if got := errgo.ExitCode(test.err); got != 75 {
+			t.Errorf("%s: unexpected exit code %d", test.about, got)
+		}
+		if got := errgo.SeverityOf(test.err); got != errgo.SeverityWarning {
+			t.Errorf("%s: unexpected severity %v", test.about, got)
+		}
+		if !errgo.IsRetryable(test.err) {
+			t.Errorf("%s: not retryable", test.about)
+		}
+		if got := errgo.DocURL(test.err); got != "https://example.com/quota" {
+			t.Errorf("%s: unexpected doc URL %q", test.about, got)
+		}
+	}
+
+	// Explicit properties take precedence.
+	err := errgo.WithKindf(nil, kindTaxonomyQuota, "too many requests")
+	err = errgo.WithHTTPStatus(errgo.WithRetryable(err, false), http.StatusServiceUnavailable)
+	if got := errgo.HTTPStatus(err); got != http.StatusServiceUnavailable {
+		t.Errorf("unexpected HTTP status %d", got)
+	}
+	if errgo.IsRetryable(err) {
+		t.Errorf("explicitly unretryable error is retryable")
+	}
+
+	// Other errors are unaffected.
+	if got := errgo.HTTPStatus(errNotFound); got != http.StatusInternalServerError {
+		t.Errorf("unexpected HTTP status %d", got)
+	}
+	if got := errgo.Classify(errgo.WithCode(errNotFound, 4997)); got != "" {
+		t.Errorf("unexpected kind %q", got)
+	}
+}
+
+func TestTaxonomyCheck(t *testing.T) {
+	tax := errgo.Taxonomy{
+		kindTaxonomyQuota:  {Code: 4998},
+		errgo.KindNotFound: {Code: 4998},
+	}
+	if err := tax.Check(); err == nil || err.Error() != "code 4998 for kinds not-found and taxonomy-quota" {
+		t.Fatalf("unexpected error %v", err)
+	}
+	delete(tax, errgo.KindNotFound)
+	if err := tax.Check(); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
+
+func TestSetTaxonomyDuplicateCode(t *testing.T) {
+	defer errgo.SetTaxonomy(nil)
+	defer func() {
+		want := "errgo: SetTaxonomy called with code 4998 for kinds not-found and taxonomy-quota"
+		if r := recover(); r != want {
+			t.Fatalf("got panic %v want %q", r, want)
+		}
+	}()
+	errgo.SetTaxonomy(errgo.Taxonomy{
+		kindTaxonomyQuota:  {Code: 4998},
+		errgo.KindNotFound: {Code: 4998},
+	})
+}
@@ -0,0 +1,61 @@
+// The templateerr package renders errgo errors with
+// templates from the text/template package.
+package templateerr
+
+import (
+	"bytes"
+	"text/template"
+
+	"github.com/juju/errgo"
+)
+
+// Entry holds the data passed to the template executed
+// by Format for each entry in an error chain.
+type Entry struct {
+	// Index holds the position of the entry in the chain,
+	// starting at zero for the outermost error.
+	Index int
+
+	// Location holds the source location of the entry,
+	// which may be unset.
+	Location errgo.Location
+
+	// Message holds the message of the entry, not including
+	// the messages of any underlying errors.
+	Message string
+
+	// Cause holds the cause recorded by the entry
+	// (see errgo.Causer), which may be nil.
+	Cause error
+}
+
+// Format renders the error chain of err by executing tmpl once
+// for each error in the chain, in the order they are visited by
+// errgo.Find, and concatenating the results. For example, to
+// produce one line per entry:
+//
+//	tmpl := template.Must(template.New("").Parse(
+//		"{{.Index}} {{if .Location.IsSet}}{{.Location}} {{end}}{{.Message}}\n",
+//	))
+//	s, err := templateerr.Format(err, tmpl)
+//
+// If err is nil, Format returns the empty string.
+func Format(err error, tmpl *template.Template) (string, error) {
+	var buf bytes.Buffer
+	for i, link := range errgo.Links(err) {
+		entry := Entry{
+			Index:   i,
+			Message: errgo.Message(link),
+		}
+		if link, ok := link.(errgo.Causer); ok {
+			entry.Cause = link.Cause()
+		}
+		if link, ok := link.(errgo.Locationer); ok {
+			entry.Location = link.Location()
+		}
+		if err := tmpl.Execute(&buf, entry); err != nil {
+			return "", errgo.Notef(err, "cannot execute template")
+		}
+	}
+	return buf.String(), nil
+}
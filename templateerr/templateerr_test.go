@@ -0,0 +1,50 @@
+package templateerr_test
+
+import (
+	"errors"
+	"testing"
+	"text/template"
+
+	"github.com/juju/errgo"
+	"github.com/juju/errgo/templateerr"
+)
+
+var someErr = errors.New("some error")
+
+var entryTemplate = template.Must(template.New("").Parse(
+	"{{.Index}}|{{if .Location.IsSet}}{{.Location}}{{end}}|{{.Message}}|{{if .Cause}}{{.Cause}}{{end}}\n",
+))
+
+func TestFormat(t *testing.T) {
+	err0 := &errgo.Err{
+		Message_:  "foo",
+		Cause_:    someErr,
+		Location_: errgo.Location{File: "a.go", Line: 10},
+	}
+	err1 := &errgo.Err{
+		Message_:    "bar",
+		Underlying_: err0,
+		Location_:   errgo.Location{File: "b.go", Line: 20},
+	}
+
+	s, err := templateerr.Format(err1, entryTemplate)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "0|b.go:20|bar|\n1|a.go:10|foo|some error\n"; s != want {
+		t.Fatalf("unexpected result; want %q got %q", want, s)
+	}
+
+	s, err = templateerr.Format(nil, entryTemplate)
+	if err != nil || s != "" {
+		t.Fatalf("unexpected result for nil error: %q, %v", s, err)
+	}
+}
+
+func TestFormatError(t *testing.T) {
+	tmpl := template.Must(template.New("").Parse("{{.Missing}}"))
+	_, err := templateerr.Format(someErr, tmpl)
+	if err == nil {
+		t.Fatalf("expected error")
+	}
+}
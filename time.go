@@ -0,0 +1,78 @@
+package errgo
+
+import (
+	"time"
+)
+
+// RecordTimes controls whether errors record the time at which
+// they were created. The times can be shown by FormatDetails
+// (see DetailsOptions.Elapsed) to reveal how long an error took
+// to pass through each layer of a program, for example
+// when it was held in a retry loop.
+//
+// It should be set before any errors are created, usually
+// during program initialization.
+var RecordTimes = false
+
+// now is changed when testing.
+var now = time.Now
+
+// Timestamper can be implemented by any error type that
+// records the time at which it was created.
+type Timestamper interface {
+	// Time returns the time the error was created, or
+	// the zero time if it was not recorded.
+	Time() time.Time
+}
+
+// errorTime returns the creation time recorded by err,
+// or the zero time if there is none.
+func errorTime(err error) time.Time {
+	if err, ok := err.(Timestamper); ok {
+		return err.Time()
+	}
+	return time.Time{}
+}
+
+// elapsed returns the time between the creation of err and
+// the creation of the nearest error in its chain (see Wrapper)
+// that recorded a time, and whether there was such an error.
+func elapsed(err error) (time.Duration, bool) {
+	t := errorTime(err)
+	if t.IsZero() {
+		return 0, false
+	}
+	for e := underlying(err); e != nil; e = underlying(e) {
+		if t1 := errorTime(e); !t1.IsZero() {
+			return t.Sub(t1), true
+		}
+	}
+	return 0, false
+}
+
+// CreatedAt returns the creation time recorded by the innermost
+// error in the chain of err (see Depth) that recorded one, which
+// is usually when the problem first occurred, and whether there
+// was such an error. See RecordTimes.
+func CreatedAt(err error) (time.Time, bool) {
+	var t time.Time
+	for ; err != nil; err = next(err) {
+		if et := errorTime(err); !et.IsZero() {
+			t = et
+		}
+	}
+	return t, !t.IsZero()
+}
+
+// Age returns the time elapsed since the creation of err as
+// returned by CreatedAt, for example so that a consumer of a
+// queue can decide whether an error captured long ago is still
+// worth reporting. It returns zero if no creation
+// time was recorded.
+func Age(err error) time.Duration {
+	t, ok := CreatedAt(err)
+	if !ok {
+		return 0
+	}
+	return now().Sub(t)
+}
@@ -0,0 +1,83 @@
+package errgo_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/juju/errgo"
+	"github.com/juju/errgo/errgotest"
+)
+
+func TestRecordTimes(t *testing.T) {
+	skipIfMinimal(t)
+	t0 := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	clock := t0
+	defer errgo.PatchNow(func() time.Time {
+		return clock
+	})()
+
+	err0 := errgo.New("foo")
+	if tm := err0.(errgo.Timestamper).Time(); !tm.IsZero() {
+		t.Fatalf("unexpected time %v", tm)
+	}
+
+	errgo.RecordTimes = true
+	defer func() {
+		errgo.RecordTimes = false
+	}()
+	err1 := errgo.Notef(err0, "bar")
+	clock = clock.Add(30 * time.Second)
+	err2 := errgo.Mask(err1)
+	clock = clock.Add(1500 * time.Millisecond)
+	err3 := errgo.Notef(err2, "baz")
+
+	if tm := err1.(errgo.Timestamper).Time(); !tm.Equal(t0) {
+		t.Fatalf("unexpected time %v", tm)
+	}
+	got := errgo.FormatDetails(err3, errgo.DetailsOptions{
+		Elapsed: true,
+	})
+	want := "[{+1.5s time_test.go: baz} {+30s time_test.go: } {time_test.go: bar} {time_test.go: foo}]"
+	if got := errgotest.NormalizeStack(got, nil); got != want && sourceLocations {
+		t.Fatalf("unexpected details; got %q want %q", got, want)
+	}
+	if got, want := errgotest.NormalizeStack(errgo.Details(err3), nil), "[{time_test.go: baz} {time_test.go: } {time_test.go: bar} {time_test.go: foo}]"; got != want && sourceLocations {
+		t.Fatalf("unexpected details; got %q want %q", got, want)
+	}
+}
+
+func TestAge(t *testing.T) {
+	skipIfMinimal(t)
+	t0 := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	clock := t0
+	defer errgo.PatchNow(func() time.Time {
+		return clock
+	})()
+
+	err0 := errgo.New("foo")
+	if at, ok := errgo.CreatedAt(err0); ok || !at.IsZero() {
+		t.Fatalf("unexpected creation time %v", at)
+	}
+	if age := errgo.Age(err0); age != 0 {
+		t.Fatalf("unexpected age %v", age)
+	}
+
+	errgo.RecordTimes = true
+	defer func() {
+		errgo.RecordTimes = false
+	}()
+	err1 := errgo.Notef(err0, "bar")
+	clock = clock.Add(time.Minute)
+	err2 := errgo.Mask(err1)
+	clock = clock.Add(time.Hour)
+
+	if at, ok := errgo.CreatedAt(err2); !ok || !at.Equal(t0) {
+		t.Fatalf("unexpected creation time %v, %v", at, ok)
+	}
+	if age := errgo.Age(err2); age != time.Hour+time.Minute {
+		t.Fatalf("unexpected age %v", age)
+	}
+	if age := errgo.Age(nil); age != 0 {
+		t.Fatalf("unexpected age of nil error %v", age)
+	}
+}
@@ -0,0 +1,121 @@
+package errgo
+
+import (
+	"context"
+	"strings"
+)
+
+// TraceContext holds the identifiers of a distributed trace
+// and of the span within it.
+type TraceContext struct {
+	// TraceID holds the trace identifier as 32 hex digits.
+	TraceID string
+
+	// SpanID holds the span identifier as 16 hex digits.
+	SpanID string
+}
+
+// TraceContextFromContext is used by TraceCtx to find the trace
+// context of a context.Context. By default it returns the trace
+// context stored by ContextWithTraceparent. Programs using a
+// tracing library such as OpenTelemetry may set it to a function
+// that returns that library's active span context instead.
+//
+// It should be set before any errors are created, usually
+// during program initialization.
+var TraceContextFromContext = traceparentFromContext
+
+type traceparentKey struct{}
+
+// ContextWithTraceparent returns a copy of ctx holding the trace
+// context from the given W3C traceparent header value, for example
+// "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01".
+// If the header cannot be parsed, ctx is returned unchanged.
+func ContextWithTraceparent(ctx context.Context, traceparent string) context.Context {
+	parts := strings.Split(traceparent, "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || !isHex(parts[1], 32) || !isHex(parts[2], 16) {
+		return ctx
+	}
+	return context.WithValue(ctx, traceparentKey{}, TraceContext{
+		TraceID: parts[1],
+		SpanID:  parts[2],
+	})
+}
+
+func traceparentFromContext(ctx context.Context) (TraceContext, bool) {
+	tc, ok := ctx.Value(traceparentKey{}).(TraceContext)
+	return tc, ok
+}
+
+// isHex reports whether s is made of n lower case hex digits,
+// not all zero.
+func isHex(s string, n int) bool {
+	if len(s) != n || strings.Trim(s, "0") == "" {
+		return false
+	}
+	for _, c := range s {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// traceErr holds an error along with the trace context
+// that was active when it was recorded.
+type traceErr struct {
+	Err
+	traceContext TraceContext
+}
+
+// TraceContext returns the recorded trace context.
+func (e *traceErr) TraceContext() TraceContext {
+	return e.traceContext
+}
+
+// TraceCtx returns an error that wraps err and records the trace
+// context of ctx (see TraceContextFromContext), so that it can be
+// retrieved with TraceContextOf after the context has gone.
+// The returned error has the same message and cause as err.
+//
+// If err is nil or ctx holds no trace context, TraceCtx returns err
+// unchanged.
+func TraceCtx(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+	tc, ok := TraceContextFromContext(ctx)
+	if !ok {
+		return err
+	}
+	newErr := &traceErr{
+		Err: Err{
+			Underlying_: err,
+			Cause_:      Cause(err),
+		},
+		traceContext: tc,
+	}
+	newErr.SetLocation(1)
+	return Created(newErr)
+}
+
+// TraceContexter can be implemented by any error type
+// that records a trace context.
+type TraceContexter interface {
+	TraceContext() TraceContext
+}
+
+// TraceContextOf returns the trace context recorded by the
+// outermost call to TraceCtx, or other error implementing
+// TraceContexter, in the chain of err.
+// It returns false if there is none.
+func TraceContextOf(err error) (TraceContext, bool) {
+	found := Find(err, func(err error) bool {
+		err1, ok := err.(TraceContexter)
+		return ok && err1.TraceContext() != TraceContext{}
+	})
+	if found == nil {
+		return TraceContext{}, false
+	}
+	return found.(TraceContexter).TraceContext(), true
+}
@@ -0,0 +1,64 @@
+package errgo_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/juju/errgo"
+)
+
+var traceparentTests = []struct {
+	traceparent string
+	expect      errgo.TraceContext
+	ok          bool
+}{{
+	traceparent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+	expect: errgo.TraceContext{
+		TraceID: "4bf92f3577b34da6a3ce929d0e0e4736",
+		SpanID:  "00f067aa0ba902b7",
+	},
+	ok: true,
+}, {
+	traceparent: "00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+}, {
+	traceparent: "00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+}, {
+	traceparent: "00-4bf92f3577b34da6a3ce929d0e0e4736-01",
+}, {
+	traceparent: "",
+}}
+
+func TestTraceCtx(t *testing.T) {
+	for i, test := range traceparentTests {
+		ctx := errgo.ContextWithTraceparent(context.Background(), test.traceparent)
+		err0 := errgo.WithCausef(nil, errNotFound, "foo")
+		err := errgo.TraceCtx(ctx, err0)
+		tc, ok := errgo.TraceContextOf(errgo.Notef(err, "bar"))
+		if tc != test.expect || ok != test.ok {
+			t.Errorf("test %d: got (%#v, %v) want (%#v, %v)", i, tc, ok, test.expect, test.ok)
+		}
+		if !test.ok && err != err0 {
+			t.Errorf("test %d: error was wrapped with no trace context", i)
+		}
+		if err.Error() != "foo" || errgo.Cause(err) != errNotFound {
+			t.Errorf("test %d: unexpected error %#v", i, err)
+		}
+	}
+	if err := errgo.TraceCtx(context.Background(), nil); err != nil {
+		t.Errorf("unexpected error %#v", err)
+	}
+}
+
+func TestTraceContextFromContext(t *testing.T) {
+	defer func(f func(context.Context) (errgo.TraceContext, bool)) {
+		errgo.TraceContextFromContext = f
+	}(errgo.TraceContextFromContext)
+	want := errgo.TraceContext{TraceID: "trace", SpanID: "span"}
+	errgo.TraceContextFromContext = func(context.Context) (errgo.TraceContext, bool) {
+		return want, true
+	}
+	tc, ok := errgo.TraceContextOf(errgo.TraceCtx(context.Background(), someErr))
+	if tc != want || !ok {
+		t.Fatalf("got (%#v, %v) want %#v", tc, ok, want)
+	}
+}
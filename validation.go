@@ -0,0 +1,184 @@
+package errgo
+
+import (
+	"sort"
+	"unicode/utf8"
+)
+
+// FieldError holds a description of a problem with a single
+// field of some value, such as a request parameter that
+// failed validation.
+type FieldError struct {
+	Err
+
+	// Field_ holds the path of the field, for example
+	// "spec.ports[2].name".
+	Field_ string
+}
+
+// Field returns the path of the field.
+func (e *FieldError) Field() string {
+	return e.Field_
+}
+
+// Message implements Wrapper. The message is
+// prefixed with the field path.
+func (e *FieldError) Message() string {
+	return e.Field_ + ": " + e.Message_
+}
+
+// Error implements error.Error. The message is
+// prefixed with the field path.
+func (e *FieldError) Error() string {
+	return e.Field_ + ": " + e.Err.Error()
+}
+
+// GoString returns the details of the receiving error
+// message, so that printing an error with %#v will
+// produce useful information.
+func (e *FieldError) GoString() string {
+	return Details(e)
+}
+
+// Validation collects the problems found when validating a value.
+//
+// The zero value is ready to use. For example:
+//
+//	var v errgo.Validation
+//	if p.Name == "" {
+//		v.Addf("name", "is required")
+//	}
+//	if p.Age < 0 {
+//		v.Addf("age", "must not be negative, got %d", p.Age)
+//	}
+//	return v.Err()
+//
+// A Validation implements MultiWrapper, with each of
+// its underlying errors being a *FieldError.
+type Validation struct {
+	MultiErr
+}
+
+// Addf records a problem with the given field, described by
+// the given formatted message. The location of the problem is
+// the caller of Addf.
+func (v *Validation) Addf(field string, f string, a ...interface{}) {
+	v.add(field, nil, f, a)
+}
+
+// AddCausef is like Addf, but also records the given error as
+// the cause of the problem.
+func (v *Validation) AddCausef(field string, cause error, f string, a ...interface{}) {
+	v.add(field, cause, f, a)
+}
+
+func (v *Validation) add(field string, cause error, f string, a []interface{}) {
+	err := &FieldError{
+		Err: Err{
+			Cause_: cause,
+		},
+		Field_: field,
+	}
+	err.setMessagef(f, a)
+	err.SetLocation(2)
+	v.UnderlyingErrors_ = append(v.UnderlyingErrors_, err)
+}
+
+// Fields returns all the field errors recorded so far.
+func (v *Validation) Fields() []*FieldError {
+	fields := make([]*FieldError, 0, len(v.UnderlyingErrors_))
+	for _, err := range v.UnderlyingErrors_ {
+		if err, ok := err.(*FieldError); ok {
+			fields = append(fields, err)
+		}
+	}
+	return fields
+}
+
+// Err returns nil if no problems have been recorded. Otherwise
+// it returns a copy of v holding the problems recorded so far,
+// with its location set to the caller of Err and its message
+// set to "validation failed" if it has none. Problems recorded
+// after Err returns do not change the returned error.
+func (v *Validation) Err() error {
+	n := len(v.UnderlyingErrors_)
+	if n == 0 {
+		return nil
+	}
+	err := &Validation{
+		MultiErr: MultiErr{
+			Err: Err{
+				Message_:    v.Message_,
+				Cause_:      v.Cause_,
+				Underlying_: v.Underlying_,
+			},
+			UnderlyingErrors_: v.UnderlyingErrors_[:n:n],
+		},
+	}
+	if err.Message_ == "" {
+		err.Message_ = "validation failed"
+	}
+	err.SetLocation(1)
+	return Created(err)
+}
+
+// MarshalJSON implements json.Marshaler by encoding the
+// validation problems as an object mapping each field
+// path to a list of messages, for example:
+//
+//	{"age": ["must not be negative, got -1"], "name": ["is required"]}
+func (v *Validation) MarshalJSON() ([]byte, error) {
+	fields := make(map[string][]string)
+	for _, err := range v.Fields() {
+		fields[err.Field_] = append(fields[err.Field_], err.Err.Error())
+	}
+	paths := make([]string, 0, len(fields))
+	for path := range fields {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	data := []byte{'{'}
+	for i, path := range paths {
+		if i > 0 {
+			data = append(data, ',')
+		}
+		data = appendJSONString(data, path)
+		data = append(data, ':', '[')
+		for j, msg := range fields[path] {
+			if j > 0 {
+				data = append(data, ',')
+			}
+			data = appendJSONString(data, msg)
+		}
+		data = append(data, ']')
+	}
+	return append(data, '}'), nil
+}
+
+// appendJSONString appends s to data encoded as a JSON string,
+// with invalid UTF-8 replaced by U+FFFD as by encoding/json.
+func appendJSONString(data []byte, s string) []byte {
+	const hex = "0123456789abcdef"
+	data = append(data, '"')
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		i += size
+		switch {
+		case r == '"' || r == '\\':
+			data = append(data, '\\', byte(r))
+		case r == '\n':
+			data = append(data, '\\', 'n')
+		case r == '\r':
+			data = append(data, '\\', 'r')
+		case r == '\t':
+			data = append(data, '\\', 't')
+		case r < ' ' || r == '<' || r == '>' || r == '&':
+			data = append(data, '\\', 'u', '0', '0', hex[r>>4], hex[r&0xf])
+		case r == '\u2028' || r == '\u2029':
+			data = append(data, '\\', 'u', '2', '0', '2', hex[r&0xf])
+		default:
+			data = utf8.AppendRune(data, r)
+		}
+	}
+	return append(data, '"')
+}
@@ -0,0 +1,64 @@
+package errgo_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/juju/errgo"
+)
+
+func TestValidation(t *testing.T) {
+	var v errgo.Validation
+	if err := v.Err(); err != nil {
+		t.Fatalf("unexpected error %#v", err)
+	}
+	v.Addf("name", "is required")
+	v.AddCausef("age", errNotFound, "must be %d", 42)
+	v.Addf("name", "must not contain %q", "/")
+	err := v.Err()
+	checkErr(t, err, nil, `validation failed: name: is required; age: must be 42; name: must not contain "/"`,
+		`[{validation_test.go: validation failed [{validation_test.go: name: is required}] [{validation_test.go: age: must be 42}] [{validation_test.go: name: must not contain "/"}]}]`, err)
+
+	fields := v.Fields()
+	if len(fields) != 3 || fields[1].Field() != "age" || errgo.Cause(fields[1]) != errNotFound {
+		t.Fatalf("unexpected fields %#v", fields)
+	}
+	if !errgo.Matches(err, "test-any") {
+		t.Fatalf("validation error does not match registered checker")
+	}
+
+	data, jerr := json.Marshal(err)
+	if jerr != nil {
+		t.Fatal(jerr)
+	}
+	want := `{"age":["must be 42"],"name":["is required","must not contain \"/\""]}`
+	if string(data) != want {
+		t.Fatalf("unexpected JSON: got %s want %s", data, want)
+	}
+}
+
+func TestValidationMarshalJSONEscaping(t *testing.T) {
+	var v errgo.Validation
+	msg := "a<b> & \"c\"\\\n\t\x01\xff é"
+	v.Addf("spec.\"name\"", "%s", msg)
+	data, jerr := v.MarshalJSON()
+	if jerr != nil {
+		t.Fatal(jerr)
+	}
+	var got map[string][]string
+	if jerr := json.Unmarshal(data, &got); jerr != nil {
+		t.Fatalf("invalid JSON %s: %v", data, jerr)
+	}
+	want := map[string][]string{
+		`spec."name"`: {strings.ToValidUTF8(msg, "�")},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected decoded JSON %q", got)
+	}
+	if std, _ := json.Marshal(got); !bytes.Equal(data, std) {
+		t.Fatalf("JSON differs from encoding/json; got %s want %s", data, std)
+	}
+}
@@ -0,0 +1,57 @@
+package errgo
+
+import "reflect"
+
+// valueErr holds an error along with a value associated with a key.
+type valueErr struct {
+	Err
+	key, val interface{}
+}
+
+// WithValue returns an error that wraps err and associates val with
+// key, so that it can be retrieved with Value. As with
+// context.WithValue, the key must be comparable and should be of
+// an unexported type defined by the package using it, to avoid
+// collisions with keys defined by other packages. For example:
+//
+//	type orderKey struct{}
+//
+//	return errgo.WithValue(err, orderKey{}, order)
+//
+// The returned error has the same message and cause as err.
+// If err is nil, WithValue returns nil. WithValue panics
+// if key is nil or not comparable.
+func WithValue(err error, key, val interface{}) error {
+	if key == nil {
+		panic("errgo: WithValue called with nil key")
+	}
+	if !reflect.TypeOf(key).Comparable() {
+		panic("errgo: WithValue called with incomparable key")
+	}
+	if err == nil {
+		return nil
+	}
+	newErr := &valueErr{
+		Err: Err{
+			Underlying_: err,
+			Cause_:      Cause(err),
+		},
+		key: key,
+		val: val,
+	}
+	newErr.SetLocation(1)
+	return Created(newErr)
+}
+
+// Value returns the value associated with key by the outermost
+// call to WithValue in the chain of err, or nil if there is none.
+func Value(err error, key interface{}) interface{} {
+	found := Find(err, func(err error) bool {
+		verr, ok := err.(*valueErr)
+		return ok && verr.key == key
+	})
+	if found == nil {
+		return nil
+	}
+	return found.(*valueErr).val
+}
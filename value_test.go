@@ -0,0 +1,69 @@
+package errgo_test
+
+import (
+	"testing"
+
+	"github.com/juju/errgo"
+)
+
+type keyA struct{}
+
+type keyB struct{}
+
+func TestWithValue(t *testing.T) {
+	err0 := errgo.WithCausef(nil, errNotFound, "foo")
+	err := errgo.WithValue(err0, keyA{}, "a")
+	checkErr(t, err, err0, "foo", "[{value_test.go: } {value_test.go: foo}]", errNotFound)
+
+	err = errgo.Notef(err, "bar")
+	err = errgo.WithValue(err, keyB{}, 2)
+	err = errgo.WithValue(err, keyA{}, "outer")
+	tests := []struct {
+		about  string
+		err    error
+		key    interface{}
+		expect interface{}
+	}{{
+		about: "nil error",
+		key:   keyA{},
+	}, {
+		about: "no value",
+		err:   err0,
+		key:   keyA{},
+	}, {
+		about:  "outermost value",
+		err:    err,
+		key:    keyA{},
+		expect: "outer",
+	}, {
+		about:  "other key",
+		err:    err,
+		key:    keyB{},
+		expect: 2,
+	}, {
+		about: "unknown key",
+		err:   err,
+		key:   "keyA",
+	}}
+	for _, test := range tests {
+		if got := errgo.Value(test.err, test.key); got != test.expect {
+			t.Errorf("%s: got %#v want %#v", test.about, got, test.expect)
+		}
+	}
+	if errgo.WithValue(nil, keyA{}, "a") != nil {
+		t.Errorf("expected nil error")
+	}
+}
+
+func TestWithValuePanics(t *testing.T) {
+	for _, key := range []interface{}{nil, []int{1}} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("no panic for key %#v", key)
+				}
+			}()
+			errgo.WithValue(someErr, key, 1)
+		}()
+	}
+}
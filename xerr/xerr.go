@@ -0,0 +1,43 @@
+// The xerr package arranges for chains of errors made by the
+// golang.org/x/xerrors package, or by other packages whose errors
+// implement xerrors.Formatter, to be shown link by link by
+// errgo.Details, so that the locations of errgo errors wrapped
+// by them are not lost. It is used for its side effects:
+//
+//	import _ "github.com/juju/errgo/xerr"
+package xerr
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/xerrors"
+
+	"github.com/juju/errgo"
+)
+
+func init() {
+	errgo.RegisterWrapper(func(err xerrors.Formatter) (string, error) {
+		var p printer
+		next := err.FormatError(&p)
+		return p.String(), next
+	})
+}
+
+// printer implements xerrors.Printer by
+// recording the message printed by an error.
+type printer struct {
+	strings.Builder
+}
+
+func (p *printer) Print(args ...interface{}) {
+	fmt.Fprint(&p.Builder, args...)
+}
+
+func (p *printer) Printf(format string, args ...interface{}) {
+	fmt.Fprintf(&p.Builder, format, args...)
+}
+
+func (p *printer) Detail() bool {
+	return false
+}
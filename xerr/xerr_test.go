@@ -0,0 +1,32 @@
+package xerr_test
+
+import (
+	"testing"
+
+	"golang.org/x/xerrors"
+
+	"github.com/juju/errgo"
+	_ "github.com/juju/errgo/xerr"
+)
+
+func TestDetails(t *testing.T) {
+	err0 := &errgo.Err{
+		Message_:  "foo",
+		Location_: errgo.Location{File: "a.go", Line: 10},
+	}
+	err1 := xerrors.Errorf("ctx: %w", err0)
+	err2 := &errgo.Err{
+		Message_:    "bar",
+		Underlying_: err1,
+		Location_:   errgo.Location{File: "b.go", Line: 20},
+	}
+	if got, want := err2.Error(), "bar: ctx: foo"; got != want {
+		t.Fatalf("unexpected message %q; want %q", got, want)
+	}
+	if got, want := errgo.Details(err2), "[{b.go:20: bar} {ctx} {a.go:10: foo}]"; got != want {
+		t.Fatalf("unexpected details %q; want %q", got, want)
+	}
+	if err := errgo.Find(err2, errgo.Is(err0)); err != err0 {
+		t.Fatalf("Find returned %#v", err)
+	}
+}
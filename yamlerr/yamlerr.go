@@ -0,0 +1,129 @@
+// The yamlerr package renders errgo errors as YAML documents,
+// suitable for embedding in reports read by people.
+package yamlerr
+
+import (
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/juju/errgo"
+)
+
+// ToYAML returns a YAML document describing the error chain of
+// err. The document is a sequence with an entry for each error in
+// the chain (see errgo.Details), outermost first, holding its
+// message, location, cause and any additional fields, for example:
+//
+//	# The chain of an error returned when a user is not found.
+//	- message: "cannot get user"
+//	  location: "user.go:20"
+//	- message: "not found"
+//	  location: "db.go:99"
+//	  cause: "no rows in result set"
+//	  fields:
+//	    id: "Q4ZKX7RM"
+//
+// The recorded fields are the identifier (see errgo.AssignIDs), the
+// creation time (see errgo.RecordTimes) and the field of a
+// *errgo.FieldError. Errors wrapped by an error (see
+// errgo.MultiWrapper) are described under its "errors" key,
+// each as a nested sequence.
+//
+// Strings are written as double-quoted scalars, with YAML escape
+// sequences for special and non-printable characters. Any invalid
+// UTF-8 in them is replaced by U+FFFD, as YAML cannot represent it.
+//
+// If err is nil, ToYAML returns "[]\n".
+func ToYAML(err error) string {
+	if err == nil {
+		return "[]\n"
+	}
+	var b strings.Builder
+	writeChain(&b, err, "")
+	return b.String()
+}
+
+// writeChain writes the YAML sequence describing the
+// chain of err to b, with each line prefixed by indent.
+func writeChain(b *strings.Builder, err error, indent string) {
+	for ; err != nil; err = errgo.Underlying(err) {
+		b.WriteString(indent + "- message: " + quote(errgo.Message(err)) + "\n")
+		inner := indent + "  "
+		if err, ok := err.(errgo.Locationer); ok && err.Location().IsSet() {
+			b.WriteString(inner + "location: " + quote(err.Location().String()) + "\n")
+		}
+		if err, ok := err.(errgo.Causer); ok && err.Cause() != nil {
+			b.WriteString(inner + "cause: " + quote(err.Cause().Error()) + "\n")
+		}
+		if fields := fields(err); len(fields) > 0 {
+			b.WriteString(inner + "fields:\n")
+			for _, f := range fields {
+				b.WriteString(inner + "  " + f[0] + ": " + quote(f[1]) + "\n")
+			}
+		}
+		if errs := errgo.Branches(err); len(errs) > 0 {
+			b.WriteString(inner + "errors:\n")
+			for _, branch := range errs {
+				b.WriteString(inner + "  -\n")
+				writeChain(b, branch, inner+"    ")
+			}
+		}
+	}
+}
+
+// fields returns the names and values of the additional
+// fields of err included by ToYAML.
+func fields(err error) [][2]string {
+	var fields [][2]string
+	if err, ok := err.(errgo.Identifier); ok && err.ID() != "" {
+		fields = append(fields, [2]string{"id", err.ID()})
+	}
+	if err, ok := err.(errgo.Timestamper); ok && !err.Time().IsZero() {
+		fields = append(fields, [2]string{"time", err.Time().Format(time.RFC3339Nano)})
+	}
+	if err, ok := err.(*errgo.FieldError); ok {
+		fields = append(fields, [2]string{"field", err.Field()})
+	}
+	return fields
+}
+
+// quote returns s as a YAML double-quoted scalar.
+func quote(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	// Ranging over s replaces invalid UTF-8 with U+FFFD.
+	for _, r := range s {
+		switch {
+		case r == '"' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\t':
+			b.WriteString(`\t`)
+		case r == '\r':
+			b.WriteString(`\r`)
+		case r < 0x20 || r == 0x7f:
+			writeEscape(&b, `\x`, r, 2)
+		case !unicode.IsPrint(r) && r <= 0xffff:
+			writeEscape(&b, `\u`, r, 4)
+		case !unicode.IsPrint(r):
+			writeEscape(&b, `\U`, r, 8)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// writeEscape writes the escape sequence made of prefix
+// followed by n hexadecimal digits of r to b.
+func writeEscape(b *strings.Builder, prefix string, r rune, n int) {
+	const hexDigits = "0123456789abcdef"
+	b.WriteString(prefix)
+	for i := n - 1; i >= 0; i-- {
+		b.WriteByte(hexDigits[r>>(4*i)&0xf])
+	}
+}
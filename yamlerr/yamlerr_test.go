@@ -0,0 +1,86 @@
+package yamlerr_test
+
+import (
+	"errors"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/juju/errgo"
+	"github.com/juju/errgo/yamlerr"
+)
+
+func TestToYAML(t *testing.T) {
+	if got := yamlerr.ToYAML(nil); got != "[]\n" {
+		t.Fatalf("unexpected YAML for nil error %q", got)
+	}
+	var v errgo.Validation
+	v.Addf("name", "is \"required\"")
+	err0 := v.Err()
+	err1 := &errgo.Err{
+		Message_:    "bad\nrequest",
+		Underlying_: err0,
+		Cause_:      errors.New("not found"),
+		Location_:   errgo.Location{File: "a.go", Line: 10},
+	}
+
+	got := yamlerr.ToYAML(err1)
+	want := `- message: "bad\nrequest"
+  location: "a.go:10"
+  cause: "not found"
+- message: "validation failed"
+` + location(err0, "  ") + `  errors:
+    -
+      - message: "name: is \"required\""
+` + location(errgo.Branches(err0)[0], "        ") + `        fields:
+          field: "name"
+`
+	if got != want {
+		t.Fatalf("unexpected YAML; got\n%s\nwant\n%s", got, want)
+	}
+
+	var doc []struct {
+		Message  string
+		Location string
+		Cause    string
+		Errors   [][]struct {
+			Message string
+			Fields  map[string]string
+		}
+	}
+	if err := yaml.Unmarshal([]byte(got), &doc); err != nil {
+		t.Fatalf("cannot parse YAML: %v", err)
+	}
+	if len(doc) != 2 || doc[0].Message != "bad\nrequest" || doc[1].Errors[0][0].Fields["field"] != "name" {
+		t.Fatalf("unexpected parsed YAML %#v", doc)
+	}
+}
+
+func TestToYAMLEscaping(t *testing.T) {
+	err := &errgo.Err{
+		Message_: "nul\x00 esc\x1b del\x7f nel\u0085 bad\xff ok\u00e9 \\ \"",
+	}
+	got := yamlerr.ToYAML(err)
+	want := "- message: \"nul\\x00 esc\\x1b del\\x7f nel\\u0085 bad\ufffd ok\u00e9 \\\\ \\\"\"\n"
+	if got != want {
+		t.Fatalf("unexpected YAML; got\n%s\nwant\n%s", got, want)
+	}
+	var doc []struct {
+		Message string
+	}
+	if err := yaml.Unmarshal([]byte(got), &doc); err != nil {
+		t.Fatalf("cannot parse YAML: %v", err)
+	}
+	if want := "nul\x00 esc\x1b del\x7f nel\u0085 bad\ufffd ok\u00e9 \\ \""; len(doc) != 1 || doc[0].Message != want {
+		t.Fatalf("unexpected parsed YAML %#v", doc)
+	}
+}
+
+// location returns the line describing the location
+// recorded by err, if any, prefixed by indent.
+func location(err error, indent string) string {
+	if err, ok := err.(errgo.Locationer); ok && err.Location().IsSet() {
+		return indent + `location: "` + err.Location().String() + "\"\n"
+	}
+	return ""
+}